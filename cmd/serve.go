@@ -7,14 +7,20 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/giantswarm/mcp-teleport/internal/policy"
 	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport"
+	"github.com/giantswarm/mcp-teleport/internal/tools/access"
 	"github.com/giantswarm/mcp-teleport/internal/tools/apps"
 	"github.com/giantswarm/mcp-teleport/internal/tools/auth"
 	"github.com/giantswarm/mcp-teleport/internal/tools/database"
+	"github.com/giantswarm/mcp-teleport/internal/tools/jobs"
 	"github.com/giantswarm/mcp-teleport/internal/tools/kube"
+	"github.com/giantswarm/mcp-teleport/internal/tools/output"
 	"github.com/giantswarm/mcp-teleport/internal/tools/ssh"
 	mcpserver "github.com/mark3labs/mcp-go/server"
 )
@@ -45,6 +51,34 @@ func newServeCmd() *cobra.Command {
 		dryRun             bool
 		debugMode          bool
 
+		// Timeout options
+		defaultTimeout time.Duration
+		toolTimeouts   map[string]string
+		maxTimeout     time.Duration
+
+		// Expiry warning for teleport_status
+		expiryWarning time.Duration
+
+		// Concurrency limit for label-selector SSH commands
+		sshConcurrency int
+
+		// Timeout for background jobs
+		jobTimeout time.Duration
+
+		// Output size limits
+		outputLimit  int
+		captureLimit int
+
+		// Paths teleport_scp may transfer from or to
+		scpPolicy policy.SCPPolicy
+
+		// Rules for teleport_ssh commands
+		sshPolicyFile string
+
+		// Private kubeconfigs for tsh
+		kubeconfigIsolation bool
+		kubeconfigDir       string
+
 		// Transport options
 		transport       string
 		httpAddr        string
@@ -64,7 +98,19 @@ Supports multiple transport types:
   - sse: Server-Sent Events over HTTP
   - streamable-http: Streamable HTTP transport`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			timeouts, err := parseToolTimeouts(toolTimeouts)
+			if err != nil {
+				return err
+			}
+			var sshPolicy *policy.SSHPolicy
+			if sshPolicyFile != "" {
+				if sshPolicy, err = policy.LoadSSHPolicy(sshPolicyFile); err != nil {
+					return err
+				}
+			}
 			return runServe(transport, nonDestructiveMode, dryRun, debugMode,
+				defaultTimeout, timeouts, maxTimeout, expiryWarning, sshConcurrency, jobTimeout,
+				outputLimit, captureLimit, scpPolicy, sshPolicy, kubeconfigIsolation, kubeconfigDir,
 				httpAddr, sseEndpoint, messageEndpoint, httpEndpoint)
 		},
 	}
@@ -74,6 +120,33 @@ Supports multiple transport types:
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Enable dry run mode (default: false)")
 	cmd.Flags().BoolVar(&debugMode, "debug", false, "Enable debug logging (default: false)")
 
+	// Timeout flags
+	cmd.Flags().DurationVar(&defaultTimeout, "timeout", teleport.DefaultTimeout, "Default timeout for tsh commands")
+	cmd.Flags().StringToStringVar(&toolTimeouts, "tool-timeout", nil, "Per-tool timeouts overriding --timeout, e.g. teleport_scp=10m,teleport_list_ssh_nodes=10s")
+	cmd.Flags().DurationVar(&maxTimeout, "max-timeout", 30*time.Minute, "Maximum timeout a client may request with the timeoutSeconds tool argument")
+
+	cmd.Flags().DurationVar(&expiryWarning, "expiry-warning", server.DefaultExpiryWarning, "Flag certificates in teleport_status that expire within this window (0 disables)")
+
+	cmd.Flags().IntVar(&sshConcurrency, "ssh-concurrency", server.DefaultSSHConcurrency, "Maximum number of nodes a label-selector teleport_ssh command runs on at once")
+
+	cmd.Flags().DurationVar(&jobTimeout, "job-timeout", server.DefaultJobTimeout, "Default timeout for background jobs started with background=true")
+
+	// Output limit flags
+	cmd.Flags().IntVar(&outputLimit, "output-limit", server.DefaultOutputLimit, "Maximum bytes of command output returned in a tool result; the rest can be read with teleport_output_read")
+	cmd.Flags().IntVar(&captureLimit, "capture-limit", server.DefaultCaptureLimit, "Maximum bytes of output captured per tsh command; the middle of larger output is dropped")
+
+	cmd.Flags().StringVar(&sshPolicyFile, "ssh-policy", "", "JSON file with allow and deny rules commands run over SSH must pass (default: no policy)")
+
+	// SCP path policy flags
+	cmd.Flags().StringSliceVar(&scpPolicy.LocalAllow, "scp-local-allow", nil, "Local paths teleport_scp may read or write, e.g. /tmp/mcp,~/transfers (default: all paths not denied)")
+	cmd.Flags().StringSliceVar(&scpPolicy.LocalDeny, "scp-local-deny", policy.DefaultLocalDeny, "Local paths teleport_scp never reads or writes; denial takes precedence over --scp-local-allow")
+	cmd.Flags().StringSliceVar(&scpPolicy.RemoteAllow, "scp-remote-allow", nil, "Remote paths teleport_scp may read or write, relative paths being under the login's home (default: all paths not denied)")
+	cmd.Flags().StringSliceVar(&scpPolicy.RemoteDeny, "scp-remote-deny", policy.DefaultRemoteDeny, "Remote paths teleport_scp never reads or writes; denial takes precedence over --scp-remote-allow")
+
+	// Kubeconfig flags
+	cmd.Flags().BoolVar(&kubeconfigIsolation, "kubeconfig-isolation", true, "Keep kubeconfigs in a private directory, one per MCP session, instead of changing the user's kubeconfig; the directory is removed on shutdown")
+	cmd.Flags().StringVar(&kubeconfigDir, "kubeconfig-dir", "", "Directory in which the private kubeconfig directory is created (default: the system temporary directory)")
+
 	// Transport flags
 	cmd.Flags().StringVar(&transport, "transport", "stdio", "Transport type: stdio, sse, or streamable-http")
 	cmd.Flags().StringVar(&httpAddr, "http-addr", ":8080", "HTTP server address (for sse and streamable-http transports)")
@@ -84,8 +157,28 @@ Supports multiple transport types:
 	return cmd
 }
 
+// parseToolTimeouts converts tool=duration flag values into per-tool timeouts
+func parseToolTimeouts(values map[string]string) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration, len(values))
+	for tool, value := range values {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --tool-timeout for %s: %w", tool, err)
+		}
+		if timeout <= 0 {
+			return nil, fmt.Errorf("invalid --tool-timeout for %s: must be positive", tool)
+		}
+		timeouts[tool] = timeout
+	}
+	return timeouts, nil
+}
+
 // runServe contains the main server logic with support for multiple transports
 func runServe(transport string, nonDestructiveMode, dryRun bool, debugMode bool,
+	defaultTimeout time.Duration, toolTimeouts map[string]time.Duration, maxTimeout time.Duration,
+	expiryWarning time.Duration, sshConcurrency int, jobTimeout time.Duration,
+	outputLimit, captureLimit int, scpPolicy policy.SCPPolicy, sshPolicy *policy.SSHPolicy,
+	kubeconfigIsolation bool, kubeconfigDir string,
 	httpAddr, sseEndpoint, messageEndpoint, httpEndpoint string) error {
 
 	// Setup graceful shutdown - listen for both SIGINT and SIGTERM
@@ -98,6 +191,18 @@ func runServe(transport string, nonDestructiveMode, dryRun bool, debugMode bool,
 		server.WithNonDestructiveMode(nonDestructiveMode),
 		server.WithDryRun(dryRun),
 		server.WithDebugMode(debugMode),
+		server.WithDefaultTimeout(defaultTimeout),
+		server.WithToolTimeouts(toolTimeouts),
+		server.WithMaxTimeout(maxTimeout),
+		server.WithExpiryWarning(expiryWarning),
+		server.WithSSHConcurrency(sshConcurrency),
+		server.WithJobTimeout(jobTimeout),
+		server.WithOutputLimit(outputLimit),
+		server.WithCaptureLimit(captureLimit),
+		server.WithSCPPolicy(scpPolicy),
+		server.WithSSHPolicy(sshPolicy),
+		server.WithKubeconfigIsolation(kubeconfigIsolation),
+		server.WithKubeconfigDir(kubeconfigDir),
 		server.WithLogger(&simpleLogger{}),
 	)
 	if err != nil {
@@ -109,10 +214,17 @@ func runServe(transport string, nonDestructiveMode, dryRun bool, debugMode bool,
 		}
 	}()
 
+	// Track tool calls so they can be cancelled by the client or on shutdown
+	hooks := &mcpserver.Hooks{}
+	hooks.AddBeforeCallTool(serverContext.TrackToolCall)
+
 	// Create MCP server
 	mcpSrv := mcpserver.NewMCPServer("mcp-teleport", rootCmd.Version,
 		mcpserver.WithToolCapabilities(true),
+		mcpserver.WithHooks(hooks),
+		mcpserver.WithToolHandlerMiddleware(serverContext.ToolHandlerMiddleware),
 	)
+	mcpSrv.AddNotificationHandler(server.CancelledNotificationMethod, serverContext.HandleCancelledNotification)
 
 	// Register all tool categories
 	if err := auth.RegisterAuthTools(mcpSrv, serverContext); err != nil {
@@ -123,6 +235,14 @@ func runServe(transport string, nonDestructiveMode, dryRun bool, debugMode bool,
 		return fmt.Errorf("failed to register SSH tools: %w", err)
 	}
 
+	if err := jobs.RegisterJobTools(mcpSrv, serverContext); err != nil {
+		return fmt.Errorf("failed to register job tools: %w", err)
+	}
+
+	if err := output.RegisterOutputTools(mcpSrv, serverContext); err != nil {
+		return fmt.Errorf("failed to register output tools: %w", err)
+	}
+
 	if err := kube.RegisterKubeTools(mcpSrv, serverContext); err != nil {
 		return fmt.Errorf("failed to register Kubernetes tools: %w", err)
 	}
@@ -131,6 +251,10 @@ func runServe(transport string, nonDestructiveMode, dryRun bool, debugMode bool,
 		return fmt.Errorf("failed to register database tools: %w", err)
 	}
 
+	if err := access.RegisterAccessRequestTools(mcpSrv, serverContext); err != nil {
+		return fmt.Errorf("failed to register access request tools: %w", err)
+	}
+
 	if err := apps.RegisterAppTools(mcpSrv, serverContext); err != nil {
 		return fmt.Errorf("failed to register app tools: %w", err)
 	}
@@ -0,0 +1,386 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SimpleCommand is a single command of a shell command line, e.g. one stage of a pipeline
+type SimpleCommand struct {
+	Argv      []string
+	Redirects []Redirect
+}
+
+// Redirect is a redirection attached to a simple command
+type Redirect struct {
+	// Fd is the explicit file descriptor prefix (e.g. "2" in 2>&1), if any
+	Fd string
+	// Op is the redirection operator: <, <<, <<<, >, >>, >|, >&, &> or &>>
+	Op string
+	// Target is the file name or file descriptor the redirection points to
+	Target string
+}
+
+// CommandLine is a shell command line split into simple commands
+type CommandLine struct {
+	Commands []SimpleCommand
+	// Substitution is set when the line contains command, arithmetic or process substitution
+	Substitution bool
+	// Subshell is set when the line contains unquoted parentheses
+	Subshell bool
+	// Expansion is set when the line contains parameter expansion ($name,
+	// ${...}), $'...' quoting or brace expansion ({a,b}, {1..3}), whose words
+	// are only known to the shell
+	Expansion bool
+}
+
+// ParseCommandLine splits a POSIX shell command line into simple commands.
+// It understands quoting, escaping, pipelines, command lists and redirections,
+// which is enough to reason about what a command does without executing it.
+// Constructs it does not model (substitutions, subshells, expansions) are
+// flagged on the result rather than rejected, so callers can decide how to
+// treat them.
+func ParseCommandLine(line string) (*CommandLine, error) {
+	p := &commandParser{input: line}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return &p.result, nil
+}
+
+// commandParser holds the tokenizer state for ParseCommandLine
+type commandParser struct {
+	input  string
+	pos    int
+	result CommandLine
+
+	word     strings.Builder
+	haveWord bool
+	quoted   bool
+
+	current  SimpleCommand
+	redirect *Redirect
+}
+
+func (p *commandParser) parse() error {
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		switch {
+		case c == ' ' || c == '\t':
+			p.pos++
+			if err := p.endWord(); err != nil {
+				return err
+			}
+		case c == '\n' || c == ';':
+			p.pos++
+			if err := p.endCommand(); err != nil {
+				return err
+			}
+		case c == '|':
+			p.pos++
+			if p.peek() == '|' {
+				p.pos++
+			}
+			if err := p.endCommand(); err != nil {
+				return err
+			}
+		case c == '&':
+			p.pos++
+			switch p.peek() {
+			case '&':
+				p.pos++
+				if err := p.endCommand(); err != nil {
+					return err
+				}
+			case '>':
+				p.pos++
+				op := "&>"
+				if p.peek() == '>' {
+					p.pos++
+					op = "&>>"
+				}
+				if err := p.startRedirect(op); err != nil {
+					return err
+				}
+			default:
+				if err := p.endCommand(); err != nil {
+					return err
+				}
+			}
+		case c == '(' || c == ')':
+			p.pos++
+			p.result.Subshell = true
+			if err := p.endWord(); err != nil {
+				return err
+			}
+		case c == '<' || c == '>':
+			if err := p.parseRedirect(c); err != nil {
+				return err
+			}
+		case c == '#' && !p.haveWord:
+			// Comment until end of line
+			for p.pos < len(p.input) && p.input[p.pos] != '\n' {
+				p.pos++
+			}
+		case c == '\'':
+			p.pos++
+			end := strings.IndexByte(p.input[p.pos:], '\'')
+			if end < 0 {
+				return fmt.Errorf("unterminated single quote")
+			}
+			p.appendWord(p.input[p.pos:p.pos+end], true)
+			p.pos += end + 1
+		case c == '"':
+			if err := p.parseDoubleQuoted(); err != nil {
+				return err
+			}
+		case c == '\\':
+			p.pos++
+			if p.pos < len(p.input) {
+				if p.input[p.pos] != '\n' {
+					p.appendWord(p.input[p.pos:p.pos+1], true)
+				}
+				p.pos++
+			}
+		case c == '`':
+			if err := p.parseBackticks(); err != nil {
+				return err
+			}
+		case c == '$' && p.peekAt(1) == '(':
+			p.pos++
+			if err := p.parseParenthesized("$"); err != nil {
+				return err
+			}
+		case c == '$' && (isExpansionStart(p.peekAt(1)) || p.peekAt(1) == '\'' || p.peekAt(1) == '"'):
+			p.result.Expansion = true
+			p.appendWord("$", false)
+			p.pos++
+		case c == '{' && isBraceExpansion(p.input[p.pos:]):
+			p.result.Expansion = true
+			p.appendWord("{", false)
+			p.pos++
+		default:
+			p.appendWord(p.input[p.pos:p.pos+1], false)
+			p.pos++
+		}
+	}
+	return p.endCommand()
+}
+
+// parseRedirect handles <, <<, <<<, <(, >, >>, >|, >& and >( at the current position
+func (p *commandParser) parseRedirect(c byte) error {
+	if p.peekAt(1) == '(' {
+		// Process substitution
+		p.pos++
+		return p.parseParenthesized(string(c))
+	}
+
+	// A word made only of digits directly before the operator is a file descriptor
+	fd := ""
+	if p.haveWord && !p.quoted && isDigits(p.word.String()) {
+		fd = p.word.String()
+		p.word.Reset()
+		p.haveWord = false
+	}
+	if err := p.endWord(); err != nil {
+		return err
+	}
+
+	p.pos++
+	op := string(c)
+	if c == '<' {
+		for p.peek() == '<' && len(op) < 3 {
+			p.pos++
+			op += "<"
+		}
+	} else {
+		switch p.peek() {
+		case '>', '|', '&':
+			op += string(p.peek())
+			p.pos++
+		}
+	}
+
+	if err := p.startRedirect(op); err != nil {
+		return err
+	}
+	p.redirect.Fd = fd
+	return nil
+}
+
+// parseDoubleQuoted consumes a double-quoted string starting at the current position
+func (p *commandParser) parseDoubleQuoted() error {
+	p.pos++
+	var b strings.Builder
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			p.appendWord(b.String(), true)
+			return nil
+		case c == '\\' && p.pos+1 < len(p.input) && strings.IndexByte("\"\\$`\n", p.input[p.pos+1]) >= 0:
+			if p.input[p.pos+1] != '\n' {
+				b.WriteByte(p.input[p.pos+1])
+			}
+			p.pos += 2
+		case c == '`' || (c == '$' && p.peekAt(1) == '('):
+			p.result.Substitution = true
+			b.WriteByte(c)
+			p.pos++
+		case c == '$' && isExpansionStart(p.peekAt(1)):
+			p.result.Expansion = true
+			b.WriteByte(c)
+			p.pos++
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+	return fmt.Errorf("unterminated double quote")
+}
+
+// parseBackticks consumes a backtick command substitution
+func (p *commandParser) parseBackticks() error {
+	p.result.Substitution = true
+	end := strings.IndexByte(p.input[p.pos+1:], '`')
+	if end < 0 {
+		return fmt.Errorf("unterminated command substitution")
+	}
+	p.appendWord(p.input[p.pos:p.pos+end+2], false)
+	p.pos += end + 2
+	return nil
+}
+
+// parseParenthesized consumes a balanced (...) group following prefix, which is
+// either "$" (command or arithmetic substitution) or "<"/">" (process substitution)
+func (p *commandParser) parseParenthesized(prefix string) error {
+	p.result.Substitution = true
+	start := p.pos
+	depth := 0
+	for p.pos < len(p.input) {
+		switch p.input[p.pos] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		p.pos++
+		if depth == 0 {
+			p.appendWord(prefix+p.input[start:p.pos], false)
+			return nil
+		}
+	}
+	return fmt.Errorf("unterminated substitution")
+}
+
+func (p *commandParser) startRedirect(op string) error {
+	if err := p.endWord(); err != nil {
+		return err
+	}
+	if p.redirect != nil {
+		return fmt.Errorf("missing target for redirection %q", p.redirect.Op)
+	}
+	p.redirect = &Redirect{Op: op}
+	return nil
+}
+
+func (p *commandParser) appendWord(s string, quoted bool) {
+	p.word.WriteString(s)
+	p.haveWord = true
+	p.quoted = p.quoted || quoted
+}
+
+func (p *commandParser) endWord() error {
+	if !p.haveWord {
+		return nil
+	}
+	word := p.word.String()
+	p.word.Reset()
+	p.haveWord = false
+	p.quoted = false
+
+	if p.redirect != nil {
+		p.redirect.Target = word
+		p.current.Redirects = append(p.current.Redirects, *p.redirect)
+		p.redirect = nil
+		return nil
+	}
+	p.current.Argv = append(p.current.Argv, word)
+	return nil
+}
+
+func (p *commandParser) endCommand() error {
+	if err := p.endWord(); err != nil {
+		return err
+	}
+	if p.redirect != nil {
+		return fmt.Errorf("missing target for redirection %q", p.redirect.Op)
+	}
+	if len(p.current.Argv) > 0 || len(p.current.Redirects) > 0 {
+		p.result.Commands = append(p.result.Commands, p.current)
+	}
+	p.current = SimpleCommand{}
+	return nil
+}
+
+func (p *commandParser) peek() byte {
+	return p.peekAt(0)
+}
+
+func (p *commandParser) peekAt(offset int) byte {
+	if i := p.pos + offset; i < len(p.input) {
+		return p.input[i]
+	}
+	return 0
+}
+
+// isExpansionStart reports whether c after a $ starts a parameter expansion:
+// a name, a positional or special parameter, ${...} or $[...]
+func isExpansionStart(c byte) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '_':
+		return true
+	}
+	return c != 0 && strings.IndexByte("{[@*#?$!-", c) >= 0
+}
+
+// isBraceExpansion reports whether the { at the start of s opens a brace
+// expansion: an unquoted , or .. followed by an unquoted } within the same
+// word. Quoted text is skipped, so it may report words bash leaves alone.
+func isBraceExpansion(s string) bool {
+	separated := false
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\':
+			i++
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(s[i+1:], c)
+			if end < 0 {
+				return false
+			}
+			i += end + 1
+		case c == ',' || (c == '.' && i+1 < len(s) && s[i+1] == '.'):
+			separated = true
+		case c == '}':
+			if separated {
+				return true
+			}
+		case strings.IndexByte(" \t\n;|&<>()", c) >= 0:
+			return false
+		}
+	}
+	return false
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+// Package policy decides which operations mcp-teleport may perform on behalf
+// of an AI assistant.
+//
+// # Read-only Commands
+//
+// When the server runs in non-destructive mode (the default), teleport_ssh
+// only executes commands that CheckReadOnly accepts. A command line is
+// considered read-only when all of the following hold:
+//
+//   - It contains no command substitution ($(...), backticks), process
+//     substitution (<(...), >(...)), variable expansion ($name, ${...}),
+//     brace expansion ({a,b}, {1..3}), subshells or here-documents.
+//   - Output is only redirected to /dev/null or duplicated onto another file
+//     descriptor (for example 2>&1). Input redirection from files is allowed.
+//   - Every command in a pipeline or list (|, ;, &&, ||, &) is on the
+//     read-only allowlist, optionally prefixed by a bare "sudo".
+//   - Commands with subcommands (systemctl, kubectl, docker, crictl) use
+//     an inspection subcommand such as "status", "get" or "show". Options
+//     placed before the subcommand must use the --option=value form, except
+//     for a few known flags such as systemctl --no-pager.
+//   - No argument that makes an otherwise read-only command write is present,
+//     for example find -delete, ip route add or journalctl --vacuum-size.
+//     Denied long options are also recognized abbreviated (--vacuum-s),
+//     denied short options also clustered or with an attached value (-qp,
+//     -ofile), and ip verbs abbreviated (ip route a).
+//
+// Anything the policy cannot prove to be read-only is rejected, including
+// interpreters (sh, bash, python), editors, sed, awk, xargs and tee.
+//
+// The Kubernetes tools check their tsh kubectl arguments with
+// CheckReadOnlyKubectl, which applies the kubectl rule of the allowlist.
+//
+// # Read-only SQL
+//
+// teleport_db_query only runs statements that CheckSQLStatement accepts:
+// exactly one statement, without psql backslash meta-commands, mysql client
+// commands or executable comments. In non-destructive mode CheckReadOnlySQL
+// additionally requires a SELECT, SHOW or EXPLAIN SELECT statement without
+// INTO that calls none of the known built-in functions with side effects,
+// such as pg_terminate_backend or dblink_exec. User-defined functions are not
+// checked. Statements are scanned with both PostgreSQL and MySQL lexical rules
+// so that dialect differences in comments and quoting cannot hide a second
+// statement.
+//
+// # SSH Rules
+//
+// An SSHPolicy, loaded with LoadSSHPolicy from a JSON file, restricts which
+// commands teleport_ssh runs where. Its rules are evaluated in order and the
+// first rule whose conditions all match decides; without a matching rule the
+// default action applies, which is deny unless configured otherwise. Rules
+// match on a command regular expression, the programs of the command line,
+// the hostname, node labels, the login and the cluster. Conditions on values
+// that cannot be determined fail closed: deny rules match, allow rules do
+// not. Evaluate returns the deciding rule together with a trace of every
+// rule evaluated before it.
+//
+// # SCP Paths
+//
+// teleport_scp only transfers paths that SCPPolicy accepts. Patterns use
+// path.Match syntax and ~ for the home directory, and match a path and
+// everything below it. Deny patterns take precedence over allow patterns;
+// without allow patterns, everything not denied is allowed.
+//
+// CheckLocal makes local paths absolute and resolves symbolic links in the
+// existing part of the path before matching. CheckRemote writes remote paths
+// relative to the login's home directory as ~/... and rejects paths that
+// leave it or contain glob or shell metacharacters, which the remote side
+// may expand. For recursive transfers both also reject directories
+// containing a denied path. DefaultSCPPolicy denies the credentials of tsh,
+// ssh, kubectl and cloud CLIs locally and account, sudo and SSH
+// configuration remotely.
+//
+// # Usage
+//
+//	if err := policy.CheckReadOnly("df -h && journalctl -u kubelet | tail -n 50"); err != nil {
+//	    // refuse the command
+//	}
+//
+//	if err := policy.CheckReadOnlySQL("SELECT count(*) FROM orders"); err != nil {
+//	    // refuse the statement
+//	}
+//
+//	decision := sshPolicy.Evaluate(policy.SSHTarget{Command: "df -h", Host: "wallaby-9wldd", Login: "root"})
+//	if !decision.Allowed {
+//	    // refuse the command, naming decision.Rule
+//	}
+//
+//	if _, err := policy.DefaultSCPPolicy().CheckLocal("~/.tsh/keys", false); err != nil {
+//	    // refuse the transfer
+//	}
+package policy
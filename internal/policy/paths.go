@@ -0,0 +1,243 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultLocalDeny lists the local paths teleport_scp refuses by default:
+// credentials of tsh, ssh, kubectl, cloud CLIs and other tools on the host
+// running mcp-teleport
+var DefaultLocalDeny = []string{
+	"~/.tsh",
+	"~/.ssh",
+	"~/.kube",
+	"~/.aws",
+	"~/.azure",
+	"~/.config/gcloud",
+	"~/.docker/config.json",
+	"~/.gnupg",
+	"~/.netrc",
+}
+
+// DefaultRemoteDeny lists the remote paths teleport_scp refuses by default:
+// account, sudo and SSH key files whose exposure or replacement compromises
+// the node
+var DefaultRemoteDeny = []string{
+	"/etc/shadow",
+	"/etc/gshadow",
+	"/etc/sudoers",
+	"/etc/sudoers.d",
+	"/etc/ssh",
+	"/root/.ssh",
+	"~/.ssh",
+}
+
+// SCPPolicy restricts the local and remote paths teleport_scp may transfer
+// from or to. A pattern matches a path if it matches the path itself or one
+// of its parent directories, so a directory pattern covers everything below
+// it. Patterns may use the wildcards of path.Match, and ~ for the home
+// directory. Deny patterns take precedence; if allow patterns are set, a
+// path must match one of them. A recursive transfer is also denied if a deny
+// pattern matches something inside the copied directory.
+type SCPPolicy struct {
+	LocalAllow  []string
+	LocalDeny   []string
+	RemoteAllow []string
+	RemoteDeny  []string
+}
+
+// DefaultSCPPolicy returns the policy used unless one is configured
+func DefaultSCPPolicy() SCPPolicy {
+	return SCPPolicy{
+		LocalDeny:  append([]string{}, DefaultLocalDeny...),
+		RemoteDeny: append([]string{}, DefaultRemoteDeny...),
+	}
+}
+
+// CheckLocal returns the absolute path, with symbolic links resolved, that a
+// local path refers to, or an error if the policy does not allow it
+func (p SCPPolicy) CheckLocal(localPath string, recursive bool) (string, error) {
+	if localPath == "" {
+		return "", errors.New("empty local path")
+	}
+	absolute, err := absLocalPath(localPath)
+	if err != nil {
+		return "", err
+	}
+	resolved := resolveLocalPath(absolute)
+
+	// Check both names so that neither a symlink into a denied directory nor
+	// a denied symlink to elsewhere gets through
+	for _, pattern := range p.LocalDeny {
+		if pattern == "" {
+			continue
+		}
+		pattern, err := absLocalPath(pattern)
+		if err != nil {
+			return "", err
+		}
+		if matchPath(pattern, absolute) || matchPath(resolveLocalPath(pattern), resolved) {
+			return "", fmt.Errorf("local path %s is denied by %s", localPath, pattern)
+		}
+		if recursive && (containsMatch(pattern, absolute) || containsMatch(resolveLocalPath(pattern), resolved)) {
+			return "", fmt.Errorf("local directory %s contains paths denied by %s", localPath, pattern)
+		}
+	}
+
+	if allow := nonEmpty(p.LocalAllow); len(allow) > 0 {
+		for _, pattern := range allow {
+			pattern, err := absLocalPath(pattern)
+			if err != nil {
+				return "", err
+			}
+			if matchPath(resolveLocalPath(pattern), resolved) {
+				return resolved, nil
+			}
+		}
+		return "", fmt.Errorf("local path %s is not in the allowed paths %s", localPath, strings.Join(allow, ", "))
+	}
+	return resolved, nil
+}
+
+// remoteMetacharacters are the characters a remote shell or scp may expand in a path
+const remoteMetacharacters = "*?[{$`"
+
+// CheckRemote returns the normalized form of a remote path, with relative
+// paths taken from ~, or an error if the policy does not allow it. Paths with
+// glob or shell metacharacters are rejected, since the remote side may
+// expand them to a denied path.
+func (p SCPPolicy) CheckRemote(remotePath string, recursive bool) (string, error) {
+	if strings.ContainsAny(remotePath, remoteMetacharacters) {
+		return "", fmt.Errorf("remote path %s contains one of %s, which the remote side may expand", remotePath, remoteMetacharacters)
+	}
+	normalized, err := normalizeRemotePath(remotePath)
+	if err != nil {
+		return "", err
+	}
+
+	for _, pattern := range p.RemoteDeny {
+		if pattern == "" {
+			continue
+		}
+		pattern, err := normalizeRemotePath(pattern)
+		if err != nil {
+			return "", err
+		}
+		if matchPath(pattern, normalized) {
+			return "", fmt.Errorf("remote path %s is denied by %s", remotePath, pattern)
+		}
+		if recursive && containsMatch(pattern, normalized) {
+			return "", fmt.Errorf("remote directory %s contains paths denied by %s", remotePath, pattern)
+		}
+	}
+
+	if allow := nonEmpty(p.RemoteAllow); len(allow) > 0 {
+		for _, pattern := range allow {
+			pattern, err := normalizeRemotePath(pattern)
+			if err != nil {
+				return "", err
+			}
+			if matchPath(pattern, normalized) {
+				return normalized, nil
+			}
+		}
+		return "", fmt.Errorf("remote path %s is not in the allowed paths %s", remotePath, strings.Join(allow, ", "))
+	}
+	return normalized, nil
+}
+
+// matchPath reports whether pattern matches name or one of its parent directories
+func matchPath(pattern, name string) bool {
+	for {
+		if matched, err := path.Match(pattern, name); err == nil && matched {
+			return true
+		}
+		parent := path.Dir(name)
+		if parent == name || parent == "." {
+			return false
+		}
+		name = parent
+	}
+}
+
+// containsMatch reports whether pattern can match a path below the directory dir
+func containsMatch(pattern, dir string) bool {
+	if dir == "/" {
+		return strings.HasPrefix(pattern, "/")
+	}
+	patternParts := strings.Split(pattern, "/")
+	dirParts := strings.Split(dir, "/")
+	if len(patternParts) <= len(dirParts) {
+		return false
+	}
+	matched, err := path.Match(strings.Join(patternParts[:len(dirParts)], "/"), dir)
+	return err == nil && matched
+}
+
+// absLocalPath expands ~ and makes a local path absolute
+func absLocalPath(localPath string) (string, error) {
+	if localPath == "~" || strings.HasPrefix(localPath, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("cannot expand %s: %w", localPath, err)
+		}
+		localPath = filepath.Join(home, localPath[1:])
+	}
+	absolute, err := filepath.Abs(localPath)
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve %s: %w", localPath, err)
+	}
+	return absolute, nil
+}
+
+// resolveLocalPath resolves symbolic links in the longest existing prefix of
+// an absolute path; the rest, which need not exist yet, is kept as is
+func resolveLocalPath(absolute string) string {
+	rest := ""
+	for current := absolute; ; current = filepath.Dir(current) {
+		if resolved, err := filepath.EvalSymlinks(current); err == nil {
+			return filepath.Join(resolved, rest)
+		}
+		if filepath.Dir(current) == current {
+			return absolute
+		}
+		rest = filepath.Join(filepath.Base(current), rest)
+	}
+}
+
+// normalizeRemotePath cleans a remote path, writing paths relative to the
+// login's home directory as ~/...
+func normalizeRemotePath(remotePath string) (string, error) {
+	if strings.HasPrefix(remotePath, "/") {
+		return path.Clean(remotePath), nil
+	}
+
+	relative := strings.TrimPrefix(strings.TrimPrefix(remotePath, "~"), "/")
+	if strings.HasPrefix(remotePath, "~") && !strings.HasPrefix(remotePath, "~/") && remotePath != "~" {
+		return "", fmt.Errorf("remote path %s refers to another user's home directory", remotePath)
+	}
+	relative = path.Clean(relative)
+	if relative == ".." || strings.HasPrefix(relative, "../") {
+		return "", fmt.Errorf("remote path %s leaves the home directory", remotePath)
+	}
+	if relative == "." {
+		return "~", nil
+	}
+	return "~/" + relative, nil
+}
+
+// nonEmpty returns the non-empty values
+func nonEmpty(values []string) []string {
+	var result []string
+	for _, value := range values {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,127 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSCPPolicyCheckLocal(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, dir := range []string{".tsh/keys", "transfers", "other"} {
+		if err := os.MkdirAll(filepath.Join(home, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(home, ".tsh"), filepath.Join(home, "transfers", "innocent")); err != nil {
+		t.Fatal(err)
+	}
+
+	scpPolicy := DefaultSCPPolicy()
+	scpPolicy.LocalAllow = []string{"~/transfers", "/tmp/*.log"}
+
+	allowed := map[string]string{
+		"~/transfers/report.txt":     filepath.Join(home, "transfers", "report.txt"),
+		"~/transfers/new/report.txt": filepath.Join(home, "transfers", "new", "report.txt"),
+		"/tmp/kubelet.log":           "/tmp/kubelet.log",
+	}
+	for localPath, expected := range allowed {
+		t.Run("allow "+localPath, func(t *testing.T) {
+			resolved, err := scpPolicy.CheckLocal(localPath, false)
+			if err != nil {
+				t.Fatalf("Expected %s to be allowed, got: %v", localPath, err)
+			}
+			if resolved != expected {
+				t.Errorf("Expected %s to resolve to %s, got %s", localPath, expected, resolved)
+			}
+		})
+	}
+
+	denied := []string{
+		"",
+		"~/.tsh/keys/teleport.example.com",
+		home + "/transfers/../.tsh",
+		"~/transfers/innocent/keys",
+		"~/other/file",
+		"/tmp/kubelet.txt",
+	}
+	for _, localPath := range denied {
+		t.Run("deny "+localPath, func(t *testing.T) {
+			if _, err := scpPolicy.CheckLocal(localPath, false); err == nil {
+				t.Errorf("Expected %s to be denied", localPath)
+			}
+		})
+	}
+
+	// Copying a parent of a denied directory recursively would copy it too
+	scpPolicy.LocalAllow = nil
+	if _, err := scpPolicy.CheckLocal("~/other", true); err != nil {
+		t.Errorf("Expected ~/other to be allowed, got: %v", err)
+	}
+	if _, err := scpPolicy.CheckLocal("~", false); err != nil {
+		t.Errorf("Expected ~ to be allowed, got: %v", err)
+	}
+	if _, err := scpPolicy.CheckLocal("~", true); err == nil {
+		t.Error("Expected ~ to be denied recursively")
+	}
+}
+
+func TestSCPPolicyCheckRemote(t *testing.T) {
+	scpPolicy := DefaultSCPPolicy()
+
+	allowed := map[string]string{
+		"/var/log/syslog":     "/var/log/syslog",
+		"file.txt":            "~/file.txt",
+		"~/logs/../file.txt":  "~/file.txt",
+		"":                    "~",
+		"/etc/shadow.example": "/etc/shadow.example",
+	}
+	for remotePath, expected := range allowed {
+		t.Run("allow "+remotePath, func(t *testing.T) {
+			normalized, err := scpPolicy.CheckRemote(remotePath, false)
+			if err != nil {
+				t.Fatalf("Expected %s to be allowed, got: %v", remotePath, err)
+			}
+			if normalized != expected {
+				t.Errorf("Expected %s to normalize to %s, got %s", remotePath, expected, normalized)
+			}
+		})
+	}
+
+	denied := []string{
+		"/etc/shadow",
+		"/etc//sudoers.d/admins",
+		"/tmp/../etc/ssh/sshd_config",
+		".ssh/authorized_keys",
+		"~/.ssh",
+		"../other/file",
+		"~alice/file",
+		"/etc/shad*",
+		"/etc/[s]hadow",
+		"/root/.ss?/authorized_keys",
+		"/etc/{shadow,hosts}",
+		"/etc/$f",
+	}
+	for _, remotePath := range denied {
+		t.Run("deny "+remotePath, func(t *testing.T) {
+			if _, err := scpPolicy.CheckRemote(remotePath, false); err == nil {
+				t.Errorf("Expected %s to be denied", remotePath)
+			}
+		})
+	}
+
+	for _, remotePath := range []string{"/", "/etc", "~"} {
+		if _, err := scpPolicy.CheckRemote(remotePath, true); err == nil {
+			t.Errorf("Expected %s to be denied recursively", remotePath)
+		}
+	}
+
+	scpPolicy.RemoteAllow = []string{"/tmp"}
+	if _, err := scpPolicy.CheckRemote("/tmp/upload/file", false); err != nil {
+		t.Errorf("Expected /tmp/upload/file to be allowed, got: %v", err)
+	}
+	if _, err := scpPolicy.CheckRemote("/var/tmp/file", false); err == nil {
+		t.Error("Expected /var/tmp/file to be denied")
+	}
+}
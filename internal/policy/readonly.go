@@ -0,0 +1,331 @@
+package policy
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// commandRule describes how a read-only command may be invoked
+type commandRule struct {
+	// subcommands, if set, lists the allowed values of the first non-option
+	// argument. Options before it must use the --opt=value form, unless they
+	// are listed in flags, so that an option value is never taken for the
+	// subcommand.
+	subcommands []string
+	flags       []string
+	// deniedArgs lists options that make the command write. Long options are
+	// also matched abbreviated and in the --opt=value form, single-letter
+	// options also clustered (-qp) and with an attached value (-ofile).
+	deniedArgs []string
+	// abbreviated is set for commands such as ip whose single-dash long
+	// options may be abbreviated (-b for -batch)
+	abbreviated bool
+	// check performs additional validation of the arguments
+	check func(args []string) error
+}
+
+// readOnlyCommands is the allowlist of commands considered read-only
+var readOnlyCommands = map[string]commandRule{
+	// Files and text
+	"cat": {}, "tac": {}, "head": {}, "tail": {}, "nl": {}, "wc": {},
+	"grep": {}, "egrep": {}, "fgrep": {}, "zgrep": {}, "zcat": {},
+	"ls": {}, "stat": {}, "file": {}, "du": {}, "df": {},
+	"readlink": {}, "realpath": {}, "basename": {}, "dirname": {},
+	"cut": {}, "tr": {}, "column": {}, "jq": {}, "strings": {}, "od": {}, "hexdump": {},
+	"diff": {}, "cmp": {}, "comm": {},
+	"sha256sum": {}, "sha1sum": {}, "md5sum": {}, "cksum": {},
+	"echo": {}, "printf": {}, "true": {}, "false": {}, "test": {}, "[": {}, "pwd": {}, "which": {},
+	"find": {deniedArgs: []string{"-delete", "-exec", "-execdir", "-ok", "-okdir", "-fprint", "-fprint0", "-fprintf", "-fls"}},
+	"sort": {deniedArgs: []string{"-o", "--output", "--compress-program", "-T", "--temporary-directory"}},
+	"tree": {deniedArgs: []string{"-o", "-R"}},
+	"uniq": {check: maxOperands(1)},
+
+	// System information
+	"uptime": {}, "uname": {}, "whoami": {}, "id": {}, "groups": {}, "arch": {}, "nproc": {},
+	"w": {}, "who": {}, "last": {}, "ps": {}, "pgrep": {}, "free": {},
+	"vmstat": {}, "iostat": {}, "mpstat": {}, "lscpu": {}, "lsmod": {}, "lspci": {}, "lsusb": {},
+	"lsblk": {}, "findmnt": {}, "lsof": {}, "printenv": {},
+	"hostname": {deniedArgs: []string{"-F", "--file", "-b", "--boot"}, check: maxOperands(0)},
+	"date":     {deniedArgs: []string{"-s", "--set"}, check: formatOperandsOnly},
+	"dmesg":    {deniedArgs: []string{"-c", "-C", "-D", "-E", "-n", "--clear", "--read-clear", "--console-off", "--console-on", "--console-level"}},
+	"sysctl":   {deniedArgs: []string{"-w", "--write", "-p", "--load", "--system"}, check: noAssignments},
+	"journalctl": {deniedArgs: []string{
+		"--rotate", "--vacuum-size", "--vacuum-time", "--vacuum-files", "--flush", "--sync",
+		"--relinquish-var", "--smart-relinquish-var", "--setup-keys", "--update-catalog",
+	}},
+
+	// Networking
+	"ss":      {deniedArgs: []string{"-K", "--kill"}},
+	"netstat": {}, "ping": {}, "dig": {}, "nslookup": {}, "host": {}, "getent": {},
+	"ip": {deniedArgs: []string{"-batch", "-force"}, abbreviated: true, check: ipReadOnly},
+
+	// Services and containers
+	"systemctl": {subcommands: []string{
+		"status", "show", "cat", "list-units", "list-unit-files", "list-timers", "list-sockets",
+		"list-dependencies", "list-jobs", "is-active", "is-enabled", "is-failed",
+		"is-system-running", "get-default",
+	}, flags: []string{
+		"--no-pager", "--no-legend", "--plain", "--full", "--all", "--user", "--system", "--quiet",
+		"-l", "-a", "-q",
+	}},
+	"timedatectl": {subcommands: []string{"status", "show", "list-timezones"}},
+	"hostnamectl": {subcommands: []string{"status"}},
+	"kubectl": {subcommands: []string{
+		"get", "describe", "logs", "top", "version", "api-resources", "api-versions",
+		"cluster-info", "explain",
+	}},
+	"crictl": {subcommands: []string{
+		"ps", "pods", "images", "img", "inspect", "inspectp", "inspecti", "logs", "stats",
+		"statsp", "info", "version",
+	}},
+	"docker": {subcommands: []string{
+		"ps", "images", "inspect", "logs", "stats", "info", "version", "top", "port", "diff", "history",
+	}},
+}
+
+// trustedBinDirs are the directories from which absolute command paths are accepted
+var trustedBinDirs = []string{"/bin", "/sbin", "/usr/bin", "/usr/sbin", "/usr/local/bin", "/usr/local/sbin"}
+
+// CheckReadOnly returns nil if the command line only runs read-only commands,
+// and an error describing the first violation otherwise. See the package
+// documentation for the policy.
+func CheckReadOnly(command string) error {
+	line, err := ParseCommandLine(command)
+	if err != nil {
+		return fmt.Errorf("cannot parse command: %w", err)
+	}
+
+	if line.Substitution {
+		return fmt.Errorf("command and process substitution are not allowed")
+	}
+	if line.Subshell {
+		return fmt.Errorf("subshells are not allowed")
+	}
+	if line.Expansion {
+		return fmt.Errorf("variable expansion is not allowed")
+	}
+	if len(line.Commands) == 0 {
+		return fmt.Errorf("empty command")
+	}
+
+	for _, c := range line.Commands {
+		for _, r := range c.Redirects {
+			if err := checkReadOnlyRedirect(r); err != nil {
+				return err
+			}
+		}
+		if err := checkReadOnlyArgv(c.Argv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckReadOnlyKubectl returns nil if kubectl with the arguments, which do
+// not include the program name, only reads from the cluster. Options placed
+// before the subcommand must use the --option=value form.
+func CheckReadOnlyKubectl(args []string) error {
+	return checkReadOnlyArgv(append([]string{"kubectl"}, args...))
+}
+
+// checkReadOnlyRedirect allows input redirection and discarding or duplicating output
+func checkReadOnlyRedirect(r Redirect) error {
+	switch r.Op {
+	case "<":
+		return nil
+	case "<<", "<<<":
+		return fmt.Errorf("here-documents are not allowed")
+	case ">&":
+		if isDigits(r.Target) || r.Target == "-" {
+			return nil
+		}
+	}
+	if r.Target == "/dev/null" {
+		return nil
+	}
+	return fmt.Errorf("output redirection to %q is not allowed", r.Target)
+}
+
+// checkReadOnlyArgv validates a single command against the allowlist
+func checkReadOnlyArgv(argv []string) error {
+	if len(argv) == 0 {
+		// A bare redirection such as "> /dev/null" runs nothing
+		return nil
+	}
+
+	if argv[0] == "sudo" {
+		if len(argv) == 1 {
+			return fmt.Errorf("sudo without a command is not allowed")
+		}
+		if strings.HasPrefix(argv[1], "-") {
+			return fmt.Errorf("sudo options are not allowed")
+		}
+		argv = argv[1:]
+	}
+
+	name := argv[0]
+	if strings.Contains(name, "/") {
+		dir, base := path.Split(name)
+		if !isTrustedBinDir(strings.TrimSuffix(dir, "/")) {
+			return fmt.Errorf("command %q is not in a trusted system directory", name)
+		}
+		name = base
+	}
+
+	rule, ok := readOnlyCommands[name]
+	if !ok {
+		return fmt.Errorf("command %q is not on the read-only allowlist", name)
+	}
+
+	args := argv[1:]
+	if len(rule.subcommands) > 0 {
+		sub, err := subcommand(args, rule.flags)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if sub != "" && !contains(rule.subcommands, sub) {
+			return fmt.Errorf("%s %s is not a read-only subcommand (allowed: %s)", name, sub, strings.Join(rule.subcommands, ", "))
+		}
+	}
+
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		for _, denied := range rule.deniedArgs {
+			if matchesOption(arg, denied, rule.abbreviated) {
+				return fmt.Errorf("%s %s can modify the system", name, denied)
+			}
+		}
+	}
+
+	if rule.check != nil {
+		if err := rule.check(args); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// matchesOption reports whether arg sets the denied option
+func matchesOption(arg, denied string, abbreviated bool) bool {
+	if !strings.HasPrefix(arg, "-") || arg == "-" {
+		return false
+	}
+	option, _, _ := strings.Cut(arg, "=")
+	switch {
+	case strings.HasPrefix(denied, "--"):
+		// getopt_long accepts any unambiguous prefix of a long option
+		return strings.HasPrefix(denied, option) && len(option) > 2
+	case len(denied) == 2:
+		// Short options may be clustered, and the last one may carry its
+		// value; any occurrence of the letter counts
+		return !strings.HasPrefix(arg, "--") && strings.ContainsRune(arg[1:], rune(denied[1]))
+	case abbreviated:
+		return strings.HasPrefix(denied, option) && len(option) > 1
+	}
+	return option == denied
+}
+
+// subcommand returns the first operand of args. Options before it must not
+// take a separate value: they are either listed in flags or use the
+// --opt=value form.
+func subcommand(args, flags []string) (string, error) {
+	for _, arg := range args {
+		switch {
+		case !strings.HasPrefix(arg, "-") || arg == "-":
+			return arg, nil
+		case contains(flags, arg):
+		case strings.HasPrefix(arg, "--") && strings.Contains(arg, "="):
+		default:
+			return "", fmt.Errorf("option %s before the subcommand must use the --option=value form", arg)
+		}
+	}
+	return "", nil
+}
+
+// ipVerbs are the ip commands that change the configuration. ip accepts any
+// prefix of them, so e.g. "ip route a" adds a route.
+var ipVerbs = []string{
+	"add", "delete", "change", "chg", "replace", "set", "flush", "append", "prepend",
+	"save", "restore", "exec", "test", "update", "attach", "detach",
+}
+
+// ipReadOnly rejects ip commands that change the configuration. The first
+// operand is the object (addr, link, route, ...); any later operand that
+// abbreviates a verb is rejected.
+func ipReadOnly(args []string) error {
+	ops := operands(args)
+	if len(ops) < 2 {
+		return nil
+	}
+	for _, op := range ops[1:] {
+		for _, verb := range ipVerbs {
+			if strings.HasPrefix(verb, op) {
+				return fmt.Errorf("%s can modify the system", verb)
+			}
+		}
+	}
+	return nil
+}
+
+// maxOperands rejects commands with more than n non-option arguments
+func maxOperands(n int) func([]string) error {
+	return func(args []string) error {
+		if len(operands(args)) > n {
+			return fmt.Errorf("at most %d operand(s) allowed", n)
+		}
+		return nil
+	}
+}
+
+// formatOperandsOnly rejects date operands that would set the clock
+func formatOperandsOnly(args []string) error {
+	for _, op := range operands(args) {
+		if !strings.HasPrefix(op, "+") {
+			return fmt.Errorf("setting the date is not allowed")
+		}
+	}
+	return nil
+}
+
+// noAssignments rejects sysctl key=value arguments
+func noAssignments(args []string) error {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") && strings.Contains(arg, "=") {
+			return fmt.Errorf("setting kernel parameters is not allowed")
+		}
+	}
+	return nil
+}
+
+func firstOperand(args []string) string {
+	if ops := operands(args); len(ops) > 0 {
+		return ops[0]
+	}
+	return ""
+}
+
+func operands(args []string) []string {
+	var result []string
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			result = append(result, arg)
+		}
+	}
+	return result
+}
+
+func isTrustedBinDir(dir string) bool {
+	return contains(trustedBinDirs, dir)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,263 @@
+package policy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCommandLine(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		expected     []SimpleCommand
+		substitution bool
+		subshell     bool
+		expansion    bool
+		wantErr      bool
+	}{
+		{
+			name:     "simple command",
+			input:    "ls -la /etc",
+			expected: []SimpleCommand{{Argv: []string{"ls", "-la", "/etc"}}},
+		},
+		{
+			name:  "pipeline and list",
+			input: "df -h && journalctl -u kubelet | tail -n 5; uptime",
+			expected: []SimpleCommand{
+				{Argv: []string{"df", "-h"}},
+				{Argv: []string{"journalctl", "-u", "kubelet"}},
+				{Argv: []string{"tail", "-n", "5"}},
+				{Argv: []string{"uptime"}},
+			},
+		},
+		{
+			name:     "quoting and escaping",
+			input:    `grep "a b" 'c|d' e\ f`,
+			expected: []SimpleCommand{{Argv: []string{"grep", "a b", "c|d", "e f"}}},
+		},
+		{
+			name:  "redirections",
+			input: "cat < in.txt 2>&1 >>out.log",
+			expected: []SimpleCommand{{
+				Argv: []string{"cat"},
+				Redirects: []Redirect{
+					{Op: "<", Target: "in.txt"},
+					{Fd: "2", Op: ">&", Target: "1"},
+					{Op: ">>", Target: "out.log"},
+				},
+			}},
+		},
+		{
+			name:         "command substitution",
+			input:        "echo $(rm -rf /)",
+			expected:     []SimpleCommand{{Argv: []string{"echo", "$(rm -rf /)"}}},
+			substitution: true,
+		},
+		{
+			name:         "backticks inside double quotes",
+			input:        "echo \"`id`\"",
+			expected:     []SimpleCommand{{Argv: []string{"echo", "`id`"}}},
+			substitution: true,
+		},
+		{
+			name:     "subshell",
+			input:    "(cd /tmp)",
+			expected: []SimpleCommand{{Argv: []string{"cd", "/tmp"}}},
+			subshell: true,
+		},
+		{
+			name:     "comment",
+			input:    "uptime # how long",
+			expected: []SimpleCommand{{Argv: []string{"uptime"}}},
+		},
+		{
+			name:      "parameter expansion",
+			input:     `ls ${dir} "$HOME" '$literal' 5$`,
+			expected:  []SimpleCommand{{Argv: []string{"ls", "${dir}", "$HOME", "$literal", "5$"}}},
+			expansion: true,
+		},
+		{
+			name:      "brace expansion",
+			input:     `find . -{delete,name} x`,
+			expected:  []SimpleCommand{{Argv: []string{"find", ".", "-{delete,name}", "x"}}},
+			expansion: true,
+		},
+		{
+			name:     "braces without expansion",
+			input:    `echo {} '{a,b}' {a} {a b,c}`,
+			expected: []SimpleCommand{{Argv: []string{"echo", "{}", "{a,b}", "{a}", "{a", "b,c}"}}},
+		},
+		{
+			name:    "unterminated quote",
+			input:   "echo 'oops",
+			wantErr: true,
+		},
+		{
+			name:    "missing redirection target",
+			input:   "echo hi >",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line, err := ParseCommandLine(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got %+v", line)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if !reflect.DeepEqual(line.Commands, tt.expected) {
+				t.Errorf("Expected commands %+v, got %+v", tt.expected, line.Commands)
+			}
+			if line.Substitution != tt.substitution {
+				t.Errorf("Expected substitution=%v, got %v", tt.substitution, line.Substitution)
+			}
+			if line.Subshell != tt.subshell {
+				t.Errorf("Expected subshell=%v, got %v", tt.subshell, line.Subshell)
+			}
+			if line.Expansion != tt.expansion {
+				t.Errorf("Expected expansion=%v, got %v", tt.expansion, line.Expansion)
+			}
+		})
+	}
+}
+
+func TestCheckReadOnly(t *testing.T) {
+	allowed := []string{
+		"uptime",
+		"ls -la /etc/kubernetes",
+		"df -h && free -m",
+		"journalctl -u kubelet --since '1 hour ago' | tail -n 100",
+		"cat /etc/os-release 2>/dev/null || cat /etc/lsb-release",
+		"grep -r error /var/log/syslog 2>&1 | head",
+		"sudo journalctl -u containerd -n 50",
+		"systemctl status kubelet",
+		"systemctl --no-pager list-units",
+		"kubectl get pods -A",
+		"crictl ps",
+		"ip addr show",
+		"sysctl net.ipv4.ip_forward",
+		"find /var/log -name '*.log' -mtime -1",
+		"date +%s",
+		"/usr/bin/uname -a",
+		"dmesg -T",
+		"tree -L 2 /etc",
+		"ss -tlnp",
+		"sort -rn counts.txt",
+		"journalctl --since=today",
+		"grep 'error$' /var/log/syslog",
+		"echo cost: 5$",
+		"systemctl --no-pager status kubelet",
+		"kubectl --namespace=kube-system get pods",
+		"ip -4 addr show dev eth0",
+	}
+	for _, command := range allowed {
+		t.Run("allow "+command, func(t *testing.T) {
+			if err := CheckReadOnly(command); err != nil {
+				t.Errorf("Expected %q to be read-only, got: %v", command, err)
+			}
+		})
+	}
+
+	denied := []string{
+		"",
+		"rm -rf /tmp/foo",
+		"echo hi > /etc/motd",
+		"cat /etc/passwd >> /tmp/copy",
+		"ls; reboot",
+		"ls | sh",
+		"echo $(reboot)",
+		"echo `reboot`",
+		"cat <(reboot)",
+		"(reboot)",
+		"cat <<EOF\nhi\nEOF",
+		"sudo -i",
+		"sudo",
+		"systemctl restart kubelet",
+		"kubectl delete pod foo",
+		"docker rm foo",
+		"ip route add 10.0.0.0/8 via 1.2.3.4",
+		"sysctl -w net.ipv4.ip_forward=1",
+		"sysctl net.ipv4.ip_forward=1",
+		"find / -name core -delete",
+		"find . -exec rm {} ;",
+		"journalctl --vacuum-size=1M",
+		"dmesg -Tc",
+		"date 010100002030",
+		"hostname evil",
+		"sort -o /etc/passwd names",
+		"uniq in.txt out.txt",
+		"./ls",
+		"/tmp/ls",
+		"FOO=bar ls",
+		"sed -i s/a/b/ file",
+		"bash -c 'ls'",
+		"tee /tmp/x",
+		// Attached option values
+		"sort -o/etc/passwd /dev/null",
+		"date -s2020-01-01",
+		// Abbreviated long options
+		"sort --outp=/etc/passwd x",
+		"journalctl --vacuum-s=1",
+		// Clustered short options
+		"sysctl -qp",
+		"ip -b /tmp/cmds",
+		// Option values taken for the subcommand
+		"kubectl -n get delete pod foo",
+		"docker --host ps rm -f abc",
+		"systemctl -H get-default restart kubelet",
+		// Variable expansion
+		"find / -name x -de${x}lete",
+		"ip link ${x}delete dev eth0",
+		"find / -name x \"-de$x\"lete",
+		"find / -name x -$'\\x64'elete",
+		// Brace expansion
+		"find . -{delete,name} x",
+		"sort -{o,}/etc/x",
+		"find . -de{l..l}ete",
+		// Abbreviated ip verbs
+		"ip route a 10.0.0.0/8 via 1.2.3.4",
+		"ip link s eth0 down",
+		// Writing variants of otherwise allowed commands
+		"tree -o /etc/motd",
+		"tree -R -H . /srv",
+		"ss -K dst 1.2.3.4",
+		"ss --kill dst 1.2.3.4",
+		"sort --compress-program=sh /var/log/syslog",
+		"sort --compress=sh /var/log/syslog",
+		"sort -T /tmp /var/log/syslog",
+		"sort --temporary-directory=/tmp x",
+	}
+	for _, command := range denied {
+		t.Run("deny "+command, func(t *testing.T) {
+			if err := CheckReadOnly(command); err == nil {
+				t.Errorf("Expected %q to be rejected", command)
+			}
+		})
+	}
+}
+
+func TestCheckReadOnlyKubectl(t *testing.T) {
+	for _, args := range [][]string{
+		{"--context=teleport.example.com-prod", "get", "pods", "--namespace=kube-system", "-o", "json"},
+		{"--context=teleport.example.com-prod", "logs", "coredns-7db6d8ff4d-9xk2p", "--tail=100"},
+	} {
+		if err := CheckReadOnlyKubectl(args); err != nil {
+			t.Errorf("Expected %v to be read-only, got: %v", args, err)
+		}
+	}
+	for _, args := range [][]string{
+		{"--context=teleport.example.com-prod", "delete", "pod", "coredns-7db6d8ff4d-9xk2p"},
+		{"--context=teleport.example.com-prod", "exec", "coredns-7db6d8ff4d-9xk2p", "--", "ls"},
+		{"--context=teleport.example.com-prod", "-n", "get", "delete", "pod", "foo"},
+	} {
+		if err := CheckReadOnlyKubectl(args); err == nil {
+			t.Errorf("Expected %v to be rejected", args)
+		}
+	}
+}
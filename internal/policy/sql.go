@@ -0,0 +1,323 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sqlClientCommands are mysql client commands that act on the local machine
+// (running shell commands, reading or writing local files) when they start a line
+var sqlClientCommands = []string{"SYSTEM", "SOURCE", "TEE", "PAGER", "EDIT", "CONNECT", "DELIMITER", "SSL_SESSION_DATA_PRINT"}
+
+// sqlReadOnlyStatements are the statement types CheckReadOnlySQL accepts
+var sqlReadOnlyStatements = []string{"SELECT", "SHOW", "EXPLAIN"}
+
+// sqlSideEffectFunctions are built-in functions with side effects that a
+// read-only transaction does not prevent: signalling or reconfiguring the
+// server, taking locks, writing server files and running statements on
+// other connections
+var sqlSideEffectFunctions = []string{
+	// PostgreSQL server administration
+	"PG_TERMINATE_BACKEND", "PG_CANCEL_BACKEND", "PG_RELOAD_CONF", "PG_ROTATE_LOGFILE",
+	"PG_SWITCH_WAL", "PG_SWITCH_XLOG", "PG_CREATE_RESTORE_POINT", "PG_PROMOTE",
+	"PG_WAL_REPLAY_PAUSE", "PG_WAL_REPLAY_RESUME", "PG_XLOG_REPLAY_PAUSE", "PG_XLOG_REPLAY_RESUME",
+	"PG_START_BACKUP", "PG_STOP_BACKUP", "PG_BACKUP_START", "PG_BACKUP_STOP",
+	"PG_CREATE_PHYSICAL_REPLICATION_SLOT", "PG_CREATE_LOGICAL_REPLICATION_SLOT", "PG_DROP_REPLICATION_SLOT",
+	"PG_COPY_PHYSICAL_REPLICATION_SLOT", "PG_COPY_LOGICAL_REPLICATION_SLOT", "PG_REPLICATION_SLOT_ADVANCE",
+	"PG_LOGICAL_SLOT_GET_CHANGES", "PG_LOGICAL_SLOT_GET_BINARY_CHANGES", "PG_LOGICAL_EMIT_MESSAGE",
+	"PG_STAT_RESET", "PG_STAT_RESET_SHARED", "PG_STAT_RESET_SINGLE_TABLE_COUNTERS",
+	"PG_STAT_RESET_SINGLE_FUNCTION_COUNTERS", "PG_STAT_RESET_SLRU", "PG_STAT_STATEMENTS_RESET",
+	"PG_IMPORT_SYSTEM_COLLATIONS", "PG_NOTIFY", "SET_CONFIG",
+	// PostgreSQL advisory locks
+	"PG_ADVISORY_LOCK", "PG_ADVISORY_LOCK_SHARED", "PG_ADVISORY_XACT_LOCK", "PG_ADVISORY_XACT_LOCK_SHARED",
+	"PG_TRY_ADVISORY_LOCK", "PG_TRY_ADVISORY_LOCK_SHARED", "PG_TRY_ADVISORY_XACT_LOCK",
+	"PG_TRY_ADVISORY_XACT_LOCK_SHARED", "PG_ADVISORY_UNLOCK", "PG_ADVISORY_UNLOCK_SHARED", "PG_ADVISORY_UNLOCK_ALL",
+	// PostgreSQL server files and large objects
+	"PG_FILE_WRITE", "PG_FILE_RENAME", "PG_FILE_UNLINK", "PG_FILE_SYNC",
+	"LO_IMPORT", "LO_EXPORT", "LO_CREATE", "LO_CREAT", "LO_UNLINK", "LO_PUT", "LO_FROM_BYTEA", "LO_TRUNCATE",
+	// PostgreSQL sequences
+	"NEXTVAL", "SETVAL",
+	// dblink and postgres_fdw run statements on other connections
+	"DBLINK", "DBLINK_EXEC", "DBLINK_CONNECT", "DBLINK_CONNECT_U", "DBLINK_SEND_QUERY", "DBLINK_OPEN",
+	"POSTGRES_FDW_DISCONNECT", "POSTGRES_FDW_DISCONNECT_ALL",
+	// MySQL locks
+	"GET_LOCK", "RELEASE_LOCK", "RELEASE_ALL_LOCKS",
+}
+
+// explainOptions are the words that may appear between EXPLAIN and the explained statement
+var explainOptions = []string{
+	"ANALYZE", "ANALYSE", "VERBOSE", "EXTENDED", "PARTITIONS", "FORMAT", "=", "TRADITIONAL", "JSON", "TREE",
+}
+
+// sqlStatement is a SQL statement reduced to its unquoted words and symbols
+type sqlStatement struct {
+	// tokens are the upper-cased keywords and identifiers, and the symbols ( ) =.
+	// Quoted strings are ? and quoted identifiers are ".
+	tokens []string
+}
+
+// CheckSQLStatement returns an error unless statement is exactly one SQL
+// statement that can be handed to psql -c or mysql -e without side effects on
+// the machine running the client: backslash meta-commands, mysql client
+// commands and executable comments are rejected.
+func CheckSQLStatement(statement string) error {
+	for _, mysql := range []bool{false, true} {
+		if _, err := scanSQL(statement, mysql); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckReadOnlySQL returns nil if statement is a single SELECT, SHOW or
+// EXPLAIN statement that does not write, and an error describing the
+// violation otherwise. EXPLAIN is only accepted for SELECT statements, since
+// EXPLAIN ANALYZE executes the explained statement.
+//
+// Calls of the built-in functions in sqlSideEffectFunctions and of functions
+// named by quoted identifiers are rejected. User-defined functions are not
+// known and may still have side effects; the read-only session the statement
+// runs in is what stops them from writing.
+//
+// PostgreSQL and MySQL disagree on comments and string escapes, so the
+// statement is checked under both dialects; text one of them treats as a
+// comment or string cannot hide a second statement from the other.
+func CheckReadOnlySQL(statement string) error {
+	for _, mysql := range []bool{false, true} {
+		stmt, err := scanSQL(statement, mysql)
+		if err != nil {
+			return err
+		}
+		if err := checkReadOnlyTokens(stmt.tokens); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkReadOnlyTokens applies the read-only statement rules to a scanned statement
+func checkReadOnlyTokens(tokens []string) error {
+	if tokens[0] == "EXPLAIN" {
+		tokens = skipExplainOptions(tokens[1:])
+		if len(tokens) == 0 || tokens[0] != "SELECT" {
+			return fmt.Errorf("EXPLAIN is only allowed for SELECT statements")
+		}
+	} else if !contains(sqlReadOnlyStatements, tokens[0]) {
+		return fmt.Errorf("%s statements are not allowed (allowed: %s)", tokens[0], strings.Join(sqlReadOnlyStatements, ", "))
+	}
+
+	if contains(tokens, "INTO") {
+		return fmt.Errorf("SELECT ... INTO is not allowed")
+	}
+
+	for i := 0; i+1 < len(tokens); i++ {
+		if tokens[i+1] != "(" {
+			continue
+		}
+		switch {
+		case tokens[i] == `"`:
+			return fmt.Errorf("calling functions by quoted name is not allowed")
+		case contains(sqlSideEffectFunctions, tokens[i]):
+			return fmt.Errorf("function %s is not allowed; it has side effects", strings.ToLower(tokens[i]))
+		}
+	}
+	return nil
+}
+
+// skipExplainOptions returns tokens without the leading EXPLAIN options
+func skipExplainOptions(tokens []string) []string {
+	for len(tokens) > 0 {
+		switch {
+		case tokens[0] == "(":
+			// Parenthesized option list, e.g. EXPLAIN (ANALYZE, COSTS OFF)
+			end := indexOf(tokens, ")")
+			if end < 0 {
+				return nil
+			}
+			if contains(tokens[:end], "SELECT") {
+				return tokens
+			}
+			tokens = tokens[end+1:]
+		case contains(explainOptions, tokens[0]):
+			tokens = tokens[1:]
+		default:
+			return tokens
+		}
+	}
+	return tokens
+}
+
+// scanSQL splits statement into tokens, skipping comments and quoted text
+// according to the PostgreSQL or MySQL lexical rules. It fails unless
+// statement contains exactly one statement and no client commands.
+func scanSQL(statement string, mysql bool) (*sqlStatement, error) {
+	stmt := &sqlStatement{}
+	ended := false
+	lineStart := true
+
+	for i := 0; i < len(statement); {
+		c := statement[i]
+		switch {
+		case c == '\n':
+			lineStart = true
+			i++
+			continue
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+			continue
+		case isLineComment(statement[i:], mysql):
+			end := strings.IndexByte(statement[i:], '\n')
+			if end < 0 {
+				return finishSQL(stmt)
+			}
+			i += end
+			continue
+		case strings.HasPrefix(statement[i:], "/*!"), strings.HasPrefix(statement[i:], "/*+"):
+			return nil, fmt.Errorf("executable comments are not allowed")
+		case strings.HasPrefix(statement[i:], "/*"):
+			end, err := endOfBlockComment(statement, i, !mysql)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+			continue
+		}
+
+		if ended {
+			return nil, fmt.Errorf("only a single statement is allowed")
+		}
+
+		switch {
+		case c == ';':
+			ended = true
+			i++
+		case c == '\\':
+			return nil, fmt.Errorf("client meta-commands are not allowed")
+		case c == '\'' || c == '"' || c == '`':
+			end, err := endOfQuoted(statement, i, mysql)
+			if err != nil {
+				return nil, err
+			}
+			if c == '\'' {
+				stmt.tokens = append(stmt.tokens, "?")
+			} else {
+				stmt.tokens = append(stmt.tokens, `"`)
+			}
+			i = end
+		case !mysql && c == '$' && dollarQuoteTag(statement[i:]) != "":
+			tag := dollarQuoteTag(statement[i:])
+			end := strings.Index(statement[i+len(tag):], tag)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated dollar-quoted string")
+			}
+			stmt.tokens = append(stmt.tokens, "?")
+			i += len(tag) + end + len(tag)
+		case isSQLWordChar(c):
+			start := i
+			for i < len(statement) && isSQLWordChar(statement[i]) {
+				i++
+			}
+			word := strings.ToUpper(statement[start:i])
+			if lineStart && contains(sqlClientCommands, word) {
+				return nil, fmt.Errorf("client command %s is not allowed", word)
+			}
+			stmt.tokens = append(stmt.tokens, word)
+		default:
+			if c == '(' || c == ')' || c == '=' {
+				stmt.tokens = append(stmt.tokens, string(c))
+			}
+			i++
+		}
+		lineStart = false
+	}
+
+	return finishSQL(stmt)
+}
+
+// isLineComment reports whether s starts with a comment that runs to the end
+// of the line. MySQL requires whitespace after -- and also accepts #.
+func isLineComment(s string, mysql bool) bool {
+	if !mysql {
+		return strings.HasPrefix(s, "--")
+	}
+	if s[0] == '#' {
+		return true
+	}
+	return strings.HasPrefix(s, "--") && (len(s) == 2 || strings.IndexByte(" \t\r\n", s[2]) >= 0)
+}
+
+// endOfBlockComment returns the index after the /* */ comment starting at
+// start. PostgreSQL block comments nest, MySQL ones do not.
+func endOfBlockComment(s string, start int, nested bool) (int, error) {
+	depth := 0
+	for i := start; i+1 < len(s); i++ {
+		switch {
+		case s[i] == '/' && s[i+1] == '*':
+			if depth == 0 || nested {
+				depth++
+			}
+			i++
+		case s[i] == '*' && s[i+1] == '/':
+			depth--
+			i++
+			if depth == 0 {
+				return i + 1, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unterminated comment")
+}
+
+func finishSQL(stmt *sqlStatement) (*sqlStatement, error) {
+	if len(stmt.tokens) == 0 {
+		return nil, fmt.Errorf("empty statement")
+	}
+	return stmt, nil
+}
+
+// endOfQuoted returns the index after the quoted string starting at start.
+// Doubled quotes are escapes; backslash escapes only apply to MySQL strings.
+func endOfQuoted(s string, start int, mysql bool) (int, error) {
+	quote := s[start]
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if mysql && quote != '`' {
+				i++
+			}
+		case quote:
+			if i+1 < len(s) && s[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated quoted string")
+}
+
+// dollarQuoteTag returns the PostgreSQL dollar-quote opening tag ($$ or $tag$) at the start of s
+func dollarQuoteTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		if s[i] == '$' {
+			return s[:i+1]
+		}
+		if !isSQLWordChar(s[i]) || (s[i] >= '0' && s[i] <= '9' && i == 1) {
+			return ""
+		}
+	}
+	return ""
+}
+
+func isSQLWordChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,104 @@
+package policy
+
+import "testing"
+
+func TestCheckReadOnlySQL(t *testing.T) {
+	allowed := []string{
+		"SELECT 1",
+		"select * from orders where status = 'open';",
+		"SELECT count(*) FROM pg_stat_activity -- how busy?",
+		"/* recent */ SELECT id FROM orders ORDER BY created_at DESC LIMIT 10",
+		"SELECT 'a;b', \"weird;column\" FROM t",
+		"SELECT 'it''s'",
+		"SHOW max_connections",
+		"SHOW TABLES",
+		"EXPLAIN SELECT * FROM orders",
+		"EXPLAIN (ANALYZE, BUFFERS) SELECT * FROM orders",
+		"EXPLAIN FORMAT=JSON SELECT * FROM orders",
+		"explain analyze select 1",
+		"SELECT 1\n;\n-- done",
+		"SELECT pid, state FROM pg_stat_activity WHERE query LIKE 'pg_terminate_backend(%'",
+		"SELECT \"nextval\" FROM t",
+		"SELECT count(\"id\") FROM t",
+	}
+	for _, statement := range allowed {
+		t.Run("allow "+statement, func(t *testing.T) {
+			if err := CheckReadOnlySQL(statement); err != nil {
+				t.Errorf("Expected %q to be read-only, got: %v", statement, err)
+			}
+		})
+	}
+
+	denied := []string{
+		"",
+		"-- only a comment",
+		"DELETE FROM orders",
+		"update orders set status = 'closed'",
+		"INSERT INTO t VALUES (1)",
+		"DROP TABLE orders",
+		"WITH d AS (DELETE FROM orders RETURNING *) SELECT * FROM d",
+		"SELECT 1; DELETE FROM orders",
+		"SELECT * INTO backup FROM orders",
+		"SELECT * FROM orders INTO OUTFILE '/tmp/orders'",
+		"EXPLAIN ANALYZE DELETE FROM orders",
+		"EXPLAIN (ANALYZE) UPDATE orders SET x = 1",
+		"SET default_transaction_read_only = off",
+		"\\! rm -rf ~",
+		"SELECT 1 \\g",
+		"SELECT 1\nsystem rm -rf ~",
+		"SELECT 1 /*! , sleep(10) */",
+		"SELECT 'unterminated",
+		"SELECT 1 /* unterminated",
+		"SELECT $$unterminated",
+		// Dialect differences must not hide a second statement
+		"SELECT 'a\\' ; DELETE FROM t; -- '",
+		"SELECT 1 # 1; DELETE FROM t",
+		"SELECT 1 --1; DELETE FROM t",
+		"SELECT 1 /* /* */ ; DELETE FROM t; */",
+		// Built-in functions with side effects
+		"SELECT pg_terminate_backend(12345)",
+		"SELECT pg_catalog.pg_cancel_backend(pid) FROM pg_stat_activity",
+		"SELECT dblink_exec('dbname=prod', 'DELETE FROM orders')",
+		"SELECT * FROM dblink('dbname=prod', 'SELECT 1') AS t(x int)",
+		"SELECT set_config('default_transaction_read_only', 'off', false)",
+		"SELECT PG_ADVISORY_LOCK (1)",
+		"SELECT lo_export(16385, '/tmp/x')",
+		"SELECT GET_LOCK('maintenance', 10)",
+		"EXPLAIN SELECT pg_reload_conf()",
+		"SELECT \"pg_terminate_backend\"(12345)",
+		"SELECT `release_all_locks`()",
+		"SELECT 1\nssl_session_data_print /tmp/session",
+	}
+	for _, statement := range denied {
+		t.Run("deny "+statement, func(t *testing.T) {
+			if err := CheckReadOnlySQL(statement); err == nil {
+				t.Errorf("Expected %q to be rejected", statement)
+			}
+		})
+	}
+}
+
+func TestCheckSQLStatement(t *testing.T) {
+	allowed := []string{
+		"DELETE FROM sessions WHERE expires_at < now()",
+		"UPDATE t SET note = 'it''s'",
+		"SELECT 1;",
+	}
+	for _, statement := range allowed {
+		if err := CheckSQLStatement(statement); err != nil {
+			t.Errorf("Expected %q to be accepted, got: %v", statement, err)
+		}
+	}
+
+	denied := []string{
+		"SELECT 1; SELECT 2",
+		"\\copy t to '/tmp/t.csv'",
+		"source /tmp/evil.sql",
+		"SELECT 1 /*!50000 , 2 */",
+	}
+	for _, statement := range denied {
+		if err := CheckSQLStatement(statement); err == nil {
+			t.Errorf("Expected %q to be rejected", statement)
+		}
+	}
+}
@@ -0,0 +1,376 @@
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SSH policy actions
+const (
+	ActionAllow = "allow"
+	ActionDeny  = "deny"
+)
+
+// SSHPolicy decides which commands teleport_ssh may run where. Rules are
+// evaluated in order and the first matching rule decides; if none matches,
+// Default applies.
+type SSHPolicy struct {
+	// Default is the action when no rule matches, allow or deny; empty means deny
+	Default string    `json:"default,omitempty"`
+	Rules   []SSHRule `json:"rules"`
+}
+
+// SSHRule allows or denies the commands matching all of its conditions. A
+// condition that is not set matches everything, so a rule without
+// conditions matches every command.
+type SSHRule struct {
+	Name string `json:"name,omitempty"`
+	// Action is allow or deny
+	Action string `json:"action"`
+	// Reason is reported when the rule denies a command
+	Reason string `json:"reason,omitempty"`
+
+	// Command is a regular expression matched against the whole command line
+	Command string `json:"command,omitempty"`
+	// Programs are patterns for the programs the command line runs, without
+	// directory, env assignments or sudo. An allow rule requires every
+	// program to match, a deny rule any of them.
+	Programs []string `json:"programs,omitempty"`
+	// Hosts are patterns for the node's hostname
+	Hosts []string `json:"hosts,omitempty"`
+	// Labels are patterns for node labels, all of which must match
+	Labels map[string]string `json:"labels,omitempty"`
+	// Logins are patterns for the login on the node
+	Logins []string `json:"logins,omitempty"`
+	// Clusters are patterns for the Teleport cluster
+	Clusters []string `json:"clusters,omitempty"`
+
+	command *regexp.Regexp
+}
+
+// SSHTarget describes a command teleport_ssh is about to run on one node.
+// Labels is nil and Login or Cluster are empty if they are unknown.
+type SSHTarget struct {
+	Command string            `json:"command"`
+	Host    string            `json:"host"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Login   string            `json:"login,omitempty"`
+	Cluster string            `json:"cluster,omitempty"`
+}
+
+// SSHDecision is the outcome of evaluating an SSHPolicy
+type SSHDecision struct {
+	Allowed bool `json:"allowed"`
+	// Rule names the deciding rule; it is empty if the default applied
+	Rule   string `json:"rule,omitempty"`
+	Reason string `json:"reason,omitempty"`
+	// Trace explains for every rule up to the deciding one why it did or did
+	// not match
+	Trace []SSHRuleTrace `json:"trace,omitempty"`
+}
+
+// SSHRuleTrace is the evaluation of a single rule
+type SSHRuleTrace struct {
+	Rule    string `json:"rule"`
+	Action  string `json:"action"`
+	Matched bool   `json:"matched"`
+	// Detail names the condition that did not match, or the unknown values a
+	// match depended on
+	Detail string `json:"detail,omitempty"`
+}
+
+// String describes the decision in one line
+func (d SSHDecision) String() string {
+	verdict := "denied"
+	if d.Allowed {
+		verdict = "allowed"
+	}
+	var text string
+	if d.Rule == "" {
+		text = fmt.Sprintf("%s by the default action of the SSH policy", verdict)
+	} else {
+		text = fmt.Sprintf("%s by SSH policy rule %q", verdict, d.Rule)
+	}
+	if d.Reason != "" {
+		text += ": " + d.Reason
+	}
+	return text
+}
+
+// LoadSSHPolicy reads an SSH policy from a JSON file
+func LoadSSHPolicy(filename string) (*SSHPolicy, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read SSH policy: %w", err)
+	}
+	p, err := ParseSSHPolicy(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SSH policy %s: %w", filename, err)
+	}
+	return p, nil
+}
+
+// ParseSSHPolicy parses and validates a JSON SSH policy
+func ParseSSHPolicy(data []byte) (*SSHPolicy, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var p SSHPolicy
+	if err := decoder.Decode(&p); err != nil {
+		return nil, err
+	}
+
+	switch p.Default {
+	case "":
+		p.Default = ActionDeny
+	case ActionAllow, ActionDeny:
+	default:
+		return nil, fmt.Errorf("default must be %s or %s, got %q", ActionAllow, ActionDeny, p.Default)
+	}
+
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		if rule.Action != ActionAllow && rule.Action != ActionDeny {
+			return nil, fmt.Errorf("%s: action must be %s or %s, got %q", rule.Name, ActionAllow, ActionDeny, rule.Action)
+		}
+		if rule.Command != "" {
+			command, err := regexp.Compile(rule.Command)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid command pattern: %w", rule.Name, err)
+			}
+			rule.command = command
+		}
+
+		patterns := append(append(append(append([]string{}, rule.Programs...), rule.Hosts...), rule.Logins...), rule.Clusters...)
+		for _, value := range rule.Labels {
+			patterns = append(patterns, value)
+		}
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("%s: invalid pattern %q", rule.Name, pattern)
+			}
+		}
+	}
+	return &p, nil
+}
+
+// NeedsLabels reports whether any rule matches on node labels
+func (p *SSHPolicy) NeedsLabels() bool {
+	for _, rule := range p.Rules {
+		if len(rule.Labels) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// NeedsCluster reports whether any rule matches on the cluster
+func (p *SSHPolicy) NeedsCluster() bool {
+	for _, rule := range p.Rules {
+		if len(rule.Clusters) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Evaluate decides whether the policy allows the target
+func (p *SSHPolicy) Evaluate(target SSHTarget) SSHDecision {
+	programs, opaque := commandPrograms(target.Command)
+
+	var decision SSHDecision
+	for _, rule := range p.Rules {
+		matched, detail := rule.match(target, programs, opaque)
+		decision.Trace = append(decision.Trace, SSHRuleTrace{Rule: rule.Name, Action: rule.Action, Matched: matched, Detail: detail})
+		if matched {
+			decision.Allowed = rule.Action == ActionAllow
+			decision.Rule = rule.Name
+			decision.Reason = rule.Reason
+			return decision
+		}
+	}
+	decision.Allowed = p.Default == ActionAllow
+	return decision
+}
+
+// condition outcomes; an unknown value fails closed, matching deny rules
+// but not allow rules
+const (
+	conditionMatch = iota
+	conditionMismatch
+	conditionUnknown
+)
+
+// match evaluates the conditions of the rule, returning whether it matches
+// and a description of the condition that decided it
+func (r *SSHRule) match(target SSHTarget, programs []string, opaque bool) (bool, string) {
+	deny := r.Action == ActionDeny
+	var unknown []string
+
+	check := func(outcome int, description string) bool {
+		switch outcome {
+		case conditionMismatch:
+			return false
+		case conditionUnknown:
+			unknown = append(unknown, description)
+		}
+		return true
+	}
+
+	if r.command != nil && !check(boolOutcome(r.command.MatchString(target.Command)), "command") {
+		return false, fmt.Sprintf("command does not match %q", r.Command)
+	}
+	if len(r.Programs) > 0 && !check(r.matchPrograms(programs, opaque), "programs of the command line") {
+		if deny {
+			return false, fmt.Sprintf("no program in %v matches %v", programs, r.Programs)
+		}
+		return false, fmt.Sprintf("not every program in %v matches %v", programs, r.Programs)
+	}
+	if len(r.Hosts) > 0 && !check(matchValue(r.Hosts, target.Host), "host") {
+		return false, fmt.Sprintf("host %q does not match %v", target.Host, r.Hosts)
+	}
+	if len(r.Labels) > 0 && !check(matchLabels(r.Labels, target.Labels), "node labels") {
+		return false, fmt.Sprintf("labels %v do not match %v", FormatLabels(target.Labels), FormatLabels(r.Labels))
+	}
+	if len(r.Logins) > 0 && !check(matchValue(r.Logins, target.Login), "login") {
+		return false, fmt.Sprintf("login %q does not match %v", target.Login, r.Logins)
+	}
+	if len(r.Clusters) > 0 && !check(matchValue(r.Clusters, target.Cluster), "cluster") {
+		return false, fmt.Sprintf("cluster %q does not match %v", target.Cluster, r.Clusters)
+	}
+
+	if len(unknown) > 0 {
+		detail := "unknown " + strings.Join(unknown, ", ")
+		if deny {
+			return true, detail + " (denied to be safe)"
+		}
+		return false, detail + " (not allowed to be safe)"
+	}
+	return true, ""
+}
+
+// matchPrograms matches the programs of a command line; opaque is set when
+// the command line may run programs that are not in the list
+func (r *SSHRule) matchPrograms(programs []string, opaque bool) int {
+	if r.Action == ActionDeny {
+		for _, program := range programs {
+			if matchAny(r.Programs, program) {
+				return conditionMatch
+			}
+		}
+	} else {
+		for _, program := range programs {
+			if !matchAny(r.Programs, program) {
+				return conditionMismatch
+			}
+		}
+	}
+	if opaque {
+		return conditionUnknown
+	}
+	return boolOutcome(r.Action != ActionDeny)
+}
+
+// commandPrograms returns the programs a command line runs. opaque is set if
+// the command line cannot be parsed, contains substitutions or subshells, or
+// names a program through a variable ($x), any of which may run other
+// programs.
+func commandPrograms(command string) ([]string, bool) {
+	line, err := ParseCommandLine(command)
+	if err != nil {
+		return nil, true
+	}
+
+	var programs []string
+	opaque := line.Substitution || line.Subshell
+	for _, c := range line.Commands {
+		argv := c.Argv
+		// Skip environment assignments and sudo with its options
+		for len(argv) > 0 && isAssignment(argv[0]) {
+			argv = argv[1:]
+		}
+		if len(argv) > 0 && path.Base(argv[0]) == "sudo" {
+			argv = argv[1:]
+			for len(argv) > 0 && strings.HasPrefix(argv[0], "-") {
+				argv = argv[1:]
+			}
+		}
+		if len(argv) > 0 {
+			if strings.Contains(argv[0], "$") {
+				opaque = true
+			}
+			programs = append(programs, path.Base(argv[0]))
+		}
+	}
+	return programs, opaque
+}
+
+// isAssignment reports whether a word is a NAME=value environment assignment
+func isAssignment(word string) bool {
+	name, _, ok := strings.Cut(word, "=")
+	return ok && name != "" && !strings.ContainsAny(name, "/-.")
+}
+
+// matchValue matches a possibly unknown (empty) value against patterns
+func matchValue(patterns []string, value string) int {
+	if value == "" {
+		return conditionUnknown
+	}
+	return boolOutcome(matchAny(patterns, value))
+}
+
+// matchLabels matches possibly unknown (nil) node labels against label patterns
+func matchLabels(patterns map[string]string, labels map[string]string) int {
+	if labels == nil {
+		return conditionUnknown
+	}
+	for key, pattern := range patterns {
+		value, ok := labels[key]
+		if !ok {
+			return conditionMismatch
+		}
+		if matched, _ := path.Match(pattern, value); !matched {
+			return conditionMismatch
+		}
+	}
+	return conditionMatch
+}
+
+// matchAny reports whether value matches one of the patterns
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, value); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// boolOutcome converts a match result into a condition outcome
+func boolOutcome(matched bool) int {
+	if matched {
+		return conditionMatch
+	}
+	return conditionMismatch
+}
+
+// FormatLabels renders labels as sorted key=value pairs, or (unknown) for nil
+func FormatLabels(labels map[string]string) string {
+	if labels == nil {
+		return "(unknown)"
+	}
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return "{" + strings.Join(pairs, ",") + "}"
+}
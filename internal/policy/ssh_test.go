@@ -0,0 +1,158 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testSSHPolicy = `{
+  "default": "deny",
+  "rules": [
+    {"name": "no-root-on-prod", "action": "deny", "labels": {"env": "prod"}, "logins": ["root"], "reason": "use your own login on production"},
+    {"name": "no-rm", "action": "deny", "programs": ["rm", "shred"]},
+    {"name": "no-reboot", "action": "deny", "command": "\\b(reboot|shutdown)\\b"},
+    {"name": "inspect", "action": "allow", "programs": ["df", "journalctl", "systemctl", "tail", "grep"]},
+    {"name": "staging", "action": "allow", "hosts": ["staging-*"], "clusters": ["staging.example.com"]}
+  ]
+}`
+
+func TestSSHPolicyEvaluate(t *testing.T) {
+	p, err := ParseSSHPolicy([]byte(testSSHPolicy))
+	if err != nil {
+		t.Fatalf("ParseSSHPolicy() failed: %v", err)
+	}
+
+	prod := map[string]string{"env": "prod", "role": "worker"}
+	tests := []struct {
+		name    string
+		target  SSHTarget
+		allowed bool
+		rule    string
+	}{
+		{
+			name:    "read-only pipeline",
+			target:  SSHTarget{Command: "journalctl -u kubelet | tail -n 50", Host: "prod-1", Labels: prod, Login: "alice"},
+			allowed: true,
+			rule:    "inspect",
+		},
+		{
+			name:    "root on prod",
+			target:  SSHTarget{Command: "df -h", Host: "prod-1", Labels: prod, Login: "root"},
+			allowed: false,
+			rule:    "no-root-on-prod",
+		},
+		{
+			name:    "root with unknown labels",
+			target:  SSHTarget{Command: "df -h", Host: "prod-1", Login: "root"},
+			allowed: false,
+			rule:    "no-root-on-prod",
+		},
+		{
+			name:    "rm behind sudo and a path",
+			target:  SSHTarget{Command: "df -h && sudo -n /usr/bin/rm -rf /var/lib/etcd", Host: "prod-1", Labels: prod, Login: "alice"},
+			allowed: false,
+			rule:    "no-rm",
+		},
+		{
+			name:    "reboot",
+			target:  SSHTarget{Command: "systemctl status kubelet; reboot", Host: "prod-1", Labels: prod, Login: "alice"},
+			allowed: false,
+			rule:    "no-reboot",
+		},
+		{
+			name:    "program outside the allowlist",
+			target:  SSHTarget{Command: "df -h; curl http://example.com", Host: "prod-1", Labels: prod, Login: "alice"},
+			allowed: false,
+		},
+		{
+			name:    "substitution may run a denied program",
+			target:  SSHTarget{Command: "df -h $(cat /tmp/x)", Host: "prod-1", Labels: prod, Login: "alice"},
+			allowed: false,
+			rule:    "no-rm",
+		},
+		{
+			name:    "program named by a variable",
+			target:  SSHTarget{Command: "x=rm; $x -rf /tmp/x", Host: "prod-1", Labels: prod, Login: "alice"},
+			allowed: false,
+			rule:    "no-rm",
+		},
+		{
+			name:    "staging host",
+			target:  SSHTarget{Command: "curl http://example.com", Host: "staging-3", Labels: map[string]string{}, Login: "alice", Cluster: "staging.example.com"},
+			allowed: true,
+			rule:    "staging",
+		},
+		{
+			name:    "staging host with unknown cluster",
+			target:  SSHTarget{Command: "curl http://example.com", Host: "staging-3", Labels: map[string]string{}, Login: "alice"},
+			allowed: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := p.Evaluate(tt.target)
+			if decision.Allowed != tt.allowed || decision.Rule != tt.rule {
+				t.Errorf("Got %s, want allowed=%v rule=%q; trace: %+v", decision, tt.allowed, tt.rule, decision.Trace)
+			}
+		})
+	}
+
+	decision := p.Evaluate(SSHTarget{Command: "df -h", Host: "prod-1", Labels: prod, Login: "root"})
+	if decision.String() != `denied by SSH policy rule "no-root-on-prod": use your own login on production` {
+		t.Errorf("Unexpected description: %s", decision)
+	}
+	decision = p.Evaluate(SSHTarget{Command: "uptime", Host: "prod-1", Labels: prod, Login: "alice"})
+	if len(decision.Trace) != 5 || decision.Trace[3].Detail != "not every program in [uptime] matches [df journalctl systemctl tail grep]" {
+		t.Errorf("Unexpected trace: %+v", decision.Trace)
+	}
+}
+
+func TestParseSSHPolicyErrors(t *testing.T) {
+	for _, data := range []string{
+		`{"default": "maybe"}`,
+		`{"rules": [{"action": "permit"}]}`,
+		`{"rules": [{"action": "deny", "command": "("}]}`,
+		`{"rules": [{"action": "deny", "hosts": ["["]}]}`,
+		`{"rules": [{"action": "deny", "host": "prod-1"}]}`,
+		`not json`,
+	} {
+		if _, err := ParseSSHPolicy([]byte(data)); err == nil {
+			t.Errorf("Expected %s to be rejected", data)
+		}
+	}
+}
+
+func TestLoadSSHPolicy(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "ssh-policy.json")
+	if err := os.WriteFile(filename, []byte(`{"rules": [{"action": "allow", "hosts": ["*"]}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := LoadSSHPolicy(filename)
+	if err != nil {
+		t.Fatalf("LoadSSHPolicy() failed: %v", err)
+	}
+	if p.Default != ActionDeny || p.Rules[0].Name != "rule 1" {
+		t.Errorf("Unexpected policy: %+v", p)
+	}
+	if _, err := LoadSSHPolicy(filename + ".missing"); err == nil {
+		t.Error("Expected a missing file to fail")
+	}
+}
+
+func TestSSHPolicyProgramVariable(t *testing.T) {
+	p, err := ParseSSHPolicy([]byte(`{"default": "allow", "rules": [{"name": "no-rm", "action": "deny", "programs": ["rm"]}]}`))
+	if err != nil {
+		t.Fatalf("ParseSSHPolicy() failed: %v", err)
+	}
+	for _, command := range []string{"x=rm; $x -rf /tmp/x", "sudo ${x} -rf /tmp/x", `"$x" -rf /tmp/x`} {
+		if decision := p.Evaluate(SSHTarget{Command: command, Host: "prod-1", Login: "alice"}); decision.Allowed {
+			t.Errorf("Expected %q to be denied: %+v", command, decision.Trace)
+		}
+	}
+	if decision := p.Evaluate(SSHTarget{Command: "ls $HOME", Host: "prod-1", Login: "alice"}); !decision.Allowed {
+		t.Errorf("Expected an expanded argument to be allowed: %+v", decision.Trace)
+	}
+}
@@ -2,10 +2,24 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"time"
+
+	"github.com/giantswarm/mcp-teleport/internal/policy"
+	"github.com/giantswarm/mcp-teleport/internal/teleport"
 )
 
+// DefaultExpiryWarning is how close to expiry certificates are flagged by teleport_status
+const DefaultExpiryWarning = time.Hour
+
+// DefaultSSHConcurrency is how many nodes a label-selector teleport_ssh call runs on at once
+const DefaultSSHConcurrency = 10
+
+// ErrNonDestructive is returned when a mutating operation is attempted in non-destructive mode
+var ErrNonDestructive = errors.New("operation not permitted in non-destructive mode")
+
 // Logger interface for structured logging
 type Logger interface {
 	Debug(msg string, args ...interface{})
@@ -25,6 +39,51 @@ type ServerContext struct {
 	dryRun             bool
 	debugMode          bool
 	logger             Logger
+	runner             teleport.Runner
+
+	// Timeouts for tsh commands
+	defaultTimeout time.Duration
+	toolTimeouts   map[string]time.Duration
+	maxTimeout     time.Duration
+
+	// expiryWarning is how close to expiry certificates are flagged
+	expiryWarning time.Duration
+
+	// sshConcurrency is how many nodes a label-selector SSH command runs on at once
+	sshConcurrency int
+
+	// jobTimeout is how long background jobs may run
+	jobTimeout time.Duration
+
+	// sshPolicy decides which commands teleport_ssh may run where; nil allows all
+	sshPolicy *policy.SSHPolicy
+
+	// scpPolicy restricts the paths teleport_scp transfers; nil uses the default policy
+	scpPolicy *policy.SCPPolicy
+
+	// kubeconfigIsolation keeps kubeconfigs in kubeconfigPrivateDir, which is
+	// created in kubeconfigBaseDir on first use and removed on shutdown
+	kubeconfigIsolation  bool
+	kubeconfigBaseDir    string
+	kubeconfigPrivateDir string
+	kubeconfigShutdown   bool
+
+	// originalKubeContexts holds the current context of each kubeconfig
+	// before the server first logged in with it
+	originalKubeContexts map[string]string
+
+	// Background jobs started with background=true
+	jobs *JobManager
+
+	// outputLimit is how many bytes of command output a tool result contains
+	outputLimit int
+	// captureLimit is how many bytes of output are captured per tsh command
+	captureLimit int
+	// Full output of truncated results, for teleport_output_read
+	outputs outputStore
+
+	// In-flight tool calls that can be cancelled by the client
+	requests requestTracker
 
 	// Shared resources would go here (e.g., connection pools, caches)
 }
@@ -60,13 +119,109 @@ func WithLogger(logger Logger) ServerOption {
 	}
 }
 
+// WithRunner sets the runner used to execute tsh commands
+func WithRunner(runner teleport.Runner) ServerOption {
+	return func(sc *ServerContext) {
+		sc.runner = runner
+	}
+}
+
+// WithDefaultTimeout sets how long tsh commands may run unless a tool timeout applies
+func WithDefaultTimeout(timeout time.Duration) ServerOption {
+	return func(sc *ServerContext) {
+		sc.defaultTimeout = timeout
+	}
+}
+
+// WithToolTimeouts sets per-tool timeouts keyed by tool name, overriding the default timeout
+func WithToolTimeouts(timeouts map[string]time.Duration) ServerOption {
+	return func(sc *ServerContext) {
+		sc.toolTimeouts = timeouts
+	}
+}
+
+// WithMaxTimeout caps the timeout a client may request with the timeoutSeconds argument
+func WithMaxTimeout(timeout time.Duration) ServerOption {
+	return func(sc *ServerContext) {
+		sc.maxTimeout = timeout
+	}
+}
+
+// WithExpiryWarning sets how close to expiry certificates are flagged by
+// teleport_status; zero disables the warning
+func WithExpiryWarning(window time.Duration) ServerOption {
+	return func(sc *ServerContext) {
+		sc.expiryWarning = window
+	}
+}
+
+// WithSSHConcurrency sets how many nodes a label-selector teleport_ssh call
+// runs on at once; zero or negative keeps DefaultSSHConcurrency
+func WithSSHConcurrency(limit int) ServerOption {
+	return func(sc *ServerContext) {
+		if limit > 0 {
+			sc.sshConcurrency = limit
+		}
+	}
+}
+
+// WithJobTimeout sets how long background jobs may run unless the call sets
+// timeoutSeconds; zero or negative keeps DefaultJobTimeout
+func WithJobTimeout(timeout time.Duration) ServerOption {
+	return func(sc *ServerContext) {
+		if timeout > 0 {
+			sc.jobTimeout = timeout
+		}
+	}
+}
+
+// WithOutputLimit sets how many bytes of command output a tool result
+// contains before it is truncated; zero or negative keeps DefaultOutputLimit
+func WithOutputLimit(limit int) ServerOption {
+	return func(sc *ServerContext) {
+		if limit > 0 {
+			sc.outputLimit = limit
+		}
+	}
+}
+
+// WithCaptureLimit sets how many bytes of output are captured per tsh
+// command; zero or negative keeps DefaultCaptureLimit
+func WithCaptureLimit(limit int) ServerOption {
+	return func(sc *ServerContext) {
+		if limit > 0 {
+			sc.captureLimit = limit
+		}
+	}
+}
+
+// WithSSHPolicy sets the rules teleport_ssh commands are evaluated against
+// before they run; nil disables the policy
+func WithSSHPolicy(sshPolicy *policy.SSHPolicy) ServerOption {
+	return func(sc *ServerContext) {
+		sc.sshPolicy = sshPolicy
+	}
+}
+
+// WithSCPPolicy sets the local and remote paths teleport_scp may transfer
+// from or to, replacing policy.DefaultSCPPolicy
+func WithSCPPolicy(scpPolicy policy.SCPPolicy) ServerOption {
+	return func(sc *ServerContext) {
+		sc.scpPolicy = &scpPolicy
+	}
+}
+
 // NewServerContext creates a new server context with the given options
 func NewServerContext(ctx context.Context, opts ...ServerOption) (*ServerContext, error) {
 	serverCtx, cancel := context.WithCancel(ctx)
 
 	sc := &ServerContext{
-		ctx:    serverCtx,
-		cancel: cancel,
+		ctx:           serverCtx,
+		cancel:        cancel,
+		expiryWarning: DefaultExpiryWarning,
+		jobs:          newJobManager(serverCtx),
+		outputLimit:   DefaultOutputLimit,
+		captureLimit:  DefaultCaptureLimit,
 	}
 
 	// Apply options
@@ -96,6 +251,55 @@ func (sc *ServerContext) IsNonDestructiveMode() bool {
 	return sc.nonDestructiveMode
 }
 
+// CheckMutation returns an error wrapping ErrNonDestructive if the named
+// mutating operation is not allowed because non-destructive mode is enabled
+func (sc *ServerContext) CheckMutation(operation string) error {
+	if sc.IsNonDestructiveMode() {
+		return fmt.Errorf("%s: %w (restart the server with --non-destructive=false to allow it)", operation, ErrNonDestructive)
+	}
+	return nil
+}
+
+// ExpiryWarning returns how close to expiry certificates are flagged; zero disables the warning
+func (sc *ServerContext) ExpiryWarning() time.Duration {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+	return sc.expiryWarning
+}
+
+// SSHConcurrency returns how many nodes a label-selector SSH command runs on at once
+func (sc *ServerContext) SSHConcurrency() int {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+	if sc.sshConcurrency <= 0 {
+		return DefaultSSHConcurrency
+	}
+	return sc.sshConcurrency
+}
+
+// SSHPolicy returns the policy for teleport_ssh commands, or nil if none is configured
+func (sc *ServerContext) SSHPolicy() *policy.SSHPolicy {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+	return sc.sshPolicy
+}
+
+// SCPPolicy returns the policy for the paths teleport_scp may transfer from or to
+func (sc *ServerContext) SCPPolicy() policy.SCPPolicy {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+	if sc.scpPolicy == nil {
+		return policy.DefaultSCPPolicy()
+	}
+	return *sc.scpPolicy
+}
+
+// Jobs returns the manager of background jobs, which are killed when the
+// server shuts down
+func (sc *ServerContext) Jobs() *JobManager {
+	return sc.jobs
+}
+
 // IsDryRun returns whether operations should be simulated
 func (sc *ServerContext) IsDryRun() bool {
 	sc.mutex.RLock()
@@ -117,6 +321,17 @@ func (sc *ServerContext) Logger() Logger {
 	return sc.logger
 }
 
+// TeleportClient returns a tsh client configured from the current server settings
+func (sc *ServerContext) TeleportClient() *teleport.Client {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+	return teleport.NewClient(sc.dryRun, sc.debugMode,
+		teleport.WithRunner(sc.runner),
+		teleport.WithTimeout(sc.defaultTimeout),
+		teleport.WithCaptureLimit(sc.captureLimit),
+	)
+}
+
 // SetDryRun dynamically sets whether operations should be simulated
 func (sc *ServerContext) SetDryRun(enabled bool) {
 	sc.mutex.Lock()
@@ -141,7 +356,7 @@ func (sc *ServerContext) Shutdown() error {
 		sc.cancel = nil
 	}
 
-	return nil
+	return sc.removeKubeconfigs()
 }
 
 // noopLogger is a logger that does nothing
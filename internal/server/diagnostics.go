@@ -0,0 +1,33 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/giantswarm/mcp-teleport/internal/teleport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// WithDiagnostics appends the stderr of the given executions, such as MFA
+// prompts or deprecation warnings printed by tsh, to a tool result as a
+// separate text content. Tools whose result is parsed from stdout use it so
+// the warnings are neither lost nor mixed into the parsed output.
+func WithDiagnostics(result *mcp.CallToolResult, executions ...*teleport.ExecutionResult) *mcp.CallToolResult {
+	var diagnostics []string
+	for _, execution := range executions {
+		if execution == nil {
+			continue
+		}
+		if stderr := strings.TrimSpace(execution.Stderr); stderr != "" {
+			diagnostics = append(diagnostics, stderr)
+		}
+	}
+	if len(diagnostics) == 0 {
+		return result
+	}
+
+	result.Content = append(result.Content, mcp.TextContent{
+		Type: "text",
+		Text: "tsh diagnostics (stderr):\n" + strings.Join(diagnostics, "\n"),
+	})
+	return result
+}
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/giantswarm/mcp-teleport/internal/teleport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestWithDiagnostics(t *testing.T) {
+	result := WithDiagnostics(mcp.NewToolResultText("ok"),
+		&teleport.ExecutionResult{Stderr: "WARNING: first\n"},
+		nil,
+		&teleport.ExecutionResult{Stderr: "  \n"},
+		&teleport.ExecutionResult{Stderr: "WARNING: second\n"},
+	)
+	if len(result.Content) != 2 {
+		t.Fatalf("Expected output and diagnostics, got %+v", result.Content)
+	}
+	want := "tsh diagnostics (stderr):\nWARNING: first\nWARNING: second"
+	if got := result.Content[1].(mcp.TextContent).Text; got != want {
+		t.Errorf("Diagnostics = %q, want %q", got, want)
+	}
+
+	result = WithDiagnostics(mcp.NewToolResultText("ok"), &teleport.ExecutionResult{Stdout: "ok"})
+	if len(result.Content) != 1 {
+		t.Errorf("Expected no diagnostics without stderr, got %+v", result.Content)
+	}
+}
@@ -11,9 +11,49 @@
 // It provides methods for accessing configuration like dry-run mode, debug mode,
 // and non-destructive mode.
 //
+// TeleportClient: Returns a teleport.Client configured from the server settings.
+// The Runner that executes tsh can be replaced with WithRunner, e.g. with a
+// tshtest.Runner in tests.
+//
 // Logger interface: A structured logging interface that can be implemented
 // by different logging backends.
 //
+// Request cancellation: TrackToolCall, ToolHandlerMiddleware and
+// HandleCancelledNotification give every tool call a context that is
+// cancelled when the client sends notifications/cancelled for it or when the
+// server shuts down, so running tsh processes are killed promptly.
+//
+// Timeouts: WithDefaultTimeout, WithToolTimeouts and WithMaxTimeout configure
+// how long tsh commands may run. ToolTimeout resolves the timeout for a call,
+// honouring the optional timeoutSeconds argument that TimeoutOption declares
+// on every tool, and ToolHandlerMiddleware applies it to the call's context.
+// Tools whose arguments imply a longer run raise it, limited by CapTimeout.
+//
+// Background jobs: Jobs returns the JobManager that runs tsh commands started
+// with background=true after the call has returned. Each job keeps at least
+// the last JobOutputLimit bytes of its output, runs for JobTimeout and is
+// killed when the server shuts down. Jobs belong to the MCP session that
+// started them.
+//
+// Progress: ProgressWriter returns a writer that sends each line of tsh
+// output to the client as notifications/progress when the call carries a
+// progress token. Pass it to teleport.Client.StreamCommandContext.
+//
+// Output limits: LimitOutput cuts command output larger than OutputLimit
+// down to its head and tail and stores the whole output under a handle, which
+// ReadOutput pages through for teleport_output_read. Handles belong to the MCP
+// session that produced the output. CaptureLimit bounds the output captured
+// per tsh command.
+//
+// Kubeconfigs: with WithKubeconfigIsolation, ToolHandlerMiddleware sets
+// KUBECONFIG for tsh to a file per MCP session in a private directory, so
+// tsh kube login does not touch the user's kubeconfig. Kubeconfig returns the
+// file of a session; Shutdown removes the directory.
+//
+// Diagnostics: WithDiagnostics appends what tsh printed on stderr (MFA
+// prompts, deprecation warnings) to a tool result whose content was parsed
+// from stdout.
+//
 // # Usage
 //
 // The ServerContext is created once during server startup and passed to all
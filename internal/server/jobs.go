@@ -0,0 +1,365 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/mcp-teleport/internal/teleport"
+)
+
+const (
+	// DefaultJobTimeout is how long a background job may run unless configured otherwise
+	DefaultJobTimeout = time.Hour
+
+	// MaxRunningJobs is how many background jobs may run at once
+	MaxRunningJobs = 10
+
+	// maxFinishedJobs is how many finished jobs are kept for teleport_job_status
+	// and teleport_job_list before the oldest are forgotten
+	maxFinishedJobs = 50
+
+	// JobOutputLimit is how many bytes of output are at least kept per job.
+	// Older output is dropped once a job has written more.
+	JobOutputLimit = 1024 * 1024
+
+	// jobOutputSlack is how far beyond its limit the output of a job may grow
+	// before older output is dropped, so it is not moved on every write
+	jobOutputSlack = JobOutputLimit / 4
+)
+
+// JobState is the lifecycle state of a background job
+type JobState string
+
+const (
+	JobRunning   JobState = "running"
+	JobSucceeded JobState = "succeeded"
+	JobFailed    JobState = "failed"
+	JobCancelled JobState = "cancelled"
+	JobTimedOut  JobState = "timed_out"
+)
+
+// ErrJobNotFound is returned for job IDs that are unknown or were forgotten
+var ErrJobNotFound = errors.New("job not found")
+
+// errJobCancelled is the cancellation cause for jobs cancelled with teleport_job_cancel
+var errJobCancelled = errors.New("cancelled by client")
+
+// JobFunc runs the command of a background job, copying its output to output
+// while it runs
+type JobFunc func(ctx context.Context, output io.Writer) *teleport.ExecutionResult
+
+// JobInfo describes a background job
+type JobInfo struct {
+	ID string `json:"id"`
+	// Tool is the tool that started the job, e.g. teleport_ssh
+	Tool string `json:"tool"`
+	// Command is the tsh command line the job runs
+	Command    string     `json:"command"`
+	State      JobState   `json:"state"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	DurationMs int64      `json:"durationMs"`
+	// ExitCode is set once the job has finished
+	ExitCode *int   `json:"exitCode,omitempty"`
+	Error    string `json:"error,omitempty"`
+	// OutputBytes is the total number of bytes the job has written
+	OutputBytes int64 `json:"outputBytes"`
+	// DroppedBytes is the number of bytes at the start of the output that were
+	// dropped to stay within JobOutputLimit
+	DroppedBytes int64 `json:"droppedBytes"`
+}
+
+// JobOutput is a chunk of the output of a background job
+type JobOutput struct {
+	Output string `json:"output"`
+	// Offset is the position of Output in the job's output
+	Offset int64 `json:"offset"`
+	// NextOffset is the offset to request the following output with
+	NextOffset int64 `json:"nextOffset"`
+	// Skipped is set when the requested offset had already been dropped
+	Skipped bool `json:"skipped"`
+	// More is set when output beyond NextOffset is already available
+	More bool `json:"more"`
+}
+
+// Job is a tsh command running in the background
+type Job struct {
+	seq int
+	id  string
+	// session is the MCP session that started the job
+	session   string
+	tool      string
+	command   string
+	startedAt time.Time
+	cancel    context.CancelCauseFunc
+	done      chan struct{}
+	output    jobOutput
+
+	mutex        sync.Mutex
+	state        JobState
+	finishedAt   time.Time
+	exitCode     int
+	errorMessage string
+}
+
+// ID returns the job ID
+func (j *Job) ID() string {
+	return j.id
+}
+
+// Done returns a channel that is closed when the job has finished
+func (j *Job) Done() <-chan struct{} {
+	return j.done
+}
+
+// Info returns the current state of the job
+func (j *Job) Info() JobInfo {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	info := JobInfo{
+		ID:        j.id,
+		Tool:      j.tool,
+		Command:   j.command,
+		State:     j.state,
+		StartedAt: j.startedAt,
+		Error:     j.errorMessage,
+	}
+	info.OutputBytes, info.DroppedBytes = j.output.size()
+
+	if j.state == JobRunning {
+		info.DurationMs = time.Since(j.startedAt).Milliseconds()
+	} else {
+		finishedAt := j.finishedAt
+		exitCode := j.exitCode
+		info.FinishedAt = &finishedAt
+		info.ExitCode = &exitCode
+		info.DurationMs = finishedAt.Sub(j.startedAt).Milliseconds()
+	}
+	return info
+}
+
+// Output returns at most maxBytes of output starting at offset. Output that
+// was already dropped is skipped.
+func (j *Job) Output(offset int64, maxBytes int) JobOutput {
+	return j.output.read(offset, maxBytes)
+}
+
+// finish records the result of the job's command
+func (j *Job) finish(result *teleport.ExecutionResult) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.finishedAt = time.Now()
+	j.exitCode = result.StatusCode
+	j.errorMessage = result.ErrorMessage
+	switch {
+	case result.Success:
+		j.state = JobSucceeded
+	case result.TimedOut:
+		j.state = JobTimedOut
+	case result.Cancelled:
+		j.state = JobCancelled
+	default:
+		j.state = JobFailed
+	}
+}
+
+func (j *Job) running() bool {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	return j.state == JobRunning
+}
+
+// jobOutput keeps at least the last limit bytes a job wrote
+type jobOutput struct {
+	mutex sync.Mutex
+	data  []byte
+	// start is the offset of data[0] in the job's output
+	start int64
+	limit int
+	// slack is how many bytes data may grow beyond limit before the oldest
+	// are dropped
+	slack int
+}
+
+func (o *jobOutput) Write(p []byte) (int, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	o.data = append(o.data, p...)
+	// Drop the oldest output in chunks of at least slack bytes rather than on
+	// every write
+	if len(o.data) > o.limit+o.slack {
+		over := len(o.data) - o.limit
+		o.data = o.data[:copy(o.data, o.data[over:])]
+		o.start += int64(over)
+	}
+	return len(p), nil
+}
+
+// size returns the total number of bytes written and how many were dropped
+func (o *jobOutput) size() (int64, int64) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	return o.start + int64(len(o.data)), o.start
+}
+
+func (o *jobOutput) read(offset int64, maxBytes int) JobOutput {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	end := o.start + int64(len(o.data))
+	chunk := JobOutput{Offset: offset}
+	if offset < o.start {
+		chunk.Offset = o.start
+		chunk.Skipped = true
+	}
+	if chunk.Offset > end {
+		chunk.Offset = end
+	}
+
+	next := end
+	if maxBytes > 0 && next-chunk.Offset > int64(maxBytes) {
+		next = chunk.Offset + int64(maxBytes)
+		chunk.More = true
+	}
+	chunk.Output = string(o.data[chunk.Offset-o.start : next-o.start])
+	chunk.NextOffset = next
+	return chunk
+}
+
+// JobManager runs tsh commands in the background and keeps their output
+type JobManager struct {
+	ctx    context.Context
+	mutex  sync.Mutex
+	jobs   map[string]*Job
+	nextID int
+}
+
+// newJobManager creates a job manager whose jobs are killed when ctx is cancelled
+func newJobManager(ctx context.Context) *JobManager {
+	return &JobManager{
+		ctx:  ctx,
+		jobs: make(map[string]*Job),
+	}
+}
+
+// Start runs fn as a background job that is killed after timeout. tool and
+// command describe the job in teleport_job_status and teleport_job_list. The
+// job belongs to the MCP session of ctx; other sessions cannot see it.
+func (m *JobManager) Start(ctx context.Context, tool, command string, timeout time.Duration, fn JobFunc) (*Job, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	running := 0
+	for _, job := range m.jobs {
+		if job.running() {
+			running++
+		}
+	}
+	if running >= MaxRunningJobs {
+		return nil, fmt.Errorf("%d jobs are already running; wait for one to finish or cancel one with teleport_job_cancel", running)
+	}
+
+	m.nextID++
+	jobCtx, cancel := context.WithCancelCause(m.ctx)
+	job := &Job{
+		seq:       m.nextID,
+		id:        fmt.Sprintf("job-%d", m.nextID),
+		session:   clientSessionID(ctx),
+		tool:      tool,
+		command:   command,
+		startedAt: time.Now(),
+		cancel:    cancel,
+		done:      make(chan struct{}),
+		output:    jobOutput{limit: JobOutputLimit, slack: jobOutputSlack},
+		state:     JobRunning,
+	}
+	m.jobs[job.id] = job
+	m.pruneLocked()
+
+	go func() {
+		defer close(job.done)
+		defer cancel(nil)
+		// The job outlives the request, but runs with its environment, e.g.
+		// the session's kubeconfig
+		runCtx := teleport.ContextWithEnvFrom(teleport.ContextWithTimeout(jobCtx, timeout), ctx)
+		result := fn(runCtx, &job.output)
+		job.finish(result)
+	}()
+
+	return job, nil
+}
+
+// Get returns the job with the given ID started by the MCP session of ctx
+func (m *JobManager) Get(ctx context.Context, id string) (*Job, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok || job.session != clientSessionID(ctx) {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+	return job, nil
+}
+
+// Cancel stops the job with the given ID started by the MCP session of ctx.
+// Cancelling a finished job has no effect.
+func (m *JobManager) Cancel(ctx context.Context, id string) (*Job, error) {
+	job, err := m.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	job.cancel(errJobCancelled)
+	return job, nil
+}
+
+// List returns the known jobs started by the MCP session of ctx, most
+// recently started first
+func (m *JobManager) List(ctx context.Context) []JobInfo {
+	session := clientSessionID(ctx)
+	m.mutex.Lock()
+	jobs := make([]*Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		if job.session == session {
+			jobs = append(jobs, job)
+		}
+	}
+	m.mutex.Unlock()
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].seq > jobs[j].seq
+	})
+	infos := make([]JobInfo, len(jobs))
+	for i, job := range jobs {
+		infos[i] = job.Info()
+	}
+	return infos
+}
+
+// pruneLocked forgets the oldest finished jobs beyond maxFinishedJobs. The
+// caller must hold the mutex.
+func (m *JobManager) pruneLocked() {
+	var finished []*Job
+	for _, job := range m.jobs {
+		if !job.running() {
+			finished = append(finished, job)
+		}
+	}
+	if len(finished) <= maxFinishedJobs {
+		return
+	}
+
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].seq < finished[j].seq
+	})
+	for _, job := range finished[:len(finished)-maxFinishedJobs] {
+		delete(m.jobs, job.id)
+	}
+}
@@ -0,0 +1,281 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/mcp-teleport/internal/teleport"
+	"github.com/giantswarm/mcp-teleport/internal/teleport/tshtest"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+func newJobTestContext(t *testing.T, runner *tshtest.Runner) *ServerContext {
+	t.Helper()
+	sc, err := NewServerContext(context.Background(), WithRunner(runner))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	t.Cleanup(func() { sc.Shutdown() })
+	return sc
+}
+
+// streamJob returns a JobFunc that runs tsh ssh with the server's client
+func streamJob(sc *ServerContext, args ...string) JobFunc {
+	client := sc.TeleportClient()
+	return func(ctx context.Context, output io.Writer) *teleport.ExecutionResult {
+		return client.StreamCommandContext(ctx, "ssh", args, output, output)
+	}
+}
+
+func waitForJob(t *testing.T, job *Job) {
+	t.Helper()
+	select {
+	case <-job.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Job %s did not finish", job.ID())
+	}
+}
+
+func TestJobSucceeds(t *testing.T) {
+	runner := tshtest.NewRunner().On(`^tsh ssh root@node uptime$`, tshtest.Response{Stdout: "up 3 days\n", Stderr: "WARNING: slow\n"})
+	sc := newJobTestContext(t, runner)
+
+	job, err := sc.Jobs().Start(context.Background(), "teleport_ssh", "tsh ssh root@node uptime", time.Minute, streamJob(sc, "root@node", "uptime"))
+	if err != nil {
+		t.Fatalf("Failed to start job: %v", err)
+	}
+	if job.ID() != "job-1" {
+		t.Errorf("ID = %q, want job-1", job.ID())
+	}
+	waitForJob(t, job)
+
+	info := job.Info()
+	if info.State != JobSucceeded {
+		t.Errorf("State = %q, want %q", info.State, JobSucceeded)
+	}
+	if info.ExitCode == nil || *info.ExitCode != 0 {
+		t.Errorf("Expected exit code 0, got: %+v", info)
+	}
+	if info.FinishedAt == nil {
+		t.Error("Expected FinishedAt to be set")
+	}
+
+	output := job.Output(0, 0)
+	if output.Output != "up 3 days\nWARNING: slow\n" {
+		t.Errorf("Output = %q", output.Output)
+	}
+	if output.NextOffset != info.OutputBytes {
+		t.Errorf("NextOffset = %d, want %d", output.NextOffset, info.OutputBytes)
+	}
+}
+
+func TestJobFails(t *testing.T) {
+	runner := tshtest.NewRunner().On(`^tsh ssh`, tshtest.Response{Stderr: "no such host\n", ExitCode: 255})
+	sc := newJobTestContext(t, runner)
+
+	job, err := sc.Jobs().Start(context.Background(), "teleport_ssh", "tsh ssh root@missing true", time.Minute, streamJob(sc, "root@missing", "true"))
+	if err != nil {
+		t.Fatalf("Failed to start job: %v", err)
+	}
+	waitForJob(t, job)
+
+	info := job.Info()
+	if info.State != JobFailed {
+		t.Errorf("State = %q, want %q", info.State, JobFailed)
+	}
+	if info.ExitCode == nil || *info.ExitCode != 255 {
+		t.Errorf("Expected exit code 255, got: %+v", info)
+	}
+	if info.Error == "" {
+		t.Error("Expected an error message")
+	}
+}
+
+func TestJobCancel(t *testing.T) {
+	runner := tshtest.NewRunner().On(`^tsh ssh`, tshtest.Response{Stdout: "tailing\n", Block: true})
+	sc := newJobTestContext(t, runner)
+
+	job, err := sc.Jobs().Start(context.Background(), "teleport_ssh", "tsh ssh root@node tail -f log", time.Minute, streamJob(sc, "root@node", "tail -f log"))
+	if err != nil {
+		t.Fatalf("Failed to start job: %v", err)
+	}
+	if _, err := sc.Jobs().Cancel(context.Background(), job.ID()); err != nil {
+		t.Fatalf("Failed to cancel job: %v", err)
+	}
+	waitForJob(t, job)
+
+	info := job.Info()
+	if info.State != JobCancelled {
+		t.Errorf("State = %q, want %q", info.State, JobCancelled)
+	}
+	if !strings.Contains(info.Error, "cancelled by client") {
+		t.Errorf("Error = %q, want the cancellation cause", info.Error)
+	}
+}
+
+func TestJobTimesOut(t *testing.T) {
+	runner := tshtest.NewRunner().On(`^tsh ssh`, tshtest.Response{Block: true})
+	sc := newJobTestContext(t, runner)
+
+	job, err := sc.Jobs().Start(context.Background(), "teleport_ssh", "tsh ssh root@node sleep 100", 20*time.Millisecond, streamJob(sc, "root@node", "sleep 100"))
+	if err != nil {
+		t.Fatalf("Failed to start job: %v", err)
+	}
+	waitForJob(t, job)
+
+	if state := job.Info().State; state != JobTimedOut {
+		t.Errorf("State = %q, want %q", state, JobTimedOut)
+	}
+}
+
+func TestJobsKilledOnShutdown(t *testing.T) {
+	runner := tshtest.NewRunner().On(`^tsh ssh`, tshtest.Response{Block: true})
+	sc := newJobTestContext(t, runner)
+
+	job, err := sc.Jobs().Start(context.Background(), "teleport_ssh", "tsh ssh root@node sleep 100", time.Minute, streamJob(sc, "root@node", "sleep 100"))
+	if err != nil {
+		t.Fatalf("Failed to start job: %v", err)
+	}
+	sc.Shutdown()
+	waitForJob(t, job)
+
+	if state := job.Info().State; state != JobCancelled {
+		t.Errorf("State = %q, want %q", state, JobCancelled)
+	}
+}
+
+func TestJobRunningLimit(t *testing.T) {
+	runner := tshtest.NewRunner().On(`^tsh ssh`, tshtest.Response{Block: true})
+	sc := newJobTestContext(t, runner)
+
+	for i := 0; i < MaxRunningJobs; i++ {
+		if _, err := sc.Jobs().Start(context.Background(), "teleport_ssh", "tsh ssh", time.Minute, streamJob(sc, "root@node", "sleep 100")); err != nil {
+			t.Fatalf("Failed to start job %d: %v", i, err)
+		}
+	}
+	if _, err := sc.Jobs().Start(context.Background(), "teleport_ssh", "tsh ssh", time.Minute, streamJob(sc, "root@node", "sleep 100")); err == nil {
+		t.Error("Expected an error when too many jobs are running")
+	}
+}
+
+func TestJobNotFound(t *testing.T) {
+	sc := newJobTestContext(t, tshtest.NewRunner())
+
+	if _, err := sc.Jobs().Get(context.Background(), "job-42"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Expected ErrJobNotFound, got %v", err)
+	}
+	if _, err := sc.Jobs().Cancel(context.Background(), "job-42"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Expected ErrJobNotFound, got %v", err)
+	}
+}
+
+func TestJobOtherSession(t *testing.T) {
+	runner := tshtest.NewRunner().On(`^tsh ssh`, tshtest.Response{Stdout: "ok\n"})
+	sc := newJobTestContext(t, runner)
+
+	srv := mcpserver.NewMCPServer("test", "1.0.0")
+	sessionCtx := srv.WithContext(context.Background(), &testSession{})
+	job, err := sc.Jobs().Start(sessionCtx, "teleport_ssh", "tsh ssh root@node uptime", time.Minute, streamJob(sc, "root@node", "uptime"))
+	if err != nil {
+		t.Fatalf("Failed to start job: %v", err)
+	}
+	waitForJob(t, job)
+
+	// Other sessions can neither see nor cancel the job
+	if _, err := sc.Jobs().Get(context.Background(), job.ID()); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Expected ErrJobNotFound for another session, got %v", err)
+	}
+	if _, err := sc.Jobs().Cancel(context.Background(), job.ID()); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Expected ErrJobNotFound for another session, got %v", err)
+	}
+	if jobs := sc.Jobs().List(context.Background()); len(jobs) != 0 {
+		t.Errorf("Expected no jobs for another session, got %v", jobs)
+	}
+
+	if _, err := sc.Jobs().Get(sessionCtx, job.ID()); err != nil {
+		t.Errorf("Expected the owning session to get the job, got %v", err)
+	}
+	if jobs := sc.Jobs().List(sessionCtx); len(jobs) != 1 {
+		t.Errorf("Expected one job for the owning session, got %v", jobs)
+	}
+}
+
+func TestJobListPrunesFinishedJobs(t *testing.T) {
+	runner := tshtest.NewRunner().On(`^tsh ssh`, tshtest.Response{Stdout: "ok\n"})
+	sc := newJobTestContext(t, runner)
+
+	for i := 0; i < maxFinishedJobs+5; i++ {
+		job, err := sc.Jobs().Start(context.Background(), "teleport_ssh", fmt.Sprintf("tsh ssh root@node echo %d", i), time.Minute, streamJob(sc, "root@node", "echo"))
+		if err != nil {
+			t.Fatalf("Failed to start job %d: %v", i, err)
+		}
+		waitForJob(t, job)
+	}
+
+	jobs := sc.Jobs().List(context.Background())
+	// The newest job is still counted as finished only after the next start
+	if len(jobs) > maxFinishedJobs+1 {
+		t.Errorf("Expected at most %d jobs to be kept, got %d", maxFinishedJobs+1, len(jobs))
+	}
+	if jobs[0].ID != fmt.Sprintf("job-%d", maxFinishedJobs+5) {
+		t.Errorf("Expected the newest job first, got %s", jobs[0].ID)
+	}
+	if _, err := sc.Jobs().Get(context.Background(), "job-1"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Expected the oldest job to be forgotten, got %v", err)
+	}
+}
+
+func TestJobOutputBounded(t *testing.T) {
+	output := jobOutput{limit: 10, slack: 2}
+	output.Write([]byte("0123456789"))
+	output.Write([]byte("abcde"))
+
+	total, dropped := output.size()
+	if total != 15 || dropped != 5 {
+		t.Errorf("size() = %d, %d, want 15, 5", total, dropped)
+	}
+
+	chunk := output.read(0, 0)
+	if chunk.Output != "56789abcde" || !chunk.Skipped || chunk.Offset != 5 || chunk.NextOffset != 15 {
+		t.Errorf("read(0, 0) = %+v", chunk)
+	}
+
+	chunk = output.read(7, 4)
+	if chunk.Output != "789a" || chunk.Skipped || !chunk.More || chunk.NextOffset != 11 {
+		t.Errorf("read(7, 4) = %+v", chunk)
+	}
+
+	chunk = output.read(15, 0)
+	if chunk.Output != "" || chunk.More || chunk.NextOffset != 15 {
+		t.Errorf("read(15, 0) = %+v", chunk)
+	}
+
+	chunk = output.read(100, 0)
+	if chunk.Offset != 15 || chunk.NextOffset != 15 {
+		t.Errorf("read(100, 0) = %+v", chunk)
+	}
+}
+
+func TestJobOutputDropsInChunks(t *testing.T) {
+	output := jobOutput{limit: 8, slack: 4}
+	for i := 0; i < 100; i++ {
+		output.Write([]byte{byte('a' + i%26)})
+		if len(output.data) < min(i+1, 8) || len(output.data) > 12 {
+			t.Fatalf("Expected between 8 and 12 bytes to be kept after %d writes, got %d", i+1, len(output.data))
+		}
+	}
+
+	total, dropped := output.size()
+	if total != 100 || dropped != total-int64(len(output.data)) {
+		t.Errorf("size() = %d, %d with %d bytes kept", total, dropped, len(output.data))
+	}
+	// The last writes are kept
+	if chunk := output.read(92, 0); chunk.Output != "opqrstuv" || chunk.Skipped {
+		t.Errorf("read(92, 0) = %+v", chunk)
+	}
+}
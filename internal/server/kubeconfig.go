@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// defaultKubeconfigSession names the kubeconfig of tool calls outside an MCP session
+const defaultKubeconfigSession = "default"
+
+// WithKubeconfigIsolation sets whether tsh keeps kubeconfigs in a private
+// directory of the server, one per MCP session, instead of the user's
+// kubeconfig
+func WithKubeconfigIsolation(enabled bool) ServerOption {
+	return func(sc *ServerContext) {
+		sc.kubeconfigIsolation = enabled
+	}
+}
+
+// WithKubeconfigDir sets the directory in which the private kubeconfig
+// directory is created; empty uses the system temporary directory
+func WithKubeconfigDir(dir string) ServerOption {
+	return func(sc *ServerContext) {
+		sc.kubeconfigBaseDir = dir
+	}
+}
+
+// IsKubeconfigIsolated returns whether tsh uses private kubeconfigs instead
+// of the user's kubeconfig
+func (sc *ServerContext) IsKubeconfigIsolated() bool {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+	return sc.kubeconfigIsolation
+}
+
+// Kubeconfig returns the kubeconfig tsh reads and writes for the MCP session
+// of ctx. With isolation it is a file in the server's private directory,
+// which is created on first use and removed on shutdown; the file itself is
+// created by tsh kube login. Otherwise it is the user's kubeconfig.
+func (sc *ServerContext) Kubeconfig(ctx context.Context) (string, error) {
+	if !sc.IsKubeconfigIsolated() {
+		return userKubeconfig()
+	}
+
+	dir, err := sc.kubeconfigDir()
+	if err != nil {
+		return "", err
+	}
+	session := defaultKubeconfigSession
+	if clientSession := mcpserver.ClientSessionFromContext(ctx); clientSession != nil && clientSession.SessionID() != "" {
+		session = clientSession.SessionID()
+	}
+	return filepath.Join(dir, sanitizeFilename(session)+".yaml"), nil
+}
+
+// RememberKubeContext records the current context of a kubeconfig before the
+// server changes it for the first time; later calls for the same kubeconfig
+// are ignored. An empty context means the kubeconfig had none.
+func (sc *ServerContext) RememberKubeContext(kubeconfig, currentContext string) {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+	if sc.originalKubeContexts == nil {
+		sc.originalKubeContexts = make(map[string]string)
+	}
+	if _, ok := sc.originalKubeContexts[kubeconfig]; !ok {
+		sc.originalKubeContexts[kubeconfig] = currentContext
+	}
+}
+
+// OriginalKubeContext returns the current context recorded for a kubeconfig
+// with RememberKubeContext, and whether one was recorded
+func (sc *ServerContext) OriginalKubeContext(kubeconfig string) (string, bool) {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+	currentContext, ok := sc.originalKubeContexts[kubeconfig]
+	return currentContext, ok
+}
+
+// kubeconfigDir returns the private kubeconfig directory, creating it if needed
+func (sc *ServerContext) kubeconfigDir() (string, error) {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+	if sc.kubeconfigPrivateDir != "" {
+		return sc.kubeconfigPrivateDir, nil
+	}
+	if sc.kubeconfigShutdown {
+		return "", fmt.Errorf("server is shutting down")
+	}
+
+	// MkdirTemp creates the directory accessible to the current user only
+	dir, err := os.MkdirTemp(sc.kubeconfigBaseDir, "mcp-teleport-kube-")
+	if err != nil {
+		return "", fmt.Errorf("cannot create private kubeconfig directory: %w", err)
+	}
+	sc.kubeconfigPrivateDir = dir
+	return dir, nil
+}
+
+// removeKubeconfigs deletes the private kubeconfig directory; the caller
+// must hold sc.mutex
+func (sc *ServerContext) removeKubeconfigs() error {
+	sc.kubeconfigShutdown = true
+	if sc.kubeconfigPrivateDir == "" {
+		return nil
+	}
+	if err := os.RemoveAll(sc.kubeconfigPrivateDir); err != nil {
+		return fmt.Errorf("cannot remove private kubeconfig directory: %w", err)
+	}
+	sc.kubeconfigPrivateDir = ""
+	return nil
+}
+
+// userKubeconfig returns the kubeconfig kubectl uses by default: the first
+// file in $KUBECONFIG, or ~/.kube/config
+func userKubeconfig() (string, error) {
+	if files := filepath.SplitList(os.Getenv("KUBECONFIG")); len(files) > 0 && files[0] != "" {
+		return files[0], nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot find the home directory: %w", err)
+	}
+	return filepath.Join(home, ".kube", "config"), nil
+}
+
+// sanitizeFilename replaces characters that are not safe in file names
+func sanitizeFilename(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, name)
+}
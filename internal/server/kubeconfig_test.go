@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/mcp-teleport/internal/teleport"
+	"github.com/giantswarm/mcp-teleport/internal/teleport/tshtest"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+func TestKubeconfigIsolation(t *testing.T) {
+	baseDir := t.TempDir()
+	runner := tshtest.NewRunner().On(`^tsh kube ls`, tshtest.Response{Stdout: "[]"})
+	sc, err := NewServerContext(context.Background(),
+		WithRunner(runner),
+		WithKubeconfigIsolation(true),
+		WithKubeconfigDir(baseDir),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+
+	// Tool calls run tsh with the kubeconfig of their session
+	handler := sc.ToolHandlerMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		sc.TeleportClient().ExecuteCommandContext(ctx, "kube ls", nil)
+		return mcp.NewToolResultText(""), nil
+	})
+	srv := mcpserver.NewMCPServer("test", "1.0.0")
+	sessionCtx := srv.WithContext(context.Background(), &testSession{})
+	for _, ctx := range []context.Context{context.Background(), sessionCtx} {
+		if _, err := handler(ctx, mcp.CallToolRequest{}); err != nil {
+			t.Fatalf("handler failed: %v", err)
+		}
+	}
+
+	envs := runner.Envs()
+	if len(envs) != 2 || len(envs[0]) != 1 || len(envs[1]) != 1 {
+		t.Fatalf("Expected KUBECONFIG for both calls, got %v", envs)
+	}
+	defaultConfig := strings.TrimPrefix(envs[0][0], "KUBECONFIG=")
+	sessionConfig := strings.TrimPrefix(envs[1][0], "KUBECONFIG=")
+	if filepath.Base(defaultConfig) != "default.yaml" || filepath.Base(sessionConfig) != "test-session.yaml" {
+		t.Errorf("Unexpected kubeconfigs: %s, %s", defaultConfig, sessionConfig)
+	}
+	dir := filepath.Dir(sessionConfig)
+	if filepath.Dir(dir) != baseDir {
+		t.Errorf("Expected the private directory in %s, got %s", baseDir, dir)
+	}
+	if info, err := os.Stat(dir); err != nil || info.Mode().Perm() != 0o700 {
+		t.Errorf("Expected a private directory, got %v %v", info, err)
+	}
+
+	// Shutdown removes the kubeconfigs
+	if err := os.WriteFile(sessionConfig, []byte("apiVersion: v1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := sc.Shutdown(); err != nil {
+		t.Fatalf("Shutdown() failed: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be removed, got %v", dir, err)
+	}
+	if _, err := sc.Kubeconfig(context.Background()); err == nil {
+		t.Error("Expected no kubeconfig after shutdown")
+	}
+}
+
+func TestKubeconfigIsolationInJobs(t *testing.T) {
+	runner := tshtest.NewRunner().On(`^tsh kube ls`, tshtest.Response{Stdout: "[]"})
+	sc, err := NewServerContext(context.Background(),
+		WithRunner(runner),
+		WithKubeconfigIsolation(true),
+		WithKubeconfigDir(t.TempDir()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	// Background jobs run tsh with the kubeconfig of the session that started them
+	var job *Job
+	handler := sc.ToolHandlerMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var err error
+		job, err = sc.Jobs().Start(ctx, "teleport_kube_login", "tsh kube ls", time.Minute, func(ctx context.Context, output io.Writer) *teleport.ExecutionResult {
+			return sc.TeleportClient().StreamCommandContext(ctx, "kube", []string{"ls"}, output, output)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(""), nil
+	})
+	srv := mcpserver.NewMCPServer("test", "1.0.0")
+	if _, err := handler(srv.WithContext(context.Background(), &testSession{}), mcp.CallToolRequest{}); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+	waitForJob(t, job)
+
+	envs := runner.Envs()
+	if len(envs) != 1 || len(envs[0]) != 1 || filepath.Base(strings.TrimPrefix(envs[0][0], "KUBECONFIG=")) != "test-session.yaml" {
+		t.Errorf("Expected the session kubeconfig for the job, got %v", envs)
+	}
+}
+
+func TestKubeconfigWithoutIsolation(t *testing.T) {
+	t.Setenv("KUBECONFIG", "/home/alice/.kube/work"+string(filepath.ListSeparator)+"/home/alice/.kube/config")
+	sc, err := NewServerContext(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	if kubeconfig, err := sc.Kubeconfig(context.Background()); err != nil || kubeconfig != "/home/alice/.kube/work" {
+		t.Errorf("Expected the user's kubeconfig, got %q %v", kubeconfig, err)
+	}
+}
@@ -0,0 +1,248 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultOutputLimit is how many bytes of command output a tool result
+	// contains before the output is truncated
+	DefaultOutputLimit = 64 * 1024
+
+	// DefaultCaptureLimit is how many bytes of output are captured per tsh
+	// command; beyond that the middle of the output is dropped
+	DefaultCaptureLimit = 16 * 1024 * 1024
+
+	// minSharedOutput is the smallest share of the output limit LimitOutputs
+	// keeps of an output; with less, the whole output goes to a handle
+	minSharedOutput = 256
+
+	// maxStoredOutputBytes is how many bytes of truncated output are kept for
+	// teleport_output_read before the oldest outputs are forgotten
+	maxStoredOutputBytes = 64 * 1024 * 1024
+)
+
+// ErrOutputNotFound is returned for output handles that are unknown or were forgotten
+var ErrOutputNotFound = errors.New("output not found")
+
+// LimitedOutput is command output cut down to the output limit
+type LimitedOutput struct {
+	// Text is the output, or its head and tail with a marker in between if
+	// it was truncated
+	Text string
+	// Truncated is set when Text does not hold the whole output
+	Truncated bool
+	// TotalBytes is the size of the whole output
+	TotalBytes int
+	// Handle names the stored output for teleport_output_read if it was truncated
+	Handle string
+}
+
+// OutputChunk is a part of a stored output returned by teleport_output_read
+type OutputChunk struct {
+	Handle string `json:"handle"`
+	Output string `json:"output"`
+	// Offset is the position of Output in the stored output
+	Offset int `json:"offset"`
+	// NextOffset is the offset to request the following output with
+	NextOffset int `json:"nextOffset"`
+	TotalBytes int `json:"totalBytes"`
+	// More is set when output beyond NextOffset is available
+	More bool `json:"more"`
+}
+
+// outputStore keeps the full output of truncated results so clients can page
+// through it
+type outputStore struct {
+	mutex   sync.Mutex
+	outputs map[string]storedOutput
+	// order lists handles from oldest to newest
+	order  []string
+	bytes  int
+	nextID int
+}
+
+// storedOutput is a truncated output and the MCP session it belongs to
+type storedOutput struct {
+	session string
+	output  string
+}
+
+func (s *outputStore) add(session, output string) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.outputs == nil {
+		s.outputs = make(map[string]storedOutput)
+	}
+	s.nextID++
+	handle := fmt.Sprintf("out-%d", s.nextID)
+	s.outputs[handle] = storedOutput{session: session, output: output}
+	s.order = append(s.order, handle)
+	s.bytes += len(output)
+
+	// Forget the oldest outputs, but always keep the newest
+	for s.bytes > maxStoredOutputBytes && len(s.order) > 1 {
+		oldest := s.order[0]
+		s.order = s.order[1:]
+		s.bytes -= len(s.outputs[oldest].output)
+		delete(s.outputs, oldest)
+	}
+	return handle
+}
+
+// get returns the output stored under handle if it belongs to session
+func (s *outputStore) get(session, handle string) (string, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	stored, ok := s.outputs[handle]
+	if !ok || stored.session != session {
+		return "", false
+	}
+	return stored.output, true
+}
+
+// OutputLimit returns how many bytes of command output a tool result contains
+func (sc *ServerContext) OutputLimit() int {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+	if sc.outputLimit <= 0 {
+		return DefaultOutputLimit
+	}
+	return sc.outputLimit
+}
+
+// CaptureLimit returns how many bytes of output are captured per tsh command
+func (sc *ServerContext) CaptureLimit() int {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+	if sc.captureLimit <= 0 {
+		return DefaultCaptureLimit
+	}
+	return sc.captureLimit
+}
+
+// LimitOutput cuts output down to the output limit, keeping its head and
+// tail. The whole output is stored under a handle that teleport_output_read
+// pages through; only the MCP session of ctx can read it.
+func (sc *ServerContext) LimitOutput(ctx context.Context, output string) LimitedOutput {
+	return sc.LimitOutputTo(ctx, output, 0)
+}
+
+// LimitOutputTo is LimitOutput with a smaller limit requested by the client;
+// zero, negative or larger limits use the output limit
+func (sc *ServerContext) LimitOutputTo(ctx context.Context, output string, limit int) LimitedOutput {
+	if outputLimit := sc.OutputLimit(); limit <= 0 || limit > outputLimit {
+		limit = outputLimit
+	}
+	if len(output) <= limit {
+		return LimitedOutput{Text: output, TotalBytes: len(output)}
+	}
+
+	handle := sc.outputs.add(clientSessionID(ctx), output)
+
+	// Keep half of the limit from each end, on UTF-8 boundaries
+	headEnd := limit / 2
+	for headEnd > 0 && !utf8.RuneStart(output[headEnd]) {
+		headEnd--
+	}
+	tailStart := len(output) - (limit - limit/2)
+	for tailStart < len(output) && !utf8.RuneStart(output[tailStart]) {
+		tailStart++
+	}
+
+	text := fmt.Sprintf("%s\n\n... [%d bytes omitted; read them with teleport_output_read handle=%s offset=%d] ...\n\n%s",
+		output[:headEnd], tailStart-headEnd, handle, headEnd, output[tailStart:])
+	return LimitedOutput{
+		Text:       text,
+		Truncated:  true,
+		TotalBytes: len(output),
+		Handle:     handle,
+	}
+}
+
+// LimitOutputs cuts several outputs of one result, e.g. stdout and stderr,
+// down to the output limit together. Outputs smaller than an even share are
+// kept whole and leave their unused share to the larger ones, which are
+// truncated to what is left.
+func (sc *ServerContext) LimitOutputs(ctx context.Context, outputs ...string) []LimitedOutput {
+	order := make([]int, len(outputs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return len(outputs[order[i]]) < len(outputs[order[j]])
+	})
+
+	limited := make([]LimitedOutput, len(outputs))
+	budget := sc.OutputLimit()
+	for n, i := range order {
+		output := outputs[i]
+		share := budget / (len(order) - n)
+		switch {
+		case len(output) <= share:
+			limited[i] = LimitedOutput{Text: output, TotalBytes: len(output)}
+			budget -= len(output)
+			continue
+		case share < minSharedOutput:
+			limited[i] = sc.spillOutput(ctx, output)
+		default:
+			limited[i] = sc.LimitOutputTo(ctx, output, share)
+		}
+		budget -= share
+	}
+	return limited
+}
+
+// spillOutput stores the whole output under a handle and keeps none of it in
+// the result, for outputs whose share of the output limit is too small
+func (sc *ServerContext) spillOutput(ctx context.Context, output string) LimitedOutput {
+	if output == "" {
+		return LimitedOutput{}
+	}
+	handle := sc.outputs.add(clientSessionID(ctx), output)
+	return LimitedOutput{
+		Text:       fmt.Sprintf("... [%d bytes omitted; read them with teleport_output_read handle=%s offset=0] ...", len(output), handle),
+		Truncated:  true,
+		TotalBytes: len(output),
+		Handle:     handle,
+	}
+}
+
+// ReadOutput returns at most maxBytes of the output stored under handle,
+// starting at offset. Outputs of other MCP sessions are not found.
+func (sc *ServerContext) ReadOutput(ctx context.Context, handle string, offset, maxBytes int) (*OutputChunk, error) {
+	output, ok := sc.outputs.get(clientSessionID(ctx), handle)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrOutputNotFound, handle)
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(output) {
+		offset = len(output)
+	}
+	end := len(output)
+	if maxBytes > 0 && end-offset > maxBytes {
+		end = offset + maxBytes
+		// Do not split a UTF-8 sequence unless a single rune exceeds maxBytes
+		for end > offset+1 && !utf8.RuneStart(output[end]) {
+			end--
+		}
+	}
+
+	return &OutputChunk{
+		Handle:     handle,
+		Output:     output[offset:end],
+		Offset:     offset,
+		NextOffset: end,
+		TotalBytes: len(output),
+		More:       end < len(output),
+	}, nil
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+func TestLimitOutput(t *testing.T) {
+	sc, err := NewServerContext(context.Background(), WithOutputLimit(10))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	// Output within the limit is returned as is
+	limited := sc.LimitOutput(context.Background(), "0123456789")
+	if limited.Truncated || limited.Text != "0123456789" || limited.Handle != "" {
+		t.Errorf("Expected output within the limit to be kept, got %+v", limited)
+	}
+
+	output := "01234" + strings.Repeat("-", 20) + "56789"
+	limited = sc.LimitOutput(context.Background(), output)
+	if !limited.Truncated || limited.TotalBytes != 30 || limited.Handle != "out-1" {
+		t.Fatalf("Unexpected result: %+v", limited)
+	}
+	if !strings.HasPrefix(limited.Text, "01234\n") || !strings.HasSuffix(limited.Text, "\n56789") {
+		t.Errorf("Expected the head and tail to be kept, got %q", limited.Text)
+	}
+	if !strings.Contains(limited.Text, "[20 bytes omitted; read them with teleport_output_read handle=out-1 offset=5]") {
+		t.Errorf("Expected a marker with the handle, got %q", limited.Text)
+	}
+
+	// The stored output can be paged through
+	chunk, err := sc.ReadOutput(context.Background(), "out-1", 5, 12)
+	if err != nil {
+		t.Fatalf("ReadOutput() failed: %v", err)
+	}
+	if chunk.Output != strings.Repeat("-", 12) || chunk.NextOffset != 17 || !chunk.More {
+		t.Errorf("Unexpected chunk: %+v", chunk)
+	}
+	chunk, err = sc.ReadOutput(context.Background(), "out-1", 17, 0)
+	if err != nil {
+		t.Fatalf("ReadOutput() failed: %v", err)
+	}
+	if chunk.Output != strings.Repeat("-", 8)+"56789" || chunk.More || chunk.TotalBytes != 30 {
+		t.Errorf("Unexpected chunk: %+v", chunk)
+	}
+
+	if _, err := sc.ReadOutput(context.Background(), "out-42", 0, 0); !errors.Is(err, ErrOutputNotFound) {
+		t.Errorf("Expected ErrOutputNotFound, got %v", err)
+	}
+}
+
+func TestLimitOutputTo(t *testing.T) {
+	sc, err := NewServerContext(context.Background(), WithOutputLimit(10))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	if limited := sc.LimitOutputTo(context.Background(), "0123456789", 4); !limited.Truncated || !strings.HasPrefix(limited.Text, "01\n") {
+		t.Errorf("Expected a smaller limit to apply, got %+v", limited)
+	}
+	// Clients cannot raise the limit
+	if limited := sc.LimitOutputTo(context.Background(), "0123456789abcdef", 100); !limited.Truncated {
+		t.Errorf("Expected the output limit to apply, got %+v", limited)
+	}
+}
+
+func TestLimitOutputKeepsRunes(t *testing.T) {
+	sc, err := NewServerContext(context.Background(), WithOutputLimit(6))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	// Every rune is 3 bytes, so the limit falls inside runes at both ends
+	limited := sc.LimitOutput(context.Background(), strings.Repeat("€", 10))
+	if !strings.HasPrefix(limited.Text, "€\n") || !strings.HasSuffix(limited.Text, "\n€") {
+		t.Errorf("Expected whole runes at both ends, got %q", limited.Text)
+	}
+
+	chunk, err := sc.ReadOutput(context.Background(), limited.Handle, 0, 4)
+	if err != nil {
+		t.Fatalf("ReadOutput() failed: %v", err)
+	}
+	if chunk.Output != "€" || chunk.NextOffset != 3 {
+		t.Errorf("Expected a whole rune, got %+v", chunk)
+	}
+}
+
+func TestOutputStoreForgetsOldest(t *testing.T) {
+	var store outputStore
+	first := store.add("", strings.Repeat("a", maxStoredOutputBytes/2))
+	second := store.add("", strings.Repeat("b", maxStoredOutputBytes/2))
+	third := store.add("", "c")
+
+	if _, ok := store.get("", first); ok {
+		t.Error("Expected the oldest output to be forgotten")
+	}
+	for _, handle := range []string{second, third} {
+		if _, ok := store.get("", handle); !ok {
+			t.Errorf("Expected %s to be kept", handle)
+		}
+	}
+}
+
+func TestReadOutputOtherSession(t *testing.T) {
+	sc, err := NewServerContext(context.Background(), WithOutputLimit(4))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	srv := mcpserver.NewMCPServer("test", "1.0.0")
+	sessionCtx := srv.WithContext(context.Background(), &testSession{})
+	limited := sc.LimitOutput(sessionCtx, "0123456789")
+	if !limited.Truncated {
+		t.Fatalf("Expected the output to be truncated: %+v", limited)
+	}
+
+	// Only the session that produced the output can read it
+	if _, err := sc.ReadOutput(context.Background(), limited.Handle, 0, 0); !errors.Is(err, ErrOutputNotFound) {
+		t.Errorf("Expected ErrOutputNotFound for another session, got %v", err)
+	}
+	chunk, err := sc.ReadOutput(sessionCtx, limited.Handle, 0, 0)
+	if err != nil || chunk.Output != "0123456789" {
+		t.Errorf("Expected the owning session to read the output, got %+v %v", chunk, err)
+	}
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// ProgressNotificationMethod is the MCP notification that reports progress on a request
+const ProgressNotificationMethod = "notifications/progress"
+
+// maxProgressLineLength is the longest line sent in a single progress
+// notification; longer lines are split
+const maxProgressLineLength = 4096
+
+// ProgressWriter returns a writer that sends every line written to it to the
+// client as a notifications/progress message for the tool call. It returns
+// nil if the client did not supply a progress token. Blank lines are skipped,
+// and a final line without a trailing newline is not sent; it is part of the
+// tool result anyway.
+func ProgressWriter(ctx context.Context, request mcp.CallToolRequest) io.Writer {
+	if request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+		return nil
+	}
+	srv := mcpserver.ServerFromContext(ctx)
+	if srv == nil {
+		return nil
+	}
+	return &progressWriter{
+		ctx:    ctx,
+		server: srv,
+		token:  request.Params.Meta.ProgressToken,
+	}
+}
+
+// progressWriter turns output lines into progress notifications
+type progressWriter struct {
+	ctx    context.Context
+	server *mcpserver.MCPServer
+	token  mcp.ProgressToken
+
+	mutex   sync.Mutex
+	partial []byte
+	// progress is the number of notifications sent, which the protocol
+	// requires to increase with every notification
+	progress int
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.partial = append(w.partial, p...)
+	for {
+		line, rest, found := bytes.Cut(w.partial, []byte("\n"))
+		if !found {
+			break
+		}
+		w.send(line)
+		w.partial = rest
+	}
+	for len(w.partial) >= maxProgressLineLength {
+		w.send(w.partial[:maxProgressLineLength])
+		w.partial = w.partial[maxProgressLineLength:]
+	}
+
+	// Copy what is left so the buffer does not keep growing
+	w.partial = append([]byte(nil), w.partial...)
+	return len(p), nil
+}
+
+// send sends a line as progress notifications, split into parts of at most
+// maxProgressLineLength bytes. Notifications are best effort: they are
+// dropped if the client cannot keep up.
+func (w *progressWriter) send(line []byte) {
+	line = bytes.TrimRight(line, "\r")
+	if len(bytes.TrimSpace(line)) == 0 {
+		return
+	}
+	for len(line) > 0 {
+		part := line[:min(len(line), maxProgressLineLength)]
+		line = line[len(part):]
+
+		w.progress++
+		_ = w.server.SendNotificationToClient(w.ctx, ProgressNotificationMethod, map[string]any{
+			"progressToken": w.token,
+			"progress":      w.progress,
+			"message":       string(part),
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// testSession is a client session that collects notifications
+type testSession struct {
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (s *testSession) Initialize()       {}
+func (s *testSession) Initialized() bool { return true }
+func (s *testSession) SessionID() string { return "test-session" }
+func (s *testSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+// callWithProgress calls a tool that writes output to its progress writer and
+// returns the progress notifications sent for it
+func callWithProgress(t *testing.T, output string, meta string) []mcp.JSONRPCNotification {
+	t.Helper()
+
+	srv := mcpserver.NewMCPServer("test", "1.0.0", mcpserver.WithToolCapabilities(true))
+	srv.AddTool(mcp.NewTool("test_progress"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if w := ProgressWriter(ctx, request); w != nil {
+			io.WriteString(w, output)
+		}
+		return mcp.NewToolResultText(output), nil
+	})
+
+	session := &testSession{notifications: make(chan mcp.JSONRPCNotification, 100)}
+	ctx := srv.WithContext(context.Background(), session)
+	message := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"test_progress","arguments":{}` + meta + `}}`
+	if response := srv.HandleMessage(ctx, json.RawMessage(message)); response == nil {
+		t.Fatal("Expected a response")
+	}
+
+	close(session.notifications)
+	var notifications []mcp.JSONRPCNotification
+	for notification := range session.notifications {
+		notifications = append(notifications, notification)
+	}
+	return notifications
+}
+
+func TestProgressWriter(t *testing.T) {
+	notifications := callWithProgress(t, "Copying a.txt\n\nCopying b.txt\r\npartial", `,"_meta":{"progressToken":"token-1"}`)
+
+	if len(notifications) != 2 {
+		t.Fatalf("Expected 2 progress notifications, got %d: %+v", len(notifications), notifications)
+	}
+	for i, expected := range []string{"Copying a.txt", "Copying b.txt"} {
+		notification := notifications[i]
+		if notification.Method != ProgressNotificationMethod {
+			t.Errorf("Method = %q", notification.Method)
+		}
+		fields := notification.Params.AdditionalFields
+		if fields["progressToken"] != "token-1" {
+			t.Errorf("progressToken = %v", fields["progressToken"])
+		}
+		if fields["progress"] != i+1 {
+			t.Errorf("progress = %v, want %d", fields["progress"], i+1)
+		}
+		if fields["message"] != expected {
+			t.Errorf("message = %q, want %q", fields["message"], expected)
+		}
+	}
+}
+
+func TestProgressWriterSplitsLongLines(t *testing.T) {
+	notifications := callWithProgress(t, strings.Repeat("x", maxProgressLineLength+10)+"\n", `,"_meta":{"progressToken":7}`)
+
+	if len(notifications) != 2 {
+		t.Fatalf("Expected the line to be split in 2 notifications, got %d", len(notifications))
+	}
+	if message := notifications[0].Params.AdditionalFields["message"].(string); len(message) != maxProgressLineLength {
+		t.Errorf("Expected the first part to be %d bytes, got %d", maxProgressLineLength, len(message))
+	}
+}
+
+func TestProgressWriterWithoutToken(t *testing.T) {
+	if notifications := callWithProgress(t, "Copying a.txt\n", ""); len(notifications) != 0 {
+		t.Errorf("Expected no progress notifications without a progress token, got %+v", notifications)
+	}
+}
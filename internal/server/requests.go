@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/giantswarm/mcp-teleport/internal/teleport"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// CancelledNotificationMethod is the MCP notification a client sends to cancel an in-flight request
+const CancelledNotificationMethod = "notifications/cancelled"
+
+// requestIDMetaKey is the request metadata key used to hand the JSON-RPC
+// request ID from the before-call hook to the tool handler middleware
+const requestIDMetaKey = "mcp-teleport/requestId"
+
+// errServerShutdown is the cancellation cause for tool calls interrupted by server shutdown
+var errServerShutdown = errors.New("server is shutting down")
+
+// requestTracker maps in-flight tool calls to the functions that cancel them
+type requestTracker struct {
+	mutex   sync.Mutex
+	cancels map[string]context.CancelCauseFunc
+}
+
+func (rt *requestTracker) add(key string, cancel context.CancelCauseFunc) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	if rt.cancels == nil {
+		rt.cancels = make(map[string]context.CancelCauseFunc)
+	}
+	rt.cancels[key] = cancel
+}
+
+func (rt *requestTracker) remove(key string) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	delete(rt.cancels, key)
+}
+
+func (rt *requestTracker) cancel(key string, cause error) bool {
+	rt.mutex.Lock()
+	cancel, ok := rt.cancels[key]
+	rt.mutex.Unlock()
+	if ok {
+		cancel(cause)
+	}
+	return ok
+}
+
+// requestKey identifies a request by client session and JSON-RPC request ID
+func requestKey(ctx context.Context, id any) string {
+	return clientSessionID(ctx) + "/" + mcp.NewRequestId(id).String()
+}
+
+// clientSessionID returns the ID of the MCP session of ctx, or "" outside a session
+func clientSessionID(ctx context.Context) string {
+	if session := mcpserver.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
+
+// TrackToolCall is an OnBeforeCallTool hook that records the JSON-RPC request
+// ID in the request metadata so ToolHandlerMiddleware can register the call
+// for cancellation
+func (sc *ServerContext) TrackToolCall(ctx context.Context, id any, request *mcp.CallToolRequest) {
+	if request.Params.Meta == nil {
+		request.Params.Meta = &mcp.Meta{}
+	}
+	if request.Params.Meta.AdditionalFields == nil {
+		request.Params.Meta.AdditionalFields = make(map[string]any)
+	}
+	request.Params.Meta.AdditionalFields[requestIDMetaKey] = id
+}
+
+// ToolHandlerMiddleware gives every tool call a context that is cancelled when
+// the client sends notifications/cancelled for it or the server shuts down,
+// and that carries the tsh timeout for the call (see ToolTimeout) and, with
+// kubeconfig isolation, the session's kubeconfig
+func (sc *ServerContext) ToolHandlerMiddleware(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		timeout, err := sc.ToolTimeout(request.Params.Name, request.Params.Arguments)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: Invalid timeout: %v", err),
+				}},
+				IsError: true,
+			}, nil
+		}
+		ctx = teleport.ContextWithTimeout(ctx, timeout)
+
+		// Point tsh at the session's private kubeconfig
+		if sc.IsKubeconfigIsolated() {
+			kubeconfig, err := sc.Kubeconfig(ctx)
+			if err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{mcp.TextContent{
+						Type: "text",
+						Text: fmt.Sprintf("Error: %v", err),
+					}},
+					IsError: true,
+				}, nil
+			}
+			ctx = teleport.ContextWithEnv(ctx, "KUBECONFIG="+kubeconfig)
+		}
+
+		ctx, cancel := context.WithCancelCause(ctx)
+		defer cancel(nil)
+
+		stop := context.AfterFunc(sc.Context(), func() {
+			cancel(errServerShutdown)
+		})
+		defer stop()
+
+		if request.Params.Meta != nil {
+			if id, ok := request.Params.Meta.AdditionalFields[requestIDMetaKey]; ok {
+				key := requestKey(ctx, id)
+				sc.requests.add(key, cancel)
+				defer sc.requests.remove(key)
+			}
+		}
+
+		return next(ctx, request)
+	}
+}
+
+// HandleCancelledNotification cancels the tool call named by a notifications/cancelled message
+func (sc *ServerContext) HandleCancelledNotification(ctx context.Context, notification mcp.JSONRPCNotification) {
+	id, ok := notification.Params.AdditionalFields["requestId"]
+	if !ok {
+		return
+	}
+
+	cause := errors.New("cancelled by client")
+	if reason, _ := notification.Params.AdditionalFields["reason"].(string); reason != "" {
+		cause = fmt.Errorf("cancelled by client: %s", reason)
+	}
+
+	if sc.requests.cancel(requestKey(ctx, id), cause) {
+		sc.Logger().Info("Cancelled tool call", "requestId", id)
+	}
+}
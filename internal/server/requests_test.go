@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// blockingHandler signals when it starts and returns the cancellation cause once its context is done
+func blockingHandler(started chan<- struct{}) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		close(started)
+		<-ctx.Done()
+		return mcp.NewToolResultText(context.Cause(ctx).Error()), nil
+	}
+}
+
+func TestCancelledNotificationCancelsToolCall(t *testing.T) {
+	sc, err := NewServerContext(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	var request mcp.CallToolRequest
+	sc.TrackToolCall(context.Background(), float64(7), &request)
+
+	started := make(chan struct{})
+	done := make(chan *mcp.CallToolResult)
+	handler := sc.ToolHandlerMiddleware(blockingHandler(started))
+	go func() {
+		result, _ := handler(context.Background(), request)
+		done <- result
+	}()
+	<-started
+
+	// A notification for a different request must not cancel the call
+	sc.HandleCancelledNotification(context.Background(), mcp.JSONRPCNotification{
+		Notification: mcp.Notification{
+			Method: CancelledNotificationMethod,
+			Params: mcp.NotificationParams{AdditionalFields: map[string]any{"requestId": float64(8)}},
+		},
+	})
+
+	sc.HandleCancelledNotification(context.Background(), mcp.JSONRPCNotification{
+		Notification: mcp.Notification{
+			Method: CancelledNotificationMethod,
+			Params: mcp.NotificationParams{AdditionalFields: map[string]any{
+				"requestId": float64(7),
+				"reason":    "user aborted",
+			}},
+		},
+	})
+
+	result := <-done
+	text := result.Content[0].(mcp.TextContent).Text
+	if text != "cancelled by client: user aborted" {
+		t.Errorf("Expected client cancellation cause, got: %s", text)
+	}
+}
+
+func TestShutdownCancelsToolCall(t *testing.T) {
+	sc, err := NewServerContext(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+
+	started := make(chan struct{})
+	done := make(chan *mcp.CallToolResult)
+	handler := sc.ToolHandlerMiddleware(blockingHandler(started))
+	go func() {
+		result, _ := handler(context.Background(), mcp.CallToolRequest{})
+		done <- result
+	}()
+	<-started
+
+	if err := sc.Shutdown(); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	result := <-done
+	text := result.Content[0].(mcp.TextContent).Text
+	if text != errServerShutdown.Error() {
+		t.Errorf("Expected shutdown cause, got: %s", text)
+	}
+}
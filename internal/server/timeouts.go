@@ -0,0 +1,95 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/giantswarm/mcp-teleport/internal/teleport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// TimeoutParam is the tool argument clients use to request a per-call timeout
+const TimeoutParam = "timeoutSeconds"
+
+// TimeoutOption declares the timeoutSeconds argument on a tool
+func TimeoutOption() mcp.ToolOption {
+	return mcp.WithNumber(TimeoutParam,
+		mcp.Description("Maximum time in seconds the tsh command may run. Defaults to the server's timeout for this tool and is capped by the server's maximum timeout"),
+		mcp.Min(1),
+	)
+}
+
+// ToolTimeout returns the timeout that applies to a call of the named tool.
+// A timeoutSeconds argument takes precedence, capped by the maximum timeout;
+// otherwise the tool's configured timeout or the default timeout is used.
+func (sc *ServerContext) ToolTimeout(tool string, arguments any) (time.Duration, error) {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+
+	timeout := sc.defaultTimeout
+	if d, ok := sc.toolTimeouts[tool]; ok && d > 0 {
+		timeout = d
+	}
+	if timeout <= 0 {
+		timeout = teleport.DefaultTimeout
+	}
+
+	if requested, ok, err := sc.requestedTimeout(arguments); err != nil || ok {
+		return requested, err
+	}
+	return timeout, nil
+}
+
+// JobTimeout returns how long a background job started with the given tool
+// arguments may run. A timeoutSeconds argument takes precedence, capped by
+// the maximum timeout; otherwise the job timeout is used.
+func (sc *ServerContext) JobTimeout(arguments any) (time.Duration, error) {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+
+	timeout := sc.jobTimeout
+	if timeout <= 0 {
+		timeout = DefaultJobTimeout
+	}
+
+	if requested, ok, err := sc.requestedTimeout(arguments); err != nil || ok {
+		return requested, err
+	}
+	return timeout, nil
+}
+
+// requestedTimeout returns the timeout set with the timeoutSeconds argument,
+// capped by the maximum timeout, and whether the argument was set. The caller
+// must hold the read lock.
+func (sc *ServerContext) requestedTimeout(arguments any) (time.Duration, bool, error) {
+	params, _ := arguments.(map[string]interface{})
+	value, ok := params[TimeoutParam]
+	if !ok || value == nil {
+		return 0, false, nil
+	}
+
+	seconds, ok := value.(float64)
+	if !ok {
+		return 0, false, fmt.Errorf("%s must be a number, got %T", TimeoutParam, value)
+	}
+	if seconds <= 0 {
+		return 0, false, fmt.Errorf("%s must be positive, got %v", TimeoutParam, seconds)
+	}
+
+	timeout := time.Duration(seconds * float64(time.Second))
+	if sc.maxTimeout > 0 && timeout > sc.maxTimeout {
+		timeout = sc.maxTimeout
+	}
+	return timeout, true, nil
+}
+
+// CapTimeout limits a timeout the server chose for a call, e.g. from the
+// arguments of teleport_session_observe, to the maximum timeout
+func (sc *ServerContext) CapTimeout(timeout time.Duration) time.Duration {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+	if sc.maxTimeout > 0 && timeout > sc.maxTimeout {
+		return sc.maxTimeout
+	}
+	return timeout
+}
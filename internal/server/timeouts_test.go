@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/mcp-teleport/internal/teleport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestToolTimeout(t *testing.T) {
+	sc, err := NewServerContext(context.Background(),
+		WithDefaultTimeout(20*time.Second),
+		WithToolTimeouts(map[string]time.Duration{"teleport_scp": 10 * time.Minute}),
+		WithMaxTimeout(15*time.Minute),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	tests := []struct {
+		name      string
+		tool      string
+		arguments any
+		expected  time.Duration
+		wantErr   bool
+	}{
+		{name: "default timeout", tool: "teleport_ssh", expected: 20 * time.Second},
+		{name: "tool timeout", tool: "teleport_scp", arguments: map[string]interface{}{}, expected: 10 * time.Minute},
+		{name: "argument overrides tool timeout", tool: "teleport_scp", arguments: map[string]interface{}{TimeoutParam: float64(90)}, expected: 90 * time.Second},
+		{name: "fractional seconds", tool: "teleport_ssh", arguments: map[string]interface{}{TimeoutParam: 1.5}, expected: 1500 * time.Millisecond},
+		{name: "argument capped by maximum", tool: "teleport_ssh", arguments: map[string]interface{}{TimeoutParam: float64(3600)}, expected: 15 * time.Minute},
+		{name: "zero", tool: "teleport_ssh", arguments: map[string]interface{}{TimeoutParam: float64(0)}, wantErr: true},
+		{name: "negative", tool: "teleport_ssh", arguments: map[string]interface{}{TimeoutParam: float64(-5)}, wantErr: true},
+		{name: "not a number", tool: "teleport_ssh", arguments: map[string]interface{}{TimeoutParam: "60"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeout, err := sc.ToolTimeout(tt.tool, tt.arguments)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got timeout %v", timeout)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if timeout != tt.expected {
+				t.Errorf("Expected timeout %v, got %v", tt.expected, timeout)
+			}
+		})
+	}
+}
+
+func TestToolTimeoutDefault(t *testing.T) {
+	sc, err := NewServerContext(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	timeout, err := sc.ToolTimeout("teleport_ssh", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if timeout != teleport.DefaultTimeout {
+		t.Errorf("Expected %v, got %v", teleport.DefaultTimeout, timeout)
+	}
+}
+
+func TestJobTimeout(t *testing.T) {
+	sc, err := NewServerContext(context.Background(),
+		WithDefaultTimeout(20*time.Second),
+		WithJobTimeout(2*time.Hour),
+		WithMaxTimeout(15*time.Minute),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	timeout, err := sc.JobTimeout(map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if timeout != 2*time.Hour {
+		t.Errorf("Expected the job timeout, got %v", timeout)
+	}
+
+	timeout, err = sc.JobTimeout(map[string]interface{}{TimeoutParam: float64(3600)})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if timeout != 15*time.Minute {
+		t.Errorf("Expected the argument capped by the maximum, got %v", timeout)
+	}
+
+	if _, err := sc.JobTimeout(map[string]interface{}{TimeoutParam: "60"}); err == nil {
+		t.Error("Expected an error for a non-numeric timeout")
+	}
+}
+
+func TestToolHandlerMiddlewareTimeout(t *testing.T) {
+	sc, err := NewServerContext(context.Background(), WithRunner(blockingRunner{}))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	handler := sc.ToolHandlerMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result := sc.TeleportClient().ExecuteCommandContext(ctx, "ls", nil)
+		return mcp.NewToolResultText(result.ErrorMessage), nil
+	})
+
+	var request mcp.CallToolRequest
+	request.Params.Name = "teleport_list_ssh_nodes"
+	request.Params.Arguments = map[string]interface{}{TimeoutParam: 0.05}
+	result, err := handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.HasPrefix(text, "Command timeout after 50ms") {
+		t.Errorf("Expected the applied timeout in the error message, got: %s", text)
+	}
+
+	request.Params.Arguments = map[string]interface{}{TimeoutParam: float64(-1)}
+	result, err = handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !result.IsError {
+		t.Errorf("Expected an invalid timeout to be rejected, got: %+v", result)
+	}
+}
+
+// blockingRunner runs commands that never finish on their own
+type blockingRunner struct{}
+
+func (blockingRunner) Run(ctx context.Context, cmd teleport.Command) (int, error) {
+	<-ctx.Done()
+	return -1, ctx.Err()
+}
@@ -0,0 +1,93 @@
+package teleport
+
+import (
+	"fmt"
+	"sync"
+)
+
+// captureBuffer collects command output. With a limit it keeps the first and
+// the last limit/2 bytes and drops the middle, so a command that prints a lot
+// cannot exhaust memory. It is safe for concurrent use.
+type captureBuffer struct {
+	mutex sync.Mutex
+	// limit is the number of bytes kept; zero keeps everything
+	limit int
+	head  []byte
+	// tail is a ring buffer holding the last bytes written after head filled up
+	tail        []byte
+	tailPos     int
+	tailWritten int64
+}
+
+func newCaptureBuffer(limit int) *captureBuffer {
+	return &captureBuffer{limit: limit}
+}
+
+func (b *captureBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	n := len(p)
+	if b.limit <= 0 {
+		b.head = append(b.head, p...)
+		return n, nil
+	}
+
+	if headLimit := b.limit / 2; len(b.head) < headLimit {
+		count := min(headLimit-len(b.head), len(p))
+		b.head = append(b.head, p[:count]...)
+		p = p[count:]
+	}
+	if len(p) == 0 {
+		return n, nil
+	}
+
+	tailLimit := b.limit - b.limit/2
+	if b.tail == nil {
+		b.tail = make([]byte, 0, tailLimit)
+	}
+	b.tailWritten += int64(len(p))
+	if len(p) > tailLimit {
+		p = p[len(p)-tailLimit:]
+	}
+	for len(p) > 0 {
+		if len(b.tail) < tailLimit {
+			// The ring is not full yet
+			count := min(tailLimit-len(b.tail), len(p))
+			b.tail = append(b.tail, p[:count]...)
+			p = p[count:]
+			continue
+		}
+		count := copy(b.tail[b.tailPos:], p)
+		b.tailPos = (b.tailPos + count) % tailLimit
+		p = p[count:]
+	}
+	return n, nil
+}
+
+// dropped returns the number of bytes dropped from the middle of the output
+func (b *captureBuffer) dropped() int64 {
+	if over := b.tailWritten - int64(len(b.tail)); over > 0 {
+		return over
+	}
+	return 0
+}
+
+// Truncated reports whether output was dropped
+func (b *captureBuffer) Truncated() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.dropped() > 0
+}
+
+// String returns the captured output, with a marker where output was dropped
+func (b *captureBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	tail := string(b.tail[b.tailPos:]) + string(b.tail[:b.tailPos])
+	if dropped := b.dropped(); dropped > 0 {
+		return fmt.Sprintf("%s\n... [%d bytes dropped] ...\n%s", b.head, dropped, tail)
+	}
+	return string(b.head) + tail
+}
@@ -2,24 +2,103 @@ package teleport
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os/exec"
+	"io"
 	"strings"
 	"time"
 )
 
+// DefaultTimeout is how long a tsh command may run unless configured otherwise
+const DefaultTimeout = 30 * time.Second
+
 // Client wraps the tsh CLI for executing Teleport commands
 type Client struct {
 	dryRun    bool
 	debugMode bool
+	runner    Runner
+	timeout   time.Duration
+	// captureLimit is how many bytes of each output stream are kept
+	captureLimit int
+}
+
+// ClientOption is a functional option for configuring a Client
+type ClientOption func(*Client)
+
+// WithRunner sets the runner used to execute tsh; nil keeps the default ExecRunner
+func WithRunner(runner Runner) ClientOption {
+	return func(c *Client) {
+		if runner != nil {
+			c.runner = runner
+		}
+	}
+}
+
+// WithTimeout sets how long a command may run; zero or negative keeps DefaultTimeout
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		if timeout > 0 {
+			c.timeout = timeout
+		}
+	}
+}
+
+// WithCaptureLimit caps how many bytes of output are kept per command. The
+// head and tail of longer output are kept and the middle is dropped. Zero or
+// negative keeps all output.
+func WithCaptureLimit(limit int) ClientOption {
+	return func(c *Client) {
+		if limit > 0 {
+			c.captureLimit = limit
+		}
+	}
+}
+
+// timeoutKey is the context key for per-call timeouts
+type timeoutKey struct{}
+
+// ContextWithTimeout returns a context that makes ExecuteCommandContext use
+// timeout instead of the client's timeout. It is used to apply per-call timeouts.
+func ContextWithTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, timeoutKey{}, timeout)
+}
+
+// envKey is the context key for per-call environment variables
+type envKey struct{}
+
+// ContextWithEnv returns a context that makes the client run commands with
+// the given NAME=value environment variables in addition to its own
+// environment, e.g. to point tsh at a private kubeconfig.
+func ContextWithEnv(ctx context.Context, env ...string) context.Context {
+	return context.WithValue(ctx, envKey{}, append(envFrom(ctx), env...))
+}
+
+// ContextWithEnvFrom returns ctx with the per-call environment variables of
+// from, e.g. for background work that outlives the call it was started by.
+func ContextWithEnvFrom(ctx, from context.Context) context.Context {
+	return ContextWithEnv(ctx, envFrom(from)...)
+}
+
+// envFrom returns the per-call environment variables from ctx
+func envFrom(ctx context.Context) []string {
+	env, _ := ctx.Value(envKey{}).([]string)
+	return env[:len(env):len(env)]
 }
 
 // NewClient creates a new Teleport client
-func NewClient(dryRun, debugMode bool) *Client {
-	return &Client{
+func NewClient(dryRun, debugMode bool, opts ...ClientOption) *Client {
+	c := &Client{
 		dryRun:    dryRun,
 		debugMode: debugMode,
+		runner:    ExecRunner{},
+		timeout:   DefaultTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(c)
 	}
+
+	return c
 }
 
 // ExecutionResult represents the result of a command execution
@@ -28,67 +107,149 @@ type ExecutionResult struct {
 	Output       string `json:"output"`
 	ErrorMessage string `json:"errorMessage,omitempty"`
 	StatusCode   int    `json:"statusCode,omitempty"`
+	// Cancelled is set when the command was stopped because its context was cancelled
+	Cancelled bool `json:"cancelled,omitempty"`
+	// TimedOut is set when the command was stopped because it exceeded its timeout
+	TimedOut bool `json:"timedOut,omitempty"`
+	// Stdout and Stderr hold the two streams that are interleaved in Output.
+	// Parse machine-readable output (--format json) from Stdout only, since
+	// tsh prints warnings and prompts to stderr.
+	Stdout string `json:"stdout,omitempty"`
+	Stderr string `json:"stderr,omitempty"`
+	// Truncated is set when output beyond the capture limit was dropped from
+	// the middle of Output, Stdout or Stderr
+	Truncated bool `json:"truncated,omitempty"`
 }
 
 // ExecuteCommand executes a tsh command with the given arguments
 func (c *Client) ExecuteCommand(command string, args []string) *ExecutionResult {
+	return c.ExecuteCommandContext(context.Background(), command, args)
+}
+
+// ExecuteCommandContext executes a tsh command with the given arguments. The
+// command and any processes it started are killed as soon as ctx is cancelled
+// or the timeout expires. The timeout is taken from ctx if it was set with
+// ContextWithTimeout, and from the client otherwise.
+func (c *Client) ExecuteCommandContext(ctx context.Context, command string, args []string) *ExecutionResult {
 	// Build the full command - split command into separate arguments
 	var cmdArgs []string
-	
+
 	// Split the command string into individual arguments
 	commandParts := strings.Fields(command)
 	cmdArgs = append(cmdArgs, commandParts...)
 	cmdArgs = append(cmdArgs, args...)
 
-	fullCommand := fmt.Sprintf("tsh %s", strings.Join(cmdArgs, " "))
+	return c.execute(ctx, "tsh", cmdArgs, nil, nil, nil)
+}
+
+// ExecuteCommandInputContext executes a tsh command like ExecuteCommandContext
+// and feeds stdin to it, e.g. file content for tsh ssh to pass on to a remote
+// command.
+func (c *Client) ExecuteCommandInputContext(ctx context.Context, command string, args []string, stdin io.Reader) *ExecutionResult {
+	cmdArgs := append(strings.Fields(command), args...)
+	return c.execute(ctx, "tsh", cmdArgs, stdin, nil, nil)
+}
+
+// StreamCommandContext executes a tsh command like ExecuteCommandContext and
+// additionally copies stdout and stderr to the given writers while the
+// command runs. Either writer may be nil; a writer passed for both streams
+// must be safe for concurrent use.
+func (c *Client) StreamCommandContext(ctx context.Context, command string, args []string, stdout, stderr io.Writer) *ExecutionResult {
+	cmdArgs := append(strings.Fields(command), args...)
+	return c.execute(ctx, "tsh", cmdArgs, nil, stdout, stderr)
+}
+
+// ExecuteProgramContext executes a program other than tsh, such as a database
+// client connected through a tunnel, with the same dry-run, timeout and
+// cancellation handling as ExecuteCommandContext.
+func (c *Client) ExecuteProgramContext(ctx context.Context, name string, args []string) *ExecutionResult {
+	return c.execute(ctx, name, args, nil, nil, nil)
+}
+
+// execute runs name with args through the client's runner, feeding it stdin
+// and copying stdout and stderr to stdoutStream and stderrStream as well if
+// they are not nil
+func (c *Client) execute(ctx context.Context, name string, cmdArgs []string, stdin io.Reader, stdoutStream, stderrStream io.Writer) *ExecutionResult {
+	fullCommand := fmt.Sprintf("%s %s", name, strings.Join(cmdArgs, " "))
 
 	if c.dryRun {
+		output := fmt.Sprintf("DRY RUN: Would execute: %s", fullCommand)
+		if stdoutStream != nil {
+			io.WriteString(stdoutStream, output)
+		}
 		return &ExecutionResult{
 			Success:    true,
-			Output:     fmt.Sprintf("DRY RUN: Would execute: %s", fullCommand),
+			Output:     output,
 			StatusCode: 0,
+			Stdout:     output,
 		}
 	}
 
 	// Create context with timeout to prevent hanging
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	timeout := c.timeoutFor(ctx)
+	execCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	// Execute the command
-	cmd := exec.CommandContext(ctx, "tsh", cmdArgs...)
-	output, err := cmd.CombinedOutput()
-
-	if err != nil {
-		var statusCode int
-		if exitError, ok := err.(*exec.ExitError); ok {
-			statusCode = exitError.ExitCode()
-		} else {
-			statusCode = 1
-		}
+	// Execute the command, capturing stdout and stderr in their original order
+	// as well as separately
+	output := newCaptureBuffer(c.captureLimit)
+	stdout := newCaptureBuffer(c.captureLimit)
+	stderr := newCaptureBuffer(c.captureLimit)
+	stdoutWriters := []io.Writer{output, stdout}
+	stderrWriters := []io.Writer{output, stderr}
+	if stdoutStream != nil {
+		stdoutWriters = append(stdoutWriters, stdoutStream)
+	}
+	if stderrStream != nil {
+		stderrWriters = append(stderrWriters, stderrStream)
+	}
+	statusCode, err := c.runner.Run(execCtx, Command{
+		Name:   name,
+		Args:   cmdArgs,
+		Env:    envFrom(ctx),
+		Stdin:  stdin,
+		Stdout: io.MultiWriter(stdoutWriters...),
+		Stderr: io.MultiWriter(stderrWriters...),
+	})
+	if err == nil && statusCode != 0 {
+		err = fmt.Errorf("exit status %d", statusCode)
+	}
 
-		// If context was cancelled, it was a timeout
-		if ctx.Err() == context.DeadlineExceeded {
-			return &ExecutionResult{
-				Success:      false,
-				Output:       string(output),
-				ErrorMessage: fmt.Sprintf("Command timeout after 30 seconds: %s", err.Error()),
-				StatusCode:   statusCode,
-			}
-		}
+	result := &ExecutionResult{
+		Output:     output.String(),
+		StatusCode: statusCode,
+		Stdout:     stdout.String(),
+		Stderr:     stderr.String(),
+		Truncated:  output.Truncated() || stdout.Truncated() || stderr.Truncated(),
+	}
 
-		return &ExecutionResult{
-			Success:      false,
-			Output:       string(output),
-			ErrorMessage: err.Error(),
-			StatusCode:   statusCode,
+	if err != nil {
+		switch {
+		// If the timeout expired, it was a timeout
+		case errors.Is(execCtx.Err(), context.DeadlineExceeded):
+			result.ErrorMessage = fmt.Sprintf("Command timeout after %s: %s", timeout, err.Error())
+			result.TimedOut = true
+		// If the caller's context was cancelled, the command was aborted
+		case ctx.Err() != nil:
+			result.ErrorMessage = fmt.Sprintf("Command cancelled: %v", context.Cause(ctx))
+			result.Cancelled = true
+		default:
+			result.ErrorMessage = err.Error()
 		}
+		return result
 	}
 
-	return &ExecutionResult{
-		Success:    true,
-		Output:     string(output),
-		StatusCode: 0,
+	result.Success = true
+	result.StatusCode = 0
+	return result
+}
+
+// timeoutFor returns the per-call timeout from ctx, or the client's timeout
+func (c *Client) timeoutFor(ctx context.Context) time.Duration {
+	if d, ok := ctx.Value(timeoutKey{}).(time.Duration); ok && d > 0 {
+		return d
 	}
+	return c.timeout
 }
 
 // FormatArgs formats command arguments from parameters
@@ -151,14 +312,37 @@ func mapParameterToFlag(param string) string {
 	// Phase 1 enhanced parameters - exclude these from FormatArgs as they are handled separately
 	case "search", "query", "labels", "verbose", "all", "cluster":
 		return ""
-	case "localForward", "remoteForward", "dynamicForward", "openSSHOptions", "localCommand", "noRemoteExec", "logDir", "tty":
+	case "localForward", "remoteForward", "dynamicForward", "openSSHOptions", "localCommand", "noRemoteExec", "logDir", "tty", "forwardAgent":
 		return ""
-	case "source", "destination", "recursive", "preserveAttributes", "quiet", "port":
+	case "source", "destination", "command", "recursive", "preserveAttributes", "quiet", "port", "concurrency":
 		return ""
 	case "host":
 		return ""
+	// Database-specific parameters - exclude these from FormatArgs as they are handled separately
+	case "db", "dbUser", "dbName", "dbRoles", "sql", "protocol", "maxRows":
+		return ""
+	// Application-specific parameters - exclude these from FormatArgs as they are handled separately
+	case "app", "awsRole", "azureIdentity", "gcpServiceAccount", "method", "path", "headers", "body", "maxBodyBytes":
+		return ""
+	// Access request parameters - exclude these from FormatArgs as they are handled separately
+	case "kind", "roles", "resources", "reason", "reviewers", "requestId", "state", "reviewable":
+		return ""
+	// Session and recording parameters - exclude these from FormatArgs as they are handled separately
+	case "from", "to", "limit", "sessionId", "durationSeconds":
+		return ""
+	// Remote file parameters - exclude these from FormatArgs as they are handled separately
+	case "length", "startLine", "endLine", "encoding", "content", "backup", "verify", "maxEntries":
+		return ""
+	// Per-call execution settings handled by the server, not tsh
+	case "timeoutSeconds", "background":
+		return ""
+	// Background job and output paging parameters - exclude these from FormatArgs as they are handled separately
+	case "jobId", "offset", "maxBytes", "handle":
+		return ""
 	// Kubernetes-specific parameters - exclude these from FormatArgs as they are handled separately
-	case "kubeCluster", "asUser", "asGroups", "kubeNamespace", "contextName", "requestReason", "disableAccessRequest":
+	case "kubeCluster", "asUser", "asGroups", "kubeNamespace", "contextName", "requestReason", "disableAccessRequest", "restoreContext":
+		return ""
+	case "name", "namespace", "allNamespaces", "labelSelector", "fieldSelector", "pod", "container", "tail", "since", "previous", "timestamps", "type", "permissions":
 		return ""
 	default:
 		// Remove "Param" suffix if present
@@ -1,7 +1,12 @@
 package teleport
 
 import (
+	"context"
+	"errors"
+	"io"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewClient(t *testing.T) {
@@ -125,3 +130,222 @@ func TestMapParameterToFlag(t *testing.T) {
 		})
 	}
 }
+
+func TestExecuteCommandContextCancelled(t *testing.T) {
+	client := NewClient(false, false)
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(errors.New("cancelled by client"))
+
+	result := client.ExecuteCommandContext(ctx, "status", []string{})
+
+	if result.Success {
+		t.Error("Expected cancelled command to fail")
+	}
+	if !result.Cancelled {
+		t.Error("Expected result to be marked as cancelled")
+	}
+	if result.TimedOut {
+		t.Error("Expected result not to be marked as timed out")
+	}
+	if !strings.Contains(result.ErrorMessage, "cancelled by client") {
+		t.Errorf("Expected cancellation cause in error message, got: %s", result.ErrorMessage)
+	}
+}
+
+// scriptedRunner is a minimal Runner for testing the client without tsh
+type scriptedRunner struct {
+	stdout   string
+	stderr   string
+	exitCode int
+	argv     []string
+	stdin    string
+	env      []string
+}
+
+func (r *scriptedRunner) Run(ctx context.Context, cmd Command) (int, error) {
+	r.argv = append([]string{cmd.Name}, cmd.Args...)
+	r.env = cmd.Env
+	if cmd.Stdin != nil {
+		input, _ := io.ReadAll(cmd.Stdin)
+		r.stdin = string(input)
+	}
+	cmd.Stdout.Write([]byte(r.stdout))
+	cmd.Stderr.Write([]byte(r.stderr))
+	return r.exitCode, nil
+}
+
+func TestExecuteCommandWithRunner(t *testing.T) {
+	runner := &scriptedRunner{stdout: "ok\n"}
+	client := NewClient(false, false, WithRunner(runner))
+
+	result := client.ExecuteCommandContext(context.Background(), "kube ls", []string{"--format", "json"})
+
+	if !result.Success {
+		t.Errorf("Expected success, got: %+v", result)
+	}
+	if result.Output != "ok\n" {
+		t.Errorf("Expected output %q, got %q", "ok\n", result.Output)
+	}
+	if strings.Join(runner.argv, " ") != "tsh kube ls --format json" {
+		t.Errorf("Unexpected argv: %v", runner.argv)
+	}
+}
+
+func TestExecuteCommandWithRunnerFailure(t *testing.T) {
+	runner := &scriptedRunner{stderr: "ERROR: not logged in\n", exitCode: 2}
+	client := NewClient(false, false, WithRunner(runner))
+
+	result := client.ExecuteCommandContext(context.Background(), "status", nil)
+
+	if result.Success {
+		t.Error("Expected failure for non-zero exit code")
+	}
+	if result.StatusCode != 2 {
+		t.Errorf("Expected status code 2, got %d", result.StatusCode)
+	}
+	if result.ErrorMessage != "exit status 2" {
+		t.Errorf("Expected exit status error message, got %q", result.ErrorMessage)
+	}
+	if !strings.Contains(result.Output, "not logged in") {
+		t.Errorf("Expected stderr in output, got %q", result.Output)
+	}
+}
+
+func TestExecuteCommandSeparatesStreams(t *testing.T) {
+	runner := &scriptedRunner{stdout: "Linux\n", stderr: "WARNING: agent forwarding disabled\n", exitCode: 1}
+	client := NewClient(false, false, WithRunner(runner))
+
+	result := client.ExecuteCommandContext(context.Background(), "ssh", []string{"root@node", "uname"})
+
+	if result.Stdout != "Linux\n" {
+		t.Errorf("Stdout = %q", result.Stdout)
+	}
+	if result.Stderr != "WARNING: agent forwarding disabled\n" {
+		t.Errorf("Stderr = %q", result.Stderr)
+	}
+	if result.Output != result.Stdout+result.Stderr {
+		t.Errorf("Output = %q, want both streams", result.Output)
+	}
+}
+
+func TestStreamCommandContext(t *testing.T) {
+	runner := &scriptedRunner{stdout: "copied 1 file\n", stderr: "WARNING: slow link\n"}
+	client := NewClient(false, false, WithRunner(runner))
+
+	var stream lockedBuffer
+	result := client.StreamCommandContext(context.Background(), "scp", []string{"a", "b"}, &stream, &stream)
+
+	if !result.Success {
+		t.Fatalf("Expected success, got: %+v", result)
+	}
+	if stream.String() != result.Output {
+		t.Errorf("Streamed %q, want the full output %q", stream.String(), result.Output)
+	}
+	if result.Stdout != "copied 1 file\n" {
+		t.Errorf("Stdout = %q", result.Stdout)
+	}
+
+	// Streaming only stdout leaves out stderr
+	var stdout lockedBuffer
+	client.StreamCommandContext(context.Background(), "scp", []string{"a", "b"}, &stdout, nil)
+	if stdout.String() != "copied 1 file\n" {
+		t.Errorf("Streamed %q, want stdout only", stdout.String())
+	}
+}
+
+func TestExecuteCommandInputContext(t *testing.T) {
+	runner := &scriptedRunner{}
+	client := NewClient(false, false, WithRunner(runner))
+
+	result := client.ExecuteCommandInputContext(context.Background(), "ssh", []string{"root@node", "cat > /etc/motd"}, strings.NewReader("hello\n"))
+
+	if !result.Success {
+		t.Fatalf("Expected success, got: %+v", result)
+	}
+	if runner.stdin != "hello\n" {
+		t.Errorf("Expected the input on stdin, got %q", runner.stdin)
+	}
+}
+
+// blockingRunner runs commands that never finish on their own
+type blockingRunner struct{}
+
+func (blockingRunner) Run(ctx context.Context, cmd Command) (int, error) {
+	<-ctx.Done()
+	return -1, ctx.Err()
+}
+
+func TestExecuteCommandTimeout(t *testing.T) {
+	client := NewClient(false, false, WithRunner(blockingRunner{}), WithTimeout(20*time.Millisecond))
+
+	result := client.ExecuteCommandContext(context.Background(), "ls", nil)
+	if !result.TimedOut {
+		t.Fatalf("Expected timeout, got: %+v", result)
+	}
+	if !strings.HasPrefix(result.ErrorMessage, "Command timeout after 20ms") {
+		t.Errorf("Expected the client timeout in the error message, got %q", result.ErrorMessage)
+	}
+
+	// A per-call timeout from the context takes precedence
+	ctx := ContextWithTimeout(context.Background(), 10*time.Millisecond)
+	result = client.ExecuteCommandContext(ctx, "ls", nil)
+	if !strings.HasPrefix(result.ErrorMessage, "Command timeout after 10ms") {
+		t.Errorf("Expected the per-call timeout in the error message, got %q", result.ErrorMessage)
+	}
+}
+
+func TestExecuteCommandEnv(t *testing.T) {
+	runner := &scriptedRunner{}
+	client := NewClient(false, false, WithRunner(runner))
+
+	client.ExecuteCommandContext(context.Background(), "kube ls", nil)
+	if len(runner.env) != 0 {
+		t.Errorf("Expected no extra environment, got %v", runner.env)
+	}
+
+	ctx := ContextWithEnv(context.Background(), "KUBECONFIG=/tmp/mcp/stdio.yaml")
+	ctx = ContextWithEnv(ctx, "TELEPORT_DEBUG=1")
+	client.ExecuteCommandContext(ctx, "kube ls", nil)
+	if strings.Join(runner.env, " ") != "KUBECONFIG=/tmp/mcp/stdio.yaml TELEPORT_DEBUG=1" {
+		t.Errorf("Unexpected environment: %v", runner.env)
+	}
+}
+
+func TestExecuteCommandCaptureLimit(t *testing.T) {
+	runner := &scriptedRunner{stdout: "head-" + strings.Repeat("x", 100) + "-tail"}
+	client := NewClient(false, false, WithRunner(runner), WithCaptureLimit(20))
+
+	result := client.ExecuteCommandContext(context.Background(), "ssh", []string{"root@node", "cat big.log"})
+
+	if !result.Truncated {
+		t.Error("Expected the result to be marked as truncated")
+	}
+	if result.Stdout != "head-xxxxx\n... [90 bytes dropped] ...\nxxxxx-tail" {
+		t.Errorf("Stdout = %q", result.Stdout)
+	}
+}
+
+func TestCaptureBuffer(t *testing.T) {
+	buffer := newCaptureBuffer(8)
+	for _, chunk := range []string{"ab", "cd", "ef", "gh", "ij", "klmnop", "q"} {
+		buffer.Write([]byte(chunk))
+	}
+	if got := buffer.String(); got != "abcd\n... [9 bytes dropped] ...\nnopq" {
+		t.Errorf("String() = %q", got)
+	}
+
+	// Output within the limit is kept as is
+	buffer = newCaptureBuffer(8)
+	buffer.Write([]byte("abcdefgh"))
+	if buffer.Truncated() || buffer.String() != "abcdefgh" {
+		t.Errorf("String() = %q, Truncated() = %v", buffer.String(), buffer.Truncated())
+	}
+
+	// Without a limit everything is kept
+	buffer = newCaptureBuffer(0)
+	buffer.Write([]byte(strings.Repeat("x", 1000)))
+	if len(buffer.String()) != 1000 {
+		t.Errorf("Expected all output to be kept, got %d bytes", len(buffer.String()))
+	}
+}
@@ -11,7 +11,11 @@
 // construction, execution, and result parsing.
 //
 // ExecutionResult: A structured representation of command execution results
-// including success status, output, and error information.
+// including success status, output, and error information. Output interleaves
+// stdout and stderr for display; Stdout and Stderr hold the streams
+// separately, and JSON output must be parsed from Stdout only. WithCaptureLimit
+// bounds how much of each stream is kept; beyond it the middle of the output
+// is dropped and Truncated is set.
 //
 // # Usage
 //
@@ -25,6 +29,52 @@
 //	    fmt.Println("Command failed:", result.ErrorMessage)
 //	}
 //
+// Pass the request context so the tsh process and its children are killed
+// when the caller cancels:
+//
+//	result := client.ExecuteCommandContext(ctx, "ls", []string{"--format", "json"})
+//	if result.Cancelled {
+//	    fmt.Println("Command was cancelled:", result.ErrorMessage)
+//	}
+//
+// Commands are killed after DefaultTimeout unless the client was created with
+// WithTimeout; ContextWithTimeout overrides the timeout for a single call:
+//
+//	ctx = teleport.ContextWithTimeout(ctx, 10*time.Minute)
+//	result := client.ExecuteCommandContext(ctx, "scp", args)
+//	if result.TimedOut {
+//	    fmt.Println(result.ErrorMessage) // "Command timeout after 10m0s: ..."
+//	}
+//
+// StreamCommandContext additionally copies the output to a writer while the
+// command runs, e.g. to follow a long-running background job:
+//
+//	result := client.StreamCommandContext(ctx, "ssh", []string{"root@node", "journalctl -f"}, output, output)
+//
+// ExecuteCommandInputContext feeds a reader to the command's stdin, e.g. to
+// write a file on a remote host:
+//
+//	result := client.ExecuteCommandInputContext(ctx, "ssh", []string{"root@node", "cat > /etc/motd"}, strings.NewReader("hello\n"))
+//
+// StartDBTunnel opens a local authenticated tunnel to a database with
+// tsh proxy db --tunnel, and ExecuteProgramContext runs a database client
+// against it:
+//
+//	tunnel, err := client.StartDBTunnel(ctx, "orders", []string{"--db-user", "readonly"})
+//	if err != nil {
+//	    return err
+//	}
+//	defer tunnel.Close()
+//	result := client.ExecuteProgramContext(ctx, "psql", []string{"--command", "SELECT 1", "postgres://readonly@" + tunnel.Addr + "/orders?sslmode=disable"})
+//
+// ParseStatus turns tsh status --format=json output into a typed Status:
+//
+//	result := client.ExecuteCommandContext(ctx, "status", []string{"--format", "json"})
+//	status, err := teleport.ParseStatus(result.Stdout)
+//	if err == nil && status.Active != nil && status.Active.ExpiresWithin(time.Now(), time.Hour) {
+//	    fmt.Println("Certificates expire at", status.Active.ValidUntil)
+//	}
+//
 // Format arguments from parameters:
 //
 //	params := map[string]interface{}{
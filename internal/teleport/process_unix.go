@@ -0,0 +1,21 @@
+//go:build !windows
+
+package teleport
+
+import (
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// waitDelay bounds how long Wait blocks on output pipes held open by orphaned children
+const waitDelay = 5 * time.Second
+
+// configureProcessGroup starts cmd in a new process group and makes
+// cancellation kill the whole group, including processes tsh spawned
+func configureProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build windows
+
+package teleport
+
+import (
+	"os/exec"
+	"time"
+)
+
+// waitDelay bounds how long Wait blocks on output pipes held open by orphaned children
+const waitDelay = 5 * time.Second
+
+// configureProcessGroup is a no-op on Windows, where cancellation kills the tsh process itself
+func configureProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,53 @@
+package teleport
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// Command describes a single process invocation
+type Command struct {
+	Name string
+	Args []string
+	// Env holds NAME=value variables added to the environment of the command
+	Env []string
+	// Stdin is fed to the command if it is not nil
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Runner executes commands on behalf of the Client. The default ExecRunner
+// starts real processes; tests inject a fake such as tshtest.Runner.
+type Runner interface {
+	// Run executes cmd and returns its exit code. A non-nil error means the
+	// command could not be started, was killed or exited unsuccessfully.
+	Run(ctx context.Context, cmd Command) (int, error)
+}
+
+// ExecRunner runs commands as local processes
+type ExecRunner struct{}
+
+// Run starts cmd in its own process group and waits for it to finish. The
+// whole process group is killed as soon as ctx is cancelled.
+func (ExecRunner) Run(ctx context.Context, c Command) (int, error) {
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	configureProcessGroup(cmd)
+	cmd.WaitDelay = waitDelay
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+	cmd.Stdin = c.Stdin
+	cmd.Stdout = c.Stdout
+	cmd.Stderr = c.Stderr
+
+	if err := cmd.Run(); err != nil {
+		if exitError, ok := err.(*exec.ExitError); ok {
+			return exitError.ExitCode(), err
+		}
+		return 1, err
+	}
+	return 0, nil
+}
@@ -0,0 +1,56 @@
+package teleport
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Status is the output of tsh status --format=json
+type Status struct {
+	// Active is the profile of the cluster tsh is currently logged into, if any
+	Active *Profile `json:"active,omitempty"`
+	// Profiles are the other profiles tsh has certificates for
+	Profiles []Profile `json:"profiles"`
+}
+
+// Profile is a tsh login profile as reported by tsh status
+type Profile struct {
+	ProxyURL          string              `json:"profile_url"`
+	Username          string              `json:"username"`
+	Cluster           string              `json:"cluster"`
+	Roles             []string            `json:"roles,omitempty"`
+	Traits            map[string][]string `json:"traits,omitempty"`
+	Logins            []string            `json:"logins,omitempty"`
+	KubernetesEnabled bool                `json:"kubernetes_enabled"`
+	KubernetesCluster string              `json:"kubernetes_cluster,omitempty"`
+	KubernetesUsers   []string            `json:"kubernetes_users,omitempty"`
+	KubernetesGroups  []string            `json:"kubernetes_groups,omitempty"`
+	Databases         []string            `json:"databases,omitempty"`
+	ActiveRequests    []string            `json:"active_requests,omitempty"`
+	ValidUntil        time.Time           `json:"valid_until"`
+	Extensions        []string            `json:"extensions,omitempty"`
+}
+
+// ParseStatus parses the output of tsh status --format=json
+func ParseStatus(output string) (*Status, error) {
+	var status Status
+	if err := json.Unmarshal([]byte(output), &status); err != nil {
+		return nil, fmt.Errorf("failed to parse tsh status output: %w", err)
+	}
+	if status.Profiles == nil {
+		status.Profiles = []Profile{}
+	}
+	return &status, nil
+}
+
+// Expired reports whether the profile certificates are no longer valid at now
+func (p *Profile) Expired(now time.Time) bool {
+	return !now.Before(p.ValidUntil)
+}
+
+// ExpiresWithin reports whether the profile certificates are still valid at
+// now but expire within window
+func (p *Profile) ExpiresWithin(now time.Time, window time.Duration) bool {
+	return !p.Expired(now) && p.ValidUntil.Sub(now) <= window
+}
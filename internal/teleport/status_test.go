@@ -0,0 +1,92 @@
+package teleport
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseStatus(t *testing.T) {
+	data, err := os.ReadFile("testdata/tsh_status.json")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	status, err := ParseStatus(string(data))
+	if err != nil {
+		t.Fatalf("ParseStatus() error = %v", err)
+	}
+
+	active := status.Active
+	if active == nil {
+		t.Fatal("ParseStatus() returned no active profile")
+	}
+	if active.Username != "alice@example.com" || active.Cluster != "example.com" || active.ProxyURL != "https://teleport.example.com:443" {
+		t.Errorf("Unexpected active profile identity: %+v", active)
+	}
+	if !reflect.DeepEqual(active.Roles, []string{"access", "editor"}) {
+		t.Errorf("Roles = %v", active.Roles)
+	}
+	if !reflect.DeepEqual(active.KubernetesGroups, []string{"developers", "system:authenticated"}) {
+		t.Errorf("KubernetesGroups = %v", active.KubernetesGroups)
+	}
+	if !reflect.DeepEqual(active.Traits["logins"], []string{"alice", "ubuntu"}) {
+		t.Errorf("Traits = %v", active.Traits)
+	}
+	if !active.KubernetesEnabled || active.KubernetesCluster != "prod" {
+		t.Errorf("Unexpected Kubernetes settings: %+v", active)
+	}
+	if want := time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC); !active.ValidUntil.Equal(want) {
+		t.Errorf("ValidUntil = %v, want %v", active.ValidUntil, want)
+	}
+
+	if len(status.Profiles) != 1 || status.Profiles[0].Cluster != "staging.example.com" {
+		t.Errorf("Unexpected profiles: %+v", status.Profiles)
+	}
+}
+
+func TestParseStatusNotLoggedIn(t *testing.T) {
+	status, err := ParseStatus(`{"profiles": null}`)
+	if err != nil {
+		t.Fatalf("ParseStatus() error = %v", err)
+	}
+	if status.Active != nil {
+		t.Errorf("Expected no active profile, got %+v", status.Active)
+	}
+	if status.Profiles == nil {
+		t.Error("Expected profiles to be an empty list, got nil")
+	}
+
+	if _, err := ParseStatus("> Profile URL: https://teleport.example.com:443"); err == nil {
+		t.Error("Expected an error for text output")
+	}
+}
+
+func TestProfileExpiry(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		validUntil    time.Time
+		expired       bool
+		expiresWithin bool
+	}{
+		{name: "valid for hours", validUntil: now.Add(8 * time.Hour)},
+		{name: "expiring soon", validUntil: now.Add(20 * time.Minute), expiresWithin: true},
+		{name: "at the window edge", validUntil: now.Add(time.Hour), expiresWithin: true},
+		{name: "expired", validUntil: now.Add(-time.Minute), expired: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := &Profile{ValidUntil: tt.validUntil}
+			if got := profile.Expired(now); got != tt.expired {
+				t.Errorf("Expired() = %v, want %v", got, tt.expired)
+			}
+			if got := profile.ExpiresWithin(now, time.Hour); got != tt.expiresWithin {
+				t.Errorf("ExpiresWithin() = %v, want %v", got, tt.expiresWithin)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+// Package tshtest provides a scriptable fake tsh for testing tool handlers
+// without a Teleport cluster.
+//
+// A Runner answers tsh invocations with canned stdout, stderr and exit codes
+// selected by regular expressions over the command line. Inject it through
+// server.WithRunner so handlers exercise their real parsing, formatting and
+// error paths:
+//
+//	runner := tshtest.NewRunner().
+//	    On(`^tsh ls .*--format json`, tshtest.Response{Stdout: tshtest.Fixture(t, "testdata/tsh_ls.json")}).
+//	    On(`^tsh ssh `, tshtest.Response{Stderr: "ERROR: access denied", ExitCode: 1})
+//
+//	sc, _ := server.NewServerContext(ctx, server.WithRunner(runner))
+//	result, _ := handleListSSHNodes(ctx, request, sc)
+//
+// Invocations are recorded and can be inspected with Calls.
+package tshtest
@@ -0,0 +1,143 @@
+package tshtest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/giantswarm/mcp-teleport/internal/teleport"
+)
+
+// Response is the canned result of a fake tsh invocation
+type Response struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	// Err, if set, is returned as the run error, e.g. to simulate a missing binary
+	Err error
+	// Block makes the invocation wait until its context is done, simulating a hanging command
+	Block bool
+}
+
+// rule pairs a command line pattern with its response
+type rule struct {
+	pattern  *regexp.Regexp
+	response Response
+}
+
+// Runner is a teleport.Runner that answers invocations from a script
+type Runner struct {
+	mutex  sync.Mutex
+	rules  []rule
+	calls  [][]string
+	inputs []string
+	envs   [][]string
+}
+
+// NewRunner creates a Runner without any rules
+func NewRunner() *Runner {
+	return &Runner{}
+}
+
+// On registers a response for invocations whose command line, i.e. the
+// binary name and arguments joined by single spaces (e.g. "tsh ls --format
+// json"), matches pattern. Rules are tried in the order they were added.
+func (r *Runner) On(pattern string, response Response) *Runner {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.rules = append(r.rules, rule{pattern: regexp.MustCompile(pattern), response: response})
+	return r
+}
+
+// Calls returns the argv of every invocation so far, including the binary name
+func (r *Runner) Calls() [][]string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	calls := make([][]string, len(r.calls))
+	copy(calls, r.calls)
+	return calls
+}
+
+// Inputs returns what was fed to every invocation so far on stdin, in the
+// same order as Calls
+func (r *Runner) Inputs() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	inputs := make([]string, len(r.inputs))
+	copy(inputs, r.inputs)
+	return inputs
+}
+
+// Envs returns the environment variables added to every invocation so far,
+// in the same order as Calls
+func (r *Runner) Envs() [][]string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	envs := make([][]string, len(r.envs))
+	copy(envs, r.envs)
+	return envs
+}
+
+// Run implements teleport.Runner. Invocations without a matching rule fail
+// with exit code 1 and a diagnostic on stderr.
+func (r *Runner) Run(ctx context.Context, cmd teleport.Command) (int, error) {
+	argv := append([]string{cmd.Name}, cmd.Args...)
+	line := strings.Join(argv, " ")
+
+	var input []byte
+	if cmd.Stdin != nil {
+		input, _ = io.ReadAll(cmd.Stdin)
+	}
+
+	r.mutex.Lock()
+	r.calls = append(r.calls, argv)
+	r.inputs = append(r.inputs, string(input))
+	r.envs = append(r.envs, cmd.Env)
+	response, ok := r.match(line)
+	r.mutex.Unlock()
+
+	if !ok {
+		response = Response{
+			Stderr:   fmt.Sprintf("tshtest: no response configured for %q\n", line),
+			ExitCode: 1,
+		}
+	}
+
+	if response.Stdout != "" && cmd.Stdout != nil {
+		io.WriteString(cmd.Stdout, response.Stdout)
+	}
+	if response.Stderr != "" && cmd.Stderr != nil {
+		io.WriteString(cmd.Stderr, response.Stderr)
+	}
+
+	if response.Block {
+		<-ctx.Done()
+		return -1, ctx.Err()
+	}
+
+	return response.ExitCode, response.Err
+}
+
+func (r *Runner) match(line string) (Response, bool) {
+	for _, rule := range r.rules {
+		if rule.pattern.MatchString(line) {
+			return rule.response, true
+		}
+	}
+	return Response{}, false
+}
+
+// Fixture returns the contents of a test fixture file, failing the test if it cannot be read
+func Fixture(t testing.TB, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read fixture %s: %v", path, err)
+	}
+	return string(data)
+}
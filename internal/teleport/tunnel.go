@@ -0,0 +1,116 @@
+package teleport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tunnelAddrPattern matches the local address tsh proxy db --tunnel reports once it is listening
+var tunnelAddrPattern = regexp.MustCompile(`on ((?:127\.0\.0\.1|localhost|\[::1\]):\d+)`)
+
+// DBTunnel is a local authenticated tunnel to a database started with
+// tsh proxy db --tunnel. Clients connect to Addr without TLS or credentials.
+type DBTunnel struct {
+	// Addr is the host:port the tunnel listens on
+	Addr string
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Close stops the tunnel and waits for the tsh process to exit
+func (t *DBTunnel) Close() {
+	t.cancel()
+	<-t.done
+}
+
+// StartDBTunnel starts tsh proxy db --tunnel for db with the given extra
+// arguments (e.g. --db-user) and waits until it reports its listen address.
+// The tunnel runs until Close is called or ctx is done. Waiting for the
+// tunnel is bounded by the call's timeout; see ContextWithTimeout.
+func (c *Client) StartDBTunnel(ctx context.Context, db string, args []string) (*DBTunnel, error) {
+	cmdArgs := append([]string{"proxy", "db", "--tunnel"}, args...)
+	cmdArgs = append(cmdArgs, db)
+
+	if c.dryRun {
+		return nil, fmt.Errorf("cannot start a database tunnel in dry-run mode (would execute: tsh %s)", strings.Join(cmdArgs, " "))
+	}
+
+	tunnelCtx, cancel := context.WithCancel(ctx)
+	tunnel := &DBTunnel{cancel: cancel, done: make(chan struct{})}
+
+	stdout, stdoutWriter := io.Pipe()
+	var stderr lockedBuffer
+	var runErr error
+	go func() {
+		defer close(tunnel.done)
+		_, runErr = c.runner.Run(tunnelCtx, Command{
+			Name:   "tsh",
+			Args:   cmdArgs,
+			Stdout: stdoutWriter,
+			Stderr: &stderr,
+		})
+		stdoutWriter.Close()
+	}()
+
+	// Scan stdout for the listen address, then keep draining it so tsh never blocks on writes
+	addrs := make(chan string, 1)
+	go func() {
+		defer close(addrs)
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			if match := tunnelAddrPattern.FindStringSubmatch(scanner.Text()); match != nil {
+				addrs <- match[1]
+				break
+			}
+		}
+		io.Copy(io.Discard, stdout)
+	}()
+
+	timeout := c.timeoutFor(ctx)
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case addr, ok := <-addrs:
+		if ok {
+			tunnel.Addr = addr
+			return tunnel, nil
+		}
+		// stdout was closed without an address: tsh exited
+		<-tunnel.done
+		cancel()
+		return nil, fmt.Errorf("tsh proxy db exited before the tunnel was ready: %v\n%s", runErr, stderr.String())
+	case <-timer.C:
+		tunnel.Close()
+		return nil, fmt.Errorf("database tunnel not ready after %s\n%s", timeout, stderr.String())
+	case <-ctx.Done():
+		tunnel.Close()
+		return nil, fmt.Errorf("database tunnel cancelled: %v", context.Cause(ctx))
+	}
+}
+
+// lockedBuffer is a bytes.Buffer that is safe for concurrent use
+type lockedBuffer struct {
+	mutex sync.Mutex
+	buf   bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.String()
+}
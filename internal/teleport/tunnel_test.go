@@ -0,0 +1,76 @@
+package teleport
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// tunnelRunner prints a tsh proxy db banner and then runs until cancelled
+type tunnelRunner struct {
+	banner  string
+	exit    bool
+	stopped chan struct{}
+}
+
+func (r *tunnelRunner) Run(ctx context.Context, cmd Command) (int, error) {
+	io.WriteString(cmd.Stdout, r.banner)
+	if r.exit {
+		io.WriteString(cmd.Stderr, "ERROR: database \"orders\" not found\n")
+		return 1, nil
+	}
+	<-ctx.Done()
+	close(r.stopped)
+	return -1, ctx.Err()
+}
+
+func TestStartDBTunnel(t *testing.T) {
+	runner := &tunnelRunner{
+		banner: "Started authenticated tunnel for the PostgreSQL database \"orders\" in cluster \"example.com\" on 127.0.0.1:54321.\n" +
+			"\nUse the following command to connect to the database or to the address above using other database GUI/CLI clients:\n" +
+			"  $ psql postgres://readonly@localhost:54321/orders\n",
+		stopped: make(chan struct{}),
+	}
+	client := NewClient(false, false, WithRunner(runner))
+
+	tunnel, err := client.StartDBTunnel(context.Background(), "orders", []string{"--db-user", "readonly"})
+	if err != nil {
+		t.Fatalf("StartDBTunnel() error = %v", err)
+	}
+	if tunnel.Addr != "127.0.0.1:54321" {
+		t.Errorf("Expected tunnel address 127.0.0.1:54321, got %q", tunnel.Addr)
+	}
+
+	tunnel.Close()
+	select {
+	case <-runner.stopped:
+	default:
+		t.Error("Expected Close to stop the tunnel process")
+	}
+}
+
+func TestStartDBTunnelFailure(t *testing.T) {
+	client := NewClient(false, false, WithRunner(&tunnelRunner{exit: true}))
+
+	_, err := client.StartDBTunnel(context.Background(), "orders", nil)
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected tsh error output, got: %v", err)
+	}
+}
+
+func TestStartDBTunnelTimeout(t *testing.T) {
+	runner := &tunnelRunner{banner: "Waiting for the proxy...\n", stopped: make(chan struct{})}
+	client := NewClient(false, false, WithRunner(runner), WithTimeout(20*time.Millisecond))
+
+	_, err := client.StartDBTunnel(context.Background(), "orders", nil)
+	if err == nil || !strings.Contains(err.Error(), "not ready after 20ms") {
+		t.Errorf("Expected a timeout error, got: %v", err)
+	}
+	select {
+	case <-runner.stopped:
+	default:
+		t.Error("Expected the tunnel process to be stopped after the timeout")
+	}
+}
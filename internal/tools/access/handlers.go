@@ -0,0 +1,649 @@
+package access
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// requestStates are the access request states in the order Teleport numbers them
+var requestStates = []string{"NONE", "PENDING", "APPROVED", "DENIED", "PROMOTED"}
+
+// requestIDPattern extracts the request ID from tsh request create output
+var requestIDPattern = regexp.MustCompile(`(?m)^Request ID:\s+(\S+)`)
+
+// requestState is an access request state. tsh serializes it as a number or,
+// depending on the version, as its name.
+type requestState string
+
+// UnmarshalJSON accepts both the numeric and the string form of a state
+func (s *requestState) UnmarshalJSON(data []byte) error {
+	var number int
+	if err := json.Unmarshal(data, &number); err == nil {
+		if number < 0 || number >= len(requestStates) {
+			return fmt.Errorf("unknown access request state %d", number)
+		}
+		*s = requestState(requestStates[number])
+		return nil
+	}
+
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return fmt.Errorf("invalid access request state %s", data)
+	}
+	*s = requestState(strings.ToUpper(name))
+	return nil
+}
+
+// resourceID identifies a resource in an access request
+type resourceID struct {
+	Cluster         string `json:"cluster"`
+	Kind            string `json:"kind"`
+	Name            string `json:"name"`
+	SubResourceName string `json:"sub_resource_name,omitempty"`
+}
+
+// String returns the resource ID in the /cluster/kind/name form tsh accepts
+func (r resourceID) String() string {
+	id := fmt.Sprintf("/%s/%s/%s", r.Cluster, r.Kind, r.Name)
+	if r.SubResourceName != "" {
+		id += "/" + r.SubResourceName
+	}
+	return id
+}
+
+// accessRequestJSON represents an access request from tsh request ls and show JSON output
+type accessRequestJSON struct {
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		User               string       `json:"user"`
+		Roles              []string     `json:"roles"`
+		State              requestState `json:"state"`
+		Created            time.Time    `json:"created"`
+		Expires            time.Time    `json:"expires"`
+		AccessExpiry       time.Time    `json:"access_expiry"`
+		RequestReason      string       `json:"request_reason"`
+		ResolveReason      string       `json:"resolve_reason"`
+		SuggestedReviewers []string     `json:"suggested_reviewers"`
+		RequestedResources []resourceID `json:"requested_resource_ids"`
+		Reviews            []struct {
+			Author        string       `json:"author"`
+			ProposedState requestState `json:"proposed_state"`
+			Reason        string       `json:"reason"`
+		} `json:"reviews"`
+	} `json:"spec"`
+}
+
+// AccessRequest is an access request as returned by the access request tools
+type AccessRequest struct {
+	ID        string   `json:"id"`
+	User      string   `json:"user"`
+	State     string   `json:"state"`
+	Roles     []string `json:"roles"`
+	Resources []string `json:"resources"`
+	Reason    string   `json:"reason,omitempty"`
+	// ResolveReason is the reason given by the reviewer who approved or denied the request
+	ResolveReason string    `json:"resolveReason,omitempty"`
+	Reviewers     []string  `json:"reviewers,omitempty"`
+	Reviews       []Review  `json:"reviews,omitempty"`
+	Created       time.Time `json:"created"`
+	// Expires is when the request itself expires if it is not reviewed
+	Expires time.Time `json:"expires"`
+	// AccessExpiry is when the access granted by the request ends
+	AccessExpiry time.Time `json:"accessExpiry,omitempty"`
+}
+
+// Review is a review submitted for an access request
+type Review struct {
+	Author string `json:"author"`
+	State  string `json:"state"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// AccessRequestList is the result of teleport_request_list
+type AccessRequestList struct {
+	Requests []AccessRequest `json:"requests"`
+}
+
+// RequestableResource is a resource found by teleport_request_search
+type RequestableResource struct {
+	// ID is the resource ID to pass to teleport_request_create
+	ID       string            `json:"id"`
+	Kind     string            `json:"kind"`
+	Name     string            `json:"name"`
+	Hostname string            `json:"hostname,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+}
+
+// RequestableResourceList is the result of teleport_request_search
+type RequestableResourceList struct {
+	Resources []RequestableResource `json:"resources"`
+}
+
+// requestableResourceJSON represents a resource from tsh request search JSON output
+type requestableResourceJSON struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Name   string            `json:"name"`
+		Labels map[string]string `json:"labels"`
+	} `json:"metadata"`
+	Spec struct {
+		Hostname string `json:"hostname"`
+	} `json:"spec"`
+}
+
+// handleRequestSearch handles the teleport_request_search tool
+func handleRequestSearch(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Create teleport client
+	client := sc.TeleportClient()
+
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	kind, _ := params["kind"].(string)
+	if kind == "" {
+		return accessError("Error: 'kind' is required (node, kube_cluster, db, app or windows_desktop)"), nil
+	}
+
+	// Build request search command arguments
+	args := teleport.FormatArgs(params)
+	args = append(args, "--kind", kind, "--format", "json")
+
+	if search, ok := params["search"].(string); ok && search != "" {
+		args = append(args, "--search", search)
+	}
+
+	if query, ok := params["query"].(string); ok && query != "" {
+		args = append(args, "--query", query)
+	}
+
+	if labels, ok := params["labels"].(string); ok && labels != "" {
+		args = append(args, "--labels", labels)
+	}
+
+	// Execute request search command
+	result := client.ExecuteCommandContext(ctx, "request search", args)
+	if !result.Success {
+		return accessError(fmt.Sprintf("Error: %s\n%s", result.ErrorMessage, result.Output)), nil
+	}
+
+	var raw []requestableResourceJSON
+	if err := json.Unmarshal([]byte(result.Stdout), &raw); err != nil {
+		// If JSON parsing fails, return raw output
+		return mcp.NewToolResultText(result.Output), nil
+	}
+
+	// Resource IDs include the cluster name, which tsh only prints in text output
+	cluster := activeCluster(ctx, client, teleport.FormatArgs(params))
+
+	resources := RequestableResourceList{Resources: make([]RequestableResource, 0, len(raw))}
+	for _, r := range raw {
+		resourceKind := r.Kind
+		if resourceKind == "" {
+			resourceKind = kind
+		}
+		resources.Resources = append(resources.Resources, RequestableResource{
+			ID:       resourceID{Cluster: cluster, Kind: resourceKind, Name: r.Metadata.Name}.String(),
+			Kind:     resourceKind,
+			Name:     r.Metadata.Name,
+			Hostname: r.Spec.Hostname,
+			Labels:   r.Metadata.Labels,
+		})
+	}
+	sort.Slice(resources.Resources, func(i, j int) bool {
+		return resources.Resources[i].ID < resources.Resources[j].ID
+	})
+
+	return server.WithDiagnostics(mcp.NewToolResultStructured(resources, formatResources(resources.Resources)), result), nil
+}
+
+// handleRequestCreate handles the teleport_request_create tool
+func handleRequestCreate(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Create teleport client
+	client := sc.TeleportClient()
+
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	roles, _ := params["roles"].(string)
+	resources, _ := params["resources"].(string)
+	if roles == "" && resources == "" {
+		return accessError("Error: Either 'roles' or 'resources' must be specified. Use teleport_request_search to find resource IDs."), nil
+	}
+
+	// Build request create command arguments; --nowait returns as soon as
+	// the request exists instead of blocking until it is reviewed
+	args := teleport.FormatArgs(params)
+	args = append(args, "--nowait")
+
+	if roles != "" {
+		args = append(args, "--roles", roles)
+	}
+
+	for _, resource := range splitList(resources) {
+		args = append(args, "--resource", resource)
+	}
+
+	if reason, ok := params["reason"].(string); ok && reason != "" {
+		args = append(args, "--reason", reason)
+	}
+
+	if reviewers, ok := params["reviewers"].(string); ok && reviewers != "" {
+		args = append(args, "--reviewers", reviewers)
+	}
+
+	// Execute request create command
+	result := client.ExecuteCommandContext(ctx, "request create", args)
+	if !result.Success {
+		return accessError(fmt.Sprintf("Error: %s\n%s", result.ErrorMessage, result.Output)), nil
+	}
+
+	match := requestIDPattern.FindStringSubmatch(result.Stdout)
+	if match == nil {
+		// If the request ID cannot be found (e.g. in dry-run mode), return the output as is
+		return mcp.NewToolResultText(result.Output), nil
+	}
+
+	accessRequest, err := showRequest(ctx, client, teleport.FormatArgs(params), match[1])
+	if err != nil {
+		return mcp.NewToolResultText(result.Output), nil
+	}
+
+	text := fmt.Sprintf("Created access request %s.\n\n%s\nOnce it is approved, use teleport_request_login with requestId %s to assume it.",
+		accessRequest.ID, formatRequest(accessRequest), accessRequest.ID)
+	return server.WithDiagnostics(mcp.NewToolResultStructured(accessRequest, text), result), nil
+}
+
+// handleRequestList handles the teleport_request_list tool
+func handleRequestList(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Create teleport client
+	client := sc.TeleportClient()
+
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	// Pending and approved requests are the ones that still matter by default
+	states := []string{"PENDING", "APPROVED"}
+	if state, ok := params["state"].(string); ok && state != "" {
+		if state == "all" {
+			states = nil
+		} else {
+			states = []string{strings.ToUpper(state)}
+		}
+	}
+
+	// Build request ls command arguments
+	args := teleport.FormatArgs(params)
+	args = append(args, "--format", "json")
+
+	if reviewable, ok := params["reviewable"].(bool); ok && reviewable {
+		args = append(args, "--reviewable")
+	}
+
+	// Execute request ls command
+	result := client.ExecuteCommandContext(ctx, "request ls", args)
+	if !result.Success {
+		return accessError(fmt.Sprintf("Error: %s\n%s", result.ErrorMessage, result.Output)), nil
+	}
+
+	requests, err := parseRequests(result.Stdout)
+	if err != nil {
+		// If JSON parsing fails, return raw output
+		return mcp.NewToolResultText(result.Output), nil
+	}
+
+	list := AccessRequestList{Requests: []AccessRequest{}}
+	for _, r := range requests {
+		if states == nil || contains(states, r.State) {
+			list.Requests = append(list.Requests, r)
+		}
+	}
+	sort.Slice(list.Requests, func(i, j int) bool {
+		return list.Requests[i].Created.After(list.Requests[j].Created)
+	})
+
+	return server.WithDiagnostics(mcp.NewToolResultStructured(list, formatRequests(list.Requests)), result), nil
+}
+
+// handleRequestShow handles the teleport_request_show tool
+func handleRequestShow(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Create teleport client
+	client := sc.TeleportClient()
+
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	requestID, _ := params["requestId"].(string)
+	if requestID == "" {
+		return accessError("Error: 'requestId' is required. Use teleport_request_list to find request IDs."), nil
+	}
+
+	// Execute request show command
+	args := append(teleport.FormatArgs(params), "--format", "json", requestID)
+	result := client.ExecuteCommandContext(ctx, "request show", args)
+	if !result.Success {
+		return accessError(fmt.Sprintf("Error: %s\n%s", result.ErrorMessage, result.Output)), nil
+	}
+
+	accessRequest, err := parseRequest(result.Stdout, requestID)
+	if err != nil {
+		// If JSON parsing fails, return raw output
+		return mcp.NewToolResultText(result.Output), nil
+	}
+
+	return server.WithDiagnostics(mcp.NewToolResultStructured(accessRequest, formatRequest(accessRequest)), result), nil
+}
+
+// handleRequestLogin handles the teleport_request_login tool
+func handleRequestLogin(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Create teleport client
+	client := sc.TeleportClient()
+
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	requestID, _ := params["requestId"].(string)
+	if requestID == "" {
+		return accessError("Error: 'requestId' is required. Use teleport_request_list to find approved requests."), nil
+	}
+
+	// Execute login command with the approved request
+	args := append(teleport.FormatArgs(params), "--request-id", requestID)
+	result := client.ExecuteCommandContext(ctx, "login", args)
+	if !result.Success {
+		return accessError(fmt.Sprintf("Error: %s\n%s", result.ErrorMessage, result.Output)), nil
+	}
+
+	return statusResult(ctx, client, params, result, fmt.Sprintf("Assumed access request %s.", requestID))
+}
+
+// handleRequestDrop handles the teleport_request_drop tool
+func handleRequestDrop(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Create teleport client
+	client := sc.TeleportClient()
+
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	// Without a request ID tsh drops every assumed request
+	args := teleport.FormatArgs(params)
+	message := "Dropped all assumed access requests."
+	if requestID, ok := params["requestId"].(string); ok && requestID != "" {
+		args = append(args, requestID)
+		message = fmt.Sprintf("Dropped access request %s.", requestID)
+	}
+
+	// Execute request drop command
+	result := client.ExecuteCommandContext(ctx, "request drop", args)
+	if !result.Success {
+		return accessError(fmt.Sprintf("Error: %s\n%s", result.ErrorMessage, result.Output)), nil
+	}
+
+	return statusResult(ctx, client, params, result, message)
+}
+
+// statusResult reports the roles and assumed requests of the active profile
+// after a successful login or drop
+func statusResult(ctx context.Context, client *teleport.Client, params map[string]interface{}, result *teleport.ExecutionResult, message string) (*mcp.CallToolResult, error) {
+	statusResult := client.ExecuteCommandContext(ctx, "status", append(teleport.FormatArgs(params), "--format", "json"))
+	if !statusResult.Success {
+		return mcp.NewToolResultText(fmt.Sprintf("%s\n%s", message, result.Output)), nil
+	}
+
+	status, err := teleport.ParseStatus(statusResult.Stdout)
+	if err != nil || status.Active == nil {
+		// If the status cannot be parsed (e.g. in dry-run mode), return the output as is
+		return mcp.NewToolResultText(fmt.Sprintf("%s\n%s", message, result.Output)), nil
+	}
+
+	active := status.Active
+	var text strings.Builder
+	text.WriteString(message + "\n\n")
+	text.WriteString(fmt.Sprintf("Logged in to %s as %s\n", active.Cluster, active.Username))
+	text.WriteString(fmt.Sprintf("Roles: %s\n", formatList(active.Roles)))
+	text.WriteString(fmt.Sprintf("Active access requests: %s\n", formatList(active.ActiveRequests)))
+	text.WriteString(fmt.Sprintf("Certificates valid until: %s\n", active.ValidUntil.Format(time.RFC3339)))
+
+	return server.WithDiagnostics(mcp.NewToolResultStructured(status, text.String()), result, statusResult), nil
+}
+
+// showRequest fetches a single access request with tsh request show
+func showRequest(ctx context.Context, client *teleport.Client, commonArgs []string, requestID string) (*AccessRequest, error) {
+	args := append(append([]string{}, commonArgs...), "--format", "json", requestID)
+	result := client.ExecuteCommandContext(ctx, "request show", args)
+	if !result.Success {
+		return nil, fmt.Errorf("%s\n%s", result.ErrorMessage, result.Output)
+	}
+	return parseRequest(result.Stdout, requestID)
+}
+
+// parseRequest parses tsh request show JSON output, which depending on the
+// version is a single request or a list with one request
+func parseRequest(jsonOutput, requestID string) (*AccessRequest, error) {
+	output := strings.TrimSpace(jsonOutput)
+	if !strings.HasPrefix(output, "[") {
+		output = "[" + output + "]"
+	}
+	requests, err := parseRequests(output)
+	if err != nil {
+		return nil, err
+	}
+	if len(requests) != 1 {
+		return nil, fmt.Errorf("access request %s not found", requestID)
+	}
+	return &requests[0], nil
+}
+
+// activeCluster returns the cluster of the active tsh profile, or an empty
+// string if it cannot be determined
+func activeCluster(ctx context.Context, client *teleport.Client, commonArgs []string) string {
+	result := client.ExecuteCommandContext(ctx, "status", append(commonArgs, "--format", "json"))
+	if !result.Success {
+		return ""
+	}
+	status, err := teleport.ParseStatus(result.Stdout)
+	if err != nil || status.Active == nil {
+		return ""
+	}
+	return status.Active.Cluster
+}
+
+// parseRequests parses tsh request ls or show JSON output
+func parseRequests(jsonOutput string) ([]AccessRequest, error) {
+	if strings.TrimSpace(jsonOutput) == "" {
+		return []AccessRequest{}, nil
+	}
+
+	var raw []accessRequestJSON
+	if err := json.Unmarshal([]byte(jsonOutput), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON output: %w", err)
+	}
+
+	requests := make([]AccessRequest, 0, len(raw))
+	for _, r := range raw {
+		request := AccessRequest{
+			ID:            r.Metadata.Name,
+			User:          r.Spec.User,
+			State:         string(r.Spec.State),
+			Roles:         r.Spec.Roles,
+			Resources:     []string{},
+			Reason:        r.Spec.RequestReason,
+			ResolveReason: r.Spec.ResolveReason,
+			Reviewers:     r.Spec.SuggestedReviewers,
+			Created:       r.Spec.Created,
+			Expires:       r.Spec.Expires,
+			AccessExpiry:  r.Spec.AccessExpiry,
+		}
+		for _, resource := range r.Spec.RequestedResources {
+			request.Resources = append(request.Resources, resource.String())
+		}
+		for _, review := range r.Spec.Reviews {
+			request.Reviews = append(request.Reviews, Review{
+				Author: review.Author,
+				State:  string(review.ProposedState),
+				Reason: review.Reason,
+			})
+		}
+		requests = append(requests, request)
+	}
+	return requests, nil
+}
+
+// formatRequests formats a list of access requests for display
+func formatRequests(requests []AccessRequest) string {
+	if len(requests) == 0 {
+		return "No access requests found"
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Found %d access request(s):\n\n", len(requests)))
+	for i := range requests {
+		result.WriteString(formatRequest(&requests[i]))
+		result.WriteString("\n")
+	}
+	return result.String()
+}
+
+// formatRequest formats a single access request for display
+func formatRequest(request *AccessRequest) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("• %s (%s)\n", request.ID, request.State))
+	result.WriteString(fmt.Sprintf("  User: %s\n", request.User))
+	if len(request.Roles) > 0 {
+		result.WriteString(fmt.Sprintf("  Roles: %s\n", strings.Join(request.Roles, ", ")))
+	}
+	if len(request.Resources) > 0 {
+		result.WriteString(fmt.Sprintf("  Resources: %s\n", strings.Join(request.Resources, ", ")))
+	}
+	if request.Reason != "" {
+		result.WriteString(fmt.Sprintf("  Reason: %s\n", request.Reason))
+	}
+	if len(request.Reviewers) > 0 {
+		result.WriteString(fmt.Sprintf("  Suggested reviewers: %s\n", strings.Join(request.Reviewers, ", ")))
+	}
+	for _, review := range request.Reviews {
+		result.WriteString(fmt.Sprintf("  Review: %s by %s", review.State, review.Author))
+		if review.Reason != "" {
+			result.WriteString(fmt.Sprintf(" (%s)", review.Reason))
+		}
+		result.WriteString("\n")
+	}
+	if request.ResolveReason != "" {
+		result.WriteString(fmt.Sprintf("  Resolve reason: %s\n", request.ResolveReason))
+	}
+	if !request.Created.IsZero() {
+		result.WriteString(fmt.Sprintf("  Created: %s\n", request.Created.Format(time.RFC3339)))
+	}
+	if !request.AccessExpiry.IsZero() {
+		result.WriteString(fmt.Sprintf("  Access expires: %s\n", request.AccessExpiry.Format(time.RFC3339)))
+	}
+	return result.String()
+}
+
+// formatResources formats requestable resources for display
+func formatResources(resources []RequestableResource) string {
+	if len(resources) == 0 {
+		return "No requestable resources found"
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Found %d requestable resource(s):\n\n", len(resources)))
+	for _, resource := range resources {
+		result.WriteString(fmt.Sprintf("• %s\n", resource.ID))
+		if resource.Hostname != "" {
+			result.WriteString(fmt.Sprintf("  Hostname: %s\n", resource.Hostname))
+		}
+		if len(resource.Labels) > 0 {
+			// Sort labels for consistent output
+			var labelPairs []string
+			for k, v := range resource.Labels {
+				labelPairs = append(labelPairs, fmt.Sprintf("%s=%s", k, v))
+			}
+			sort.Strings(labelPairs)
+			result.WriteString(fmt.Sprintf("  Labels: %s\n", strings.Join(labelPairs, ", ")))
+		}
+	}
+	result.WriteString("\nPass the IDs as 'resources' to teleport_request_create to request access.\n")
+	return result.String()
+}
+
+// accessError builds an error result for the access request tools
+func accessError(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+		IsError: true,
+	}
+}
+
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// formatList joins values for display
+func formatList(values []string) string {
+	if len(values) == 0 {
+		return "none"
+	}
+	return strings.Join(values, ", ")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,294 @@
+package access
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport"
+	"github.com/giantswarm/mcp-teleport/internal/teleport/tshtest"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	pendingID  = "0b9ab6a1-7a6e-4b8c-9d53-6a1d2f0c1e01"
+	approvedID = "5c1e2d3f-0a9b-4c8d-8e7f-6a5b4c3d2e02"
+)
+
+// pendingRequestJSON is tsh request show output for the pending request
+const pendingRequestJSON = `{
+  "kind": "access_request",
+  "metadata": {"name": "` + pendingID + `"},
+  "spec": {
+    "user": "alice",
+    "roles": ["dba"],
+    "state": 1,
+    "created": "2026-10-16T10:00:00Z",
+    "request_reason": "INC-1234 orders database is slow",
+    "suggested_reviewers": ["bob"]
+  }
+}`
+
+// newTestContext creates a server context whose tsh is the given fake runner
+func newTestContext(t *testing.T, runner *tshtest.Runner) *server.ServerContext {
+	t.Helper()
+	sc, err := server.NewServerContext(context.Background(), server.WithRunner(runner))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	t.Cleanup(func() { sc.Shutdown() })
+	return sc
+}
+
+func TestHandleRequestList(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh request ls --format json`, tshtest.Response{Stdout: tshtest.Fixture(t, "testdata/tsh_request_ls.json")})
+	sc := newTestContext(t, runner)
+
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		wantIDs []string
+	}{
+		{
+			name:    "pending and approved by default",
+			params:  map[string]interface{}{},
+			wantIDs: []string{pendingID, approvedID},
+		},
+		{
+			name:    "approved only",
+			params:  map[string]interface{}{"state": "approved"},
+			wantIDs: []string{approvedID},
+		},
+		{
+			name:    "all states",
+			params:  map[string]interface{}{"state": "all"},
+			wantIDs: []string{pendingID, approvedID, "9f8e7d6c-5b4a-4392-8170-6f5e4d3c2b03"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handleRequestList(context.Background(), createTestRequest(tt.params), sc)
+			if err != nil || result.IsError {
+				t.Fatalf("handleRequestList() failed: %v %+v", err, result)
+			}
+
+			list := result.StructuredContent.(AccessRequestList)
+			var ids []string
+			for _, r := range list.Requests {
+				ids = append(ids, r.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.wantIDs, ",") {
+				t.Errorf("handleRequestList() IDs = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+
+	// The approved request carries its resources and review
+	result, _ := handleRequestList(context.Background(), createTestRequest(map[string]interface{}{"state": "approved"}), sc)
+	approved := result.StructuredContent.(AccessRequestList).Requests[0]
+	if approved.State != "APPROVED" || len(approved.Resources) != 1 || approved.Resources[0] != "/example.com/node/8d3f6c1e-worker-01" {
+		t.Errorf("Unexpected approved request: %+v", approved)
+	}
+	if len(approved.Reviews) != 1 || approved.Reviews[0].Author != "bob" || approved.Reviews[0].State != "APPROVED" {
+		t.Errorf("Unexpected reviews: %+v", approved.Reviews)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, expected := range []string{approvedID + " (APPROVED)", "Review: APPROVED by bob (ok for one hour)", "Access expires: 2026-10-16T13:00:00Z"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("handleRequestList() result doesn't contain %q. Got: %s", expected, text)
+		}
+	}
+}
+
+func TestHandleRequestSearch(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh request search --kind node --format json --labels env=prod$`, tshtest.Response{Stdout: tshtest.Fixture(t, "testdata/tsh_request_search.json")}).
+		On(`^tsh status --format json$`, tshtest.Response{Stdout: tshtest.Fixture(t, "testdata/tsh_status.json")})
+	sc := newTestContext(t, runner)
+
+	result, err := handleRequestSearch(context.Background(), createTestRequest(map[string]interface{}{"kind": "node", "labels": "env=prod"}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleRequestSearch() failed: %v %+v", err, result)
+	}
+
+	resources := result.StructuredContent.(RequestableResourceList).Resources
+	if len(resources) != 2 {
+		t.Fatalf("Expected 2 resources, got %+v", resources)
+	}
+	if resources[0].ID != "/example.com/node/1a2b3c4d-db-01" || resources[0].Hostname != "db-01" {
+		t.Errorf("Unexpected resource: %+v", resources[0])
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, expected := range []string{"/example.com/node/8d3f6c1e-worker-01", "Hostname: worker-01", "Labels: env=prod, role=worker"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("handleRequestSearch() result doesn't contain %q. Got: %s", expected, text)
+		}
+	}
+
+	// kind is required
+	result, _ = handleRequestSearch(context.Background(), createTestRequest(map[string]interface{}{}), sc)
+	if !result.IsError {
+		t.Error("Expected an error without kind")
+	}
+}
+
+func TestHandleRequestCreate(t *testing.T) {
+	createOutput := "Creating request...\nRequest ID:     " + pendingID + "\nUsername:       alice\nRoles:          dba\nStatus:         PENDING\n"
+	runner := tshtest.NewRunner().
+		On(`^tsh request create --nowait`, tshtest.Response{Stdout: createOutput}).
+		On(`^tsh request show --format json `+pendingID+`$`, tshtest.Response{Stdout: pendingRequestJSON})
+	sc := newTestContext(t, runner)
+
+	tests := []struct {
+		name     string
+		params   map[string]interface{}
+		wantErr  bool
+		wantArgs string
+	}{
+		{
+			name:     "roles with reason",
+			params:   map[string]interface{}{"roles": "dba", "reason": "INC-1234", "reviewers": "bob"},
+			wantArgs: "tsh request create --nowait --roles dba --reason INC-1234 --reviewers bob",
+		},
+		{
+			name:     "resources",
+			params:   map[string]interface{}{"resources": "/example.com/node/a, /example.com/node/b"},
+			wantArgs: "tsh request create --nowait --resource /example.com/node/a --resource /example.com/node/b",
+		},
+		{
+			name:    "neither roles nor resources",
+			params:  map[string]interface{}{"reason": "INC-1234"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(runner.Calls())
+			result, err := handleRequestCreate(context.Background(), createTestRequest(tt.params), sc)
+			if err != nil {
+				t.Fatalf("handleRequestCreate() error = %v", err)
+			}
+			if result.IsError != tt.wantErr {
+				t.Fatalf("handleRequestCreate() IsError = %v, want %v", result.IsError, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			calls := runner.Calls()[before:]
+			if len(calls) != 2 || strings.Join(calls[0], " ") != tt.wantArgs {
+				t.Errorf("handleRequestCreate() calls = %v, want %q first", calls, tt.wantArgs)
+			}
+
+			accessRequest := result.StructuredContent.(*AccessRequest)
+			if accessRequest.ID != pendingID || accessRequest.State != "PENDING" {
+				t.Errorf("Unexpected request: %+v", accessRequest)
+			}
+			if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "teleport_request_login") {
+				t.Errorf("Expected a hint to log in once approved, got: %s", text)
+			}
+		})
+	}
+}
+
+func TestHandleRequestShow(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh request show --format json `+pendingID+`$`, tshtest.Response{Stdout: pendingRequestJSON}).
+		On(`^tsh request show `, tshtest.Response{Stderr: "ERROR: access request \"missing\" not found\n", ExitCode: 1})
+	sc := newTestContext(t, runner)
+
+	result, err := handleRequestShow(context.Background(), createTestRequest(map[string]interface{}{"requestId": pendingID}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleRequestShow() failed: %v %+v", err, result)
+	}
+	if accessRequest := result.StructuredContent.(*AccessRequest); accessRequest.Reason != "INC-1234 orders database is slow" {
+		t.Errorf("Unexpected request: %+v", accessRequest)
+	}
+
+	result, _ = handleRequestShow(context.Background(), createTestRequest(map[string]interface{}{"requestId": "missing"}), sc)
+	if !result.IsError {
+		t.Error("Expected an error for an unknown request")
+	}
+}
+
+func TestHandleRequestLoginAndDrop(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh login --request-id `+pendingID+`$`, tshtest.Response{Stdout: "> Profile URL: https://teleport.example.com:443\n"}).
+		On(`^tsh request drop`, tshtest.Response{}).
+		On(`^tsh status --format json$`, tshtest.Response{Stdout: tshtest.Fixture(t, "testdata/tsh_status.json")})
+	sc := newTestContext(t, runner)
+
+	tests := []struct {
+		name     string
+		handler  func(context.Context, mcp.CallToolRequest, *server.ServerContext) (*mcp.CallToolResult, error)
+		params   map[string]interface{}
+		wantErr  bool
+		wantArgs string
+		contains string
+	}{
+		{
+			name:     "login with request",
+			handler:  handleRequestLogin,
+			params:   map[string]interface{}{"requestId": pendingID},
+			wantArgs: "tsh login --request-id " + pendingID,
+			contains: "Roles: access, dba",
+		},
+		{
+			name:    "login without request ID",
+			handler: handleRequestLogin,
+			params:  map[string]interface{}{},
+			wantErr: true,
+		},
+		{
+			name:     "drop one request",
+			handler:  handleRequestDrop,
+			params:   map[string]interface{}{"requestId": pendingID},
+			wantArgs: "tsh request drop " + pendingID,
+			contains: "Dropped access request " + pendingID,
+		},
+		{
+			name:     "drop all requests",
+			handler:  handleRequestDrop,
+			params:   map[string]interface{}{},
+			wantArgs: "tsh request drop",
+			contains: "Dropped all assumed access requests",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(runner.Calls())
+			result, err := tt.handler(context.Background(), createTestRequest(tt.params), sc)
+			if err != nil {
+				t.Fatalf("handler error = %v", err)
+			}
+			if result.IsError != tt.wantErr {
+				t.Fatalf("handler IsError = %v, want %v", result.IsError, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if calls := runner.Calls()[before:]; len(calls) == 0 || strings.Join(calls[0], " ") != tt.wantArgs {
+				t.Errorf("handler calls = %v, want %q first", calls, tt.wantArgs)
+			}
+			if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, tt.contains) {
+				t.Errorf("handler result doesn't contain %q. Got: %s", tt.contains, text)
+			}
+			if status, ok := result.StructuredContent.(*teleport.Status); !ok || len(status.Active.ActiveRequests) != 1 {
+				t.Errorf("handler structured content = %#v", result.StructuredContent)
+			}
+		})
+	}
+}
+
+func createTestRequest(params map[string]interface{}) mcp.CallToolRequest {
+	var request mcp.CallToolRequest
+	request.Params.Arguments = params
+	return request
+}
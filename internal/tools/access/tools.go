@@ -0,0 +1,217 @@
+package access
+
+import (
+	"context"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// RegisterAccessRequestTools registers access request tools with the MCP server
+func RegisterAccessRequestTools(s *mcpserver.MCPServer, sc *server.ServerContext) error {
+	// teleport_request_search tool
+	searchTool := mcp.NewTool("teleport_request_search",
+		mcp.WithDescription("Search for resources that can be requested with an access request, returning their resource IDs (structured)"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("proxyParam",
+			mcp.Description("Teleport proxy address"),
+		),
+		mcp.WithString("userParam",
+			mcp.Description("Teleport user, defaults to current local user"),
+		),
+		mcp.WithString("identityParam",
+			mcp.Description("Identity file"),
+		),
+		mcp.WithBoolean("insecureParam",
+			mcp.Description("Do not verify server's certificate and host name. Use only in test environments"),
+		),
+		mcp.WithBoolean("debugParam",
+			mcp.Description("Verbose logging to stdout"),
+		),
+		mcp.WithString("kind",
+			mcp.Required(),
+			mcp.Description("Kind of resource to search for"),
+			mcp.Enum("node", "kube_cluster", "db", "app", "windows_desktop"),
+		),
+		mcp.WithString("search",
+			mcp.Description("List of comma separated search keywords or phrases enclosed in quotations (e.g. foo,bar,\"some phrase\")"),
+		),
+		mcp.WithString("query",
+			mcp.Description("Query by predicate language enclosed in single quotes. Supports ==, !=, &&, and || (e.g. 'labels[\"key1\"] == \"value1\" && labels[\"key2\"] != \"value2\"')"),
+		),
+		mcp.WithString("labels",
+			mcp.Description("List of comma separated labels to filter by (e.g. key1=value1,key2=value2)"),
+		),
+		server.TimeoutOption(),
+	)
+
+	s.AddTool(searchTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleRequestSearch(ctx, request, sc)
+	})
+
+	// teleport_request_create tool
+	createTool := mcp.NewTool("teleport_request_create",
+		mcp.WithDescription("Create an access request for roles or resources. The request is created without waiting for review; use teleport_request_show to follow it and teleport_request_login once it is approved (structured)"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("proxyParam",
+			mcp.Description("Teleport proxy address"),
+		),
+		mcp.WithString("userParam",
+			mcp.Description("Teleport user, defaults to current local user"),
+		),
+		mcp.WithString("identityParam",
+			mcp.Description("Identity file"),
+		),
+		mcp.WithBoolean("insecureParam",
+			mcp.Description("Do not verify server's certificate and host name. Use only in test environments"),
+		),
+		mcp.WithBoolean("debugParam",
+			mcp.Description("Verbose logging to stdout"),
+		),
+		mcp.WithString("roles",
+			mcp.Description("Comma separated roles to request"),
+		),
+		mcp.WithString("resources",
+			mcp.Description("Comma separated resource IDs to request, as returned by teleport_request_search (e.g. /example.com/node/0a1b2c3d)"),
+		),
+		mcp.WithString("reason",
+			mcp.Description("Reason for the request, shown to reviewers"),
+		),
+		mcp.WithString("reviewers",
+			mcp.Description("Comma separated suggested reviewers"),
+		),
+		server.TimeoutOption(),
+	)
+
+	s.AddTool(createTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleRequestCreate(ctx, request, sc)
+	})
+
+	// teleport_request_list tool
+	listTool := mcp.NewTool("teleport_request_list",
+		mcp.WithDescription("List access requests, by default the pending and approved ones (structured)"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("proxyParam",
+			mcp.Description("Teleport proxy address"),
+		),
+		mcp.WithString("userParam",
+			mcp.Description("Teleport user, defaults to current local user"),
+		),
+		mcp.WithString("identityParam",
+			mcp.Description("Identity file"),
+		),
+		mcp.WithBoolean("insecureParam",
+			mcp.Description("Do not verify server's certificate and host name. Use only in test environments"),
+		),
+		mcp.WithBoolean("debugParam",
+			mcp.Description("Verbose logging to stdout"),
+		),
+		mcp.WithString("state",
+			mcp.Description("Only list requests in this state; defaults to pending and approved requests"),
+			mcp.Enum("pending", "approved", "denied", "all"),
+		),
+		mcp.WithBoolean("reviewable",
+			mcp.Description("Only list requests the current user can review"),
+		),
+		server.TimeoutOption(),
+	)
+
+	s.AddTool(listTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleRequestList(ctx, request, sc)
+	})
+
+	// teleport_request_show tool
+	showTool := mcp.NewTool("teleport_request_show",
+		mcp.WithDescription("Show the state, roles, resources and reviews of an access request (structured)"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("proxyParam",
+			mcp.Description("Teleport proxy address"),
+		),
+		mcp.WithString("userParam",
+			mcp.Description("Teleport user, defaults to current local user"),
+		),
+		mcp.WithString("identityParam",
+			mcp.Description("Identity file"),
+		),
+		mcp.WithBoolean("insecureParam",
+			mcp.Description("Do not verify server's certificate and host name. Use only in test environments"),
+		),
+		mcp.WithBoolean("debugParam",
+			mcp.Description("Verbose logging to stdout"),
+		),
+		mcp.WithString("requestId",
+			mcp.Required(),
+			mcp.Description("ID of the access request"),
+		),
+		server.TimeoutOption(),
+	)
+
+	s.AddTool(showTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleRequestShow(ctx, request, sc)
+	})
+
+	// teleport_request_login tool
+	loginTool := mcp.NewTool("teleport_request_login",
+		mcp.WithDescription("Re-login with an approved access request to assume its roles and resources. Returns the updated status (structured)"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("proxyParam",
+			mcp.Description("Teleport proxy address"),
+		),
+		mcp.WithString("userParam",
+			mcp.Description("Teleport user, defaults to current local user"),
+		),
+		mcp.WithString("identityParam",
+			mcp.Description("Identity file"),
+		),
+		mcp.WithBoolean("insecureParam",
+			mcp.Description("Do not verify server's certificate and host name. Use only in test environments"),
+		),
+		mcp.WithBoolean("debugParam",
+			mcp.Description("Verbose logging to stdout"),
+		),
+		mcp.WithString("requestId",
+			mcp.Required(),
+			mcp.Description("ID of the approved access request"),
+		),
+		server.TimeoutOption(),
+	)
+
+	s.AddTool(loginTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleRequestLogin(ctx, request, sc)
+	})
+
+	// teleport_request_drop tool
+	dropTool := mcp.NewTool("teleport_request_drop",
+		mcp.WithDescription("Drop the roles and resources of an assumed access request, or of all assumed requests. Returns the updated status (structured)"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("proxyParam",
+			mcp.Description("Teleport proxy address"),
+		),
+		mcp.WithString("userParam",
+			mcp.Description("Teleport user, defaults to current local user"),
+		),
+		mcp.WithString("identityParam",
+			mcp.Description("Identity file"),
+		),
+		mcp.WithBoolean("insecureParam",
+			mcp.Description("Do not verify server's certificate and host name. Use only in test environments"),
+		),
+		mcp.WithBoolean("debugParam",
+			mcp.Description("Verbose logging to stdout"),
+		),
+		mcp.WithString("requestId",
+			mcp.Description("ID of the access request to drop; drops all assumed requests if omitted"),
+		),
+		server.TimeoutOption(),
+	)
+
+	s.AddTool(dropTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleRequestDrop(ctx, request, sc)
+	})
+
+	return nil
+}
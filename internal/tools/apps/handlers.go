@@ -0,0 +1,452 @@
+package apps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// awsConsolePrefix is the URI prefix of AWS management console apps
+const awsConsolePrefix = "https://console.aws.amazon.com"
+
+// App represents an application from tsh apps ls JSON output
+type App struct {
+	Metadata struct {
+		Name        string            `json:"name"`
+		Description string            `json:"description"`
+		Labels      map[string]string `json:"labels"`
+	} `json:"metadata"`
+	Spec struct {
+		URI        string `json:"uri"`
+		PublicAddr string `json:"public_addr"`
+		Cloud      string `json:"cloud"`
+	} `json:"spec"`
+}
+
+// AppConfig represents connection information from tsh apps config JSON output
+type AppConfig struct {
+	Name string `json:"name"`
+	URI  string `json:"uri"`
+	CA   string `json:"ca"`
+	Cert string `json:"cert"`
+	Key  string `json:"key"`
+	// Curl is an example curl command for calling the app with its certificates
+	Curl string `json:"curl"`
+}
+
+// handleAppList handles the teleport_app_list tool
+func handleAppList(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Create teleport client
+	client := sc.TeleportClient()
+
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	// Build apps ls command arguments
+	var args []string
+
+	// Add common parameters (proxy, user, etc.)
+	commonArgs := teleport.FormatArgs(params)
+	args = append(args, commonArgs...)
+
+	// Always use JSON format for parsing
+	args = append(args, "--format", "json")
+
+	if search, ok := params["search"].(string); ok && search != "" {
+		args = append(args, "--search", search)
+	}
+
+	if query, ok := params["query"].(string); ok && query != "" {
+		args = append(args, "--query", query)
+	}
+
+	if cluster, ok := params["cluster"].(string); ok && cluster != "" {
+		args = append(args, "--cluster", cluster)
+	}
+
+	// Add labels as positional arguments if provided
+	if labels, ok := params["labels"].(string); ok && labels != "" {
+		args = append(args, labels)
+	}
+
+	// Execute apps ls command
+	result := client.ExecuteCommandContext(ctx, "apps ls", args)
+
+	// Build MCP response
+	var content []mcp.Content
+	if !result.Success {
+		content = append(content, mcp.TextContent{
+			Type: "text",
+			Text: fmt.Sprintf("Error: %s\n%s", result.ErrorMessage, result.Output),
+		})
+		return &mcp.CallToolResult{
+			Content: content,
+			IsError: true,
+		}, nil
+	}
+
+	// Parse JSON output and format for user
+	formattedOutput, err := formatAppsOutput(result.Stdout, params)
+	if err != nil {
+		// If JSON parsing fails, return raw output
+		content = append(content, mcp.TextContent{
+			Type: "text",
+			Text: result.Output,
+		})
+		return &mcp.CallToolResult{
+			Content: content,
+		}, nil
+	}
+
+	content = append(content, mcp.TextContent{
+		Type: "text",
+		Text: formattedOutput,
+	})
+
+	// Surface tsh warnings that were kept out of the parsed output
+	return server.WithDiagnostics(&mcp.CallToolResult{
+		Content: content,
+	}, result), nil
+}
+
+// handleAppLogin handles the teleport_app_login tool
+func handleAppLogin(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Create teleport client
+	client := sc.TeleportClient()
+
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	// Validate required app parameter
+	app, ok := params["app"].(string)
+	if !ok || app == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: "Error: 'app' is required. Check 'teleport_app_list' for available applications.",
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	// Build apps login command arguments
+	var args []string
+
+	// Add common parameters (proxy, user, etc.)
+	commonArgs := teleport.FormatArgs(params)
+	args = append(args, commonArgs...)
+
+	if cluster, ok := params["cluster"].(string); ok && cluster != "" {
+		args = append(args, "--cluster", cluster)
+	}
+
+	if awsRole, ok := params["awsRole"].(string); ok && awsRole != "" {
+		args = append(args, "--aws-role", awsRole)
+	}
+
+	if azureIdentity, ok := params["azureIdentity"].(string); ok && azureIdentity != "" {
+		args = append(args, "--azure-identity", azureIdentity)
+	}
+
+	if gcpServiceAccount, ok := params["gcpServiceAccount"].(string); ok && gcpServiceAccount != "" {
+		args = append(args, "--gcp-service-account", gcpServiceAccount)
+	}
+
+	// Add the app name as the final argument
+	args = append(args, app)
+
+	// Execute apps login command
+	result := client.ExecuteCommandContext(ctx, "apps login", args)
+
+	// Build MCP response
+	var content []mcp.Content
+	if !result.Success {
+		content = append(content, mcp.TextContent{
+			Type: "text",
+			Text: fmt.Sprintf("Error: %s\n%s", result.ErrorMessage, result.Output),
+		})
+		return &mcp.CallToolResult{
+			Content: content,
+			IsError: true,
+		}, nil
+	}
+
+	// Format success message
+	var successMessage strings.Builder
+	successMessage.WriteString(fmt.Sprintf("Successfully logged in to application: %s\n", app))
+	successMessage.WriteString("Use teleport_app_config to get the app URI and certificates.\n\n")
+
+	if result.Output != "" {
+		successMessage.WriteString("Command output:\n")
+		successMessage.WriteString(result.Output)
+	}
+
+	content = append(content, mcp.TextContent{
+		Type: "text",
+		Text: successMessage.String(),
+	})
+
+	return &mcp.CallToolResult{
+		Content: content,
+	}, nil
+}
+
+// handleAppLogout handles the teleport_app_logout tool
+func handleAppLogout(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Create teleport client
+	client := sc.TeleportClient()
+
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	// tsh apps logout without a name logs out of every app, so require it to be explicit
+	app, _ := params["app"].(string)
+	all, _ := params["all"].(bool)
+
+	if app == "" && !all {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: "Error: Either 'app' must be specified, or 'all' must be true to logout of all applications.",
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	if app != "" && all {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: "Error: 'app' and 'all' are mutually exclusive.",
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	// Build apps logout command arguments
+	var args []string
+
+	// Add common parameters (proxy, user, etc.)
+	commonArgs := teleport.FormatArgs(params)
+	args = append(args, commonArgs...)
+
+	if cluster, ok := params["cluster"].(string); ok && cluster != "" {
+		args = append(args, "--cluster", cluster)
+	}
+
+	if app != "" {
+		args = append(args, app)
+	}
+
+	// Execute apps logout command
+	result := client.ExecuteCommandContext(ctx, "apps logout", args)
+
+	// Build MCP response
+	var content []mcp.Content
+	if !result.Success {
+		content = append(content, mcp.TextContent{
+			Type: "text",
+			Text: fmt.Sprintf("Error: %s\n%s", result.ErrorMessage, result.Output),
+		})
+		return &mcp.CallToolResult{
+			Content: content,
+			IsError: true,
+		}, nil
+	}
+
+	content = append(content, mcp.TextContent{
+		Type: "text",
+		Text: result.Output,
+	})
+
+	return &mcp.CallToolResult{
+		Content: content,
+	}, nil
+}
+
+// handleAppConfig handles the teleport_app_config tool
+func handleAppConfig(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Create teleport client
+	client := sc.TeleportClient()
+
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	app, _ := params["app"].(string)
+	result := fetchAppConfig(ctx, client, params, app)
+
+	// Build MCP response
+	if !result.Success {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %s\n%s", result.ErrorMessage, result.Output),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	config, err := parseAppConfig(result.Stdout)
+	if err != nil {
+		// If JSON parsing fails (e.g. in dry-run mode), return raw output
+		return mcp.NewToolResultText(result.Output), nil
+	}
+
+	return server.WithDiagnostics(mcp.NewToolResultStructured(config, formatAppConfig(config)), result), nil
+}
+
+// fetchAppConfig runs tsh apps config for app, or the only logged in app if app is empty
+func fetchAppConfig(ctx context.Context, client *teleport.Client, params map[string]interface{}, app string) *teleport.ExecutionResult {
+	// Build apps config command arguments
+	var args []string
+
+	// Add common parameters (proxy, user, etc.)
+	commonArgs := teleport.FormatArgs(params)
+	args = append(args, commonArgs...)
+
+	// Always use JSON format for parsing
+	args = append(args, "--format", "json")
+
+	if cluster, ok := params["cluster"].(string); ok && cluster != "" {
+		args = append(args, "--cluster", cluster)
+	}
+
+	if app != "" {
+		args = append(args, app)
+	}
+
+	// Execute apps config command
+	return client.ExecuteCommandContext(ctx, "apps config", args)
+}
+
+// parseAppConfig parses JSON output from tsh apps config
+func parseAppConfig(jsonOutput string) (*AppConfig, error) {
+	var config AppConfig
+	if err := json.Unmarshal([]byte(jsonOutput), &config); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON output: %w", err)
+	}
+	return &config, nil
+}
+
+// formatAppConfig formats application connection information for display
+func formatAppConfig(config *AppConfig) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Connection information for application: %s\n\n", config.Name))
+	result.WriteString(fmt.Sprintf("URI: %s\n", config.URI))
+	result.WriteString(fmt.Sprintf("CA: %s\n", config.CA))
+	result.WriteString(fmt.Sprintf("Cert: %s\n", config.Cert))
+	result.WriteString(fmt.Sprintf("Key: %s\n", config.Key))
+	if config.Curl != "" {
+		result.WriteString(fmt.Sprintf("\nExample curl command:\n%s\n", config.Curl))
+	}
+	return result.String()
+}
+
+// formatAppsOutput formats JSON output from tsh apps ls command
+func formatAppsOutput(jsonOutput string, params map[string]interface{}) (string, error) {
+	if strings.TrimSpace(jsonOutput) == "" {
+		return "No applications found", nil
+	}
+
+	var apps []App
+	if err := json.Unmarshal([]byte(jsonOutput), &apps); err != nil {
+		return "", fmt.Errorf("failed to parse JSON output: %w", err)
+	}
+
+	if len(apps) == 0 {
+		return "No applications found", nil
+	}
+
+	// Check if verbose mode is requested
+	verbose, _ := params["verbose"].(bool)
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Found %d application(s):\n\n", len(apps)))
+
+	// Sort apps by name for consistent output
+	sort.Slice(apps, func(i, j int) bool {
+		return apps[i].Metadata.Name < apps[j].Metadata.Name
+	})
+
+	for _, app := range apps {
+		result.WriteString(fmt.Sprintf("• %s", app.Metadata.Name))
+		if app.Spec.PublicAddr != "" {
+			result.WriteString(fmt.Sprintf(" (%s)", app.Spec.PublicAddr))
+		}
+		result.WriteString("\n")
+
+		if app.Metadata.Description != "" {
+			result.WriteString(fmt.Sprintf("  Description: %s\n", app.Metadata.Description))
+		}
+
+		switch {
+		case strings.HasPrefix(app.Spec.URI, awsConsolePrefix):
+			result.WriteString("  Type: AWS console (use awsRole to select a role at login)\n")
+		case app.Spec.Cloud != "":
+			result.WriteString(fmt.Sprintf("  Type: %s\n", app.Spec.Cloud))
+		}
+
+		if verbose {
+			if app.Spec.URI != "" {
+				result.WriteString(fmt.Sprintf("  URI: %s\n", app.Spec.URI))
+			}
+
+			if len(app.Metadata.Labels) > 0 {
+				result.WriteString("  Labels: ")
+				// Sort labels for consistent output
+				var labelKeys []string
+				for k := range app.Metadata.Labels {
+					labelKeys = append(labelKeys, k)
+				}
+				sort.Strings(labelKeys)
+
+				var labelPairs []string
+				for _, k := range labelKeys {
+					labelPairs = append(labelPairs, fmt.Sprintf("%s=%s", k, app.Metadata.Labels[k]))
+				}
+				result.WriteString(strings.Join(labelPairs, ", "))
+				result.WriteString("\n")
+			}
+		} else if len(app.Metadata.Labels) > 0 {
+			// In non-verbose mode, just show count of labels
+			result.WriteString(fmt.Sprintf("  Labels: %d available (use verbose=true to see details)\n", len(app.Metadata.Labels)))
+		}
+		result.WriteString("\n")
+	}
+
+	return result.String(), nil
+}
@@ -0,0 +1,172 @@
+package apps
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport/tshtest"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleAppList(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh apps ls --format json`, tshtest.Response{Stdout: tshtest.Fixture(t, "testdata/tsh_apps_ls.json")})
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx, server.WithRunner(runner))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	tests := []struct {
+		name     string
+		params   map[string]interface{}
+		contains []string
+		wantArgs string
+	}{
+		{
+			name:     "basic list",
+			params:   map[string]interface{}{},
+			contains: []string{"Found 2 application(s)", "• aws-prod (aws-prod.teleport.example.com)", "Type: AWS console", "• grafana (grafana.teleport.example.com)", "Labels: 2 available"},
+			wantArgs: "tsh apps ls --format json",
+		},
+		{
+			name:     "verbose list with filters",
+			params:   map[string]interface{}{"verbose": true, "query": `labels["env"] == "prod"`, "labels": "team=observability"},
+			contains: []string{"URI: http://grafana.monitoring.svc:3000", "Labels: env=prod, team=observability"},
+			wantArgs: `tsh apps ls --format json --query labels["env"] == "prod" team=observability`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handleAppList(ctx, createTestRequest(tt.params), sc)
+			if err != nil || result.IsError {
+				t.Fatalf("handleAppList() failed: %v %+v", err, result)
+			}
+
+			text := result.Content[0].(mcp.TextContent).Text
+			for _, expected := range tt.contains {
+				if !strings.Contains(text, expected) {
+					t.Errorf("handleAppList() result doesn't contain %q. Got: %s", expected, text)
+				}
+			}
+
+			calls := runner.Calls()
+			if got := strings.Join(calls[len(calls)-1], " "); got != tt.wantArgs {
+				t.Errorf("handleAppList() ran %q, want %q", got, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestHandleAppLogin(t *testing.T) {
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx, server.WithDryRun(true), server.WithNonDestructiveMode(true))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	tests := []struct {
+		name     string
+		params   map[string]interface{}
+		wantErr  bool
+		contains string
+	}{
+		{
+			name:     "login to an app",
+			params:   map[string]interface{}{"app": "grafana"},
+			contains: "tsh apps login grafana",
+		},
+		{
+			name:     "login to an AWS console app with a role",
+			params:   map[string]interface{}{"app": "aws-prod", "awsRole": "ReadOnlyAccess", "cluster": "leaf"},
+			contains: "tsh apps login --cluster leaf --aws-role ReadOnlyAccess aws-prod",
+		},
+		{
+			name:     "missing app",
+			params:   map[string]interface{}{"awsRole": "ReadOnlyAccess"},
+			wantErr:  true,
+			contains: "'app' is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handleAppLogin(ctx, createTestRequest(tt.params), sc)
+			if err != nil {
+				t.Fatalf("handleAppLogin() error = %v", err)
+			}
+			if result.IsError != tt.wantErr {
+				t.Errorf("handleAppLogin() IsError = %v, want %v", result.IsError, tt.wantErr)
+			}
+			if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, tt.contains) {
+				t.Errorf("handleAppLogin() result doesn't contain %q. Got: %s", tt.contains, text)
+			}
+		})
+	}
+}
+
+func TestHandleAppLogout(t *testing.T) {
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx, server.WithDryRun(true))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	result, err := handleAppLogout(ctx, createTestRequest(map[string]interface{}{"app": "grafana"}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleAppLogout() failed: %v %+v", err, result)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "tsh apps logout grafana") {
+		t.Errorf("handleAppLogout() unexpected result: %s", text)
+	}
+
+	result, err = handleAppLogout(ctx, createTestRequest(map[string]interface{}{}), sc)
+	if err != nil {
+		t.Fatalf("handleAppLogout() error = %v", err)
+	}
+	if !result.IsError {
+		t.Error("handleAppLogout() should require app or all")
+	}
+}
+
+func TestHandleAppConfig(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh apps config --format json grafana$`, tshtest.Response{Stdout: tshtest.Fixture(t, "testdata/tsh_apps_config.json")})
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx, server.WithRunner(runner))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	result, err := handleAppConfig(ctx, createTestRequest(map[string]interface{}{"app": "grafana"}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleAppConfig() failed: %v %+v", err, result)
+	}
+
+	config, ok := result.StructuredContent.(*AppConfig)
+	if !ok {
+		t.Fatalf("handleAppConfig() structured content is %T, want *AppConfig", result.StructuredContent)
+	}
+	if config.URI != "https://grafana.teleport.example.com" || !strings.HasSuffix(config.Cert, "grafana-x509.pem") || !strings.HasPrefix(config.Curl, "curl") {
+		t.Errorf("handleAppConfig() unexpected config: %+v", config)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "Example curl command") {
+		t.Errorf("handleAppConfig() text doesn't contain the curl hint. Got: %s", text)
+	}
+}
+
+// Helper function to create test request
+func createTestRequest(params map[string]interface{}) mcp.CallToolRequest {
+	var request mcp.CallToolRequest
+	request.Params.Arguments = params
+	return request
+}
@@ -0,0 +1,281 @@
+package apps
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// defaultMaxBodyBytes is the number of response body bytes returned when maxBodyBytes is not set
+	defaultMaxBodyBytes = 64 * 1024
+	// maxMaxBodyBytes is the upper bound for maxBodyBytes
+	maxMaxBodyBytes = 1024 * 1024
+)
+
+// methodOverrideHeaders make many frameworks handle a request as the method
+// they name instead of the one it was sent with
+var methodOverrideHeaders = []string{"X-Http-Method-Override", "X-Http-Method", "X-Method-Override"}
+
+// AppResponse is the response to a request sent with teleport_app_request
+type AppResponse struct {
+	Status     string            `json:"status"`
+	StatusCode int               `json:"statusCode"`
+	Headers    map[string]string `json:"headers"`
+	// Body is the response body if it is valid UTF-8
+	Body string `json:"body,omitempty"`
+	// BodyBase64 is the base64-encoded response body if it is binary
+	BodyBase64 string `json:"bodyBase64,omitempty"`
+	// BodyTruncated is set when the body exceeded maxBodyBytes
+	BodyTruncated bool `json:"bodyTruncated"`
+}
+
+// handleAppRequest handles the teleport_app_request tool
+func handleAppRequest(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	// Validate required app parameter
+	app, _ := params["app"].(string)
+	if app == "" {
+		return requestError("Error: 'app' is required. Check 'teleport_app_list' for available applications."), nil
+	}
+
+	method := http.MethodGet
+	if m, ok := params["method"].(string); ok && m != "" {
+		method = strings.ToUpper(m)
+	}
+
+	// Only GET requests are considered read-only
+	if method != http.MethodGet {
+		if err := sc.CheckMutation("teleport_app_request with method " + method); err != nil {
+			return requestError(fmt.Sprintf("Error: %v", err)), nil
+		}
+	}
+
+	path := "/"
+	if p, ok := params["path"].(string); ok && p != "" {
+		path = p
+	}
+
+	headers := make(map[string]string)
+	if h, ok := params["headers"].(map[string]interface{}); ok {
+		for name, value := range h {
+			headers[name] = fmt.Sprint(value)
+		}
+	}
+
+	// A GET request overriding its method is not read-only either
+	for name := range headers {
+		if slices.Contains(methodOverrideHeaders, http.CanonicalHeaderKey(name)) {
+			if err := sc.CheckMutation("teleport_app_request with header " + name); err != nil {
+				return requestError(fmt.Sprintf("Error: %v", err)), nil
+			}
+		}
+	}
+	body, _ := params["body"].(string)
+
+	maxBodyBytes := defaultMaxBodyBytes
+	if value, ok := params["maxBodyBytes"].(float64); ok && value > 0 {
+		maxBodyBytes = int(value)
+		if maxBodyBytes > maxMaxBodyBytes {
+			maxBodyBytes = maxMaxBodyBytes
+		}
+	}
+
+	if sc.IsDryRun() {
+		return mcp.NewToolResultText(fmt.Sprintf("DRY RUN: Would send %s %s to application %s", method, path, app)), nil
+	}
+
+	// Look up the app URI and certificates
+	result := fetchAppConfig(ctx, sc.TeleportClient(), params, app)
+	if !result.Success {
+		return requestError(fmt.Sprintf("Error: %s\n%s\nLogin with teleport_app_login first.", result.ErrorMessage, result.Output)), nil
+	}
+	config, err := parseAppConfig(result.Stdout)
+	if err != nil {
+		return requestError(fmt.Sprintf("Error: %v", err)), nil
+	}
+
+	target, err := resolveAppURL(config.URI, path)
+	if err != nil {
+		return requestError(fmt.Sprintf("Error: %v", err)), nil
+	}
+
+	timeout, err := sc.ToolTimeout(request.Params.Name, request.Params.Arguments)
+	if err != nil {
+		return requestError(fmt.Sprintf("Error: %v", err)), nil
+	}
+
+	httpClient, err := newAppHTTPClient(config)
+	if err != nil {
+		return requestError(fmt.Sprintf("Error: %v", err)), nil
+	}
+	httpClient.Timeout = timeout
+
+	httpRequest, err := http.NewRequestWithContext(ctx, method, target, strings.NewReader(body))
+	if err != nil {
+		return requestError(fmt.Sprintf("Error: Invalid request: %v", err)), nil
+	}
+	for name, value := range headers {
+		httpRequest.Header.Set(name, value)
+	}
+
+	httpResponse, err := httpClient.Do(httpRequest)
+	if err != nil {
+		return requestError(fmt.Sprintf("Error: Request to %s failed: %v", app, err)), nil
+	}
+	defer httpResponse.Body.Close()
+
+	response, err := readAppResponse(httpResponse, maxBodyBytes)
+	if err != nil {
+		return requestError(fmt.Sprintf("Error: Failed to read response from %s: %v", app, err)), nil
+	}
+
+	return mcp.NewToolResultStructured(response, formatAppResponse(response, maxBodyBytes)), nil
+}
+
+// requestError builds an error result for teleport_app_request
+func requestError(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+		IsError: true,
+	}
+}
+
+// resolveAppURL joins an app URI with a request path, refusing paths that
+// would send the app certificates to another host
+func resolveAppURL(appURI, path string) (string, error) {
+	base, err := url.Parse(appURI)
+	if err != nil {
+		return "", fmt.Errorf("invalid app URI %q: %w", appURI, err)
+	}
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") {
+		return "", fmt.Errorf("path must be an absolute path on the app, such as /api/health, got %q", path)
+	}
+	ref, err := url.Parse(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid path %q: %w", path, err)
+	}
+
+	target := base.ResolveReference(ref)
+	if target.Host != base.Host || target.Scheme != base.Scheme {
+		return "", fmt.Errorf("path %q leaves the app", path)
+	}
+	return target.String(), nil
+}
+
+// newAppHTTPClient returns an HTTP client that authenticates with the app
+// certificates and does not follow redirects
+func newAppHTTPClient(config *AppConfig) (*http.Client, error) {
+	certificate, err := tls.LoadX509KeyPair(config.Cert, config.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load app certificate: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	// The Teleport CA is only needed when the proxy uses a self-signed certificate
+	if config.CA != "" {
+		if caPEM, err := os.ReadFile(config.CA); err == nil {
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+			pool.AppendCertsFromPEM(caPEM)
+			tlsConfig.RootCAs = pool
+		}
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: tlsConfig,
+			Proxy:           http.ProxyFromEnvironment,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}, nil
+}
+
+// readAppResponse reads the status, headers and at most maxBodyBytes of the body
+func readAppResponse(response *http.Response, maxBodyBytes int) (*AppResponse, error) {
+	data, err := io.ReadAll(io.LimitReader(response.Body, int64(maxBodyBytes)+1))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AppResponse{
+		Status:     response.Status,
+		StatusCode: response.StatusCode,
+		Headers:    make(map[string]string, len(response.Header)),
+	}
+	for name, values := range response.Header {
+		result.Headers[name] = strings.Join(values, ", ")
+	}
+
+	if len(data) > maxBodyBytes {
+		data = data[:maxBodyBytes]
+		result.BodyTruncated = true
+	}
+	if utf8.Valid(data) {
+		result.Body = string(data)
+	} else {
+		result.BodyBase64 = base64.StdEncoding.EncodeToString(data)
+	}
+	return result, nil
+}
+
+// formatAppResponse formats an app response for display
+func formatAppResponse(response *AppResponse, maxBodyBytes int) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("HTTP %s\n", response.Status))
+
+	// Sort headers for consistent output
+	var names []string
+	for name := range response.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		result.WriteString(fmt.Sprintf("%s: %s\n", name, response.Headers[name]))
+	}
+	result.WriteString("\n")
+
+	if response.BodyBase64 != "" {
+		result.WriteString(fmt.Sprintf("(binary body, base64-encoded)\n%s\n", response.BodyBase64))
+	} else {
+		result.WriteString(response.Body)
+	}
+
+	if response.BodyTruncated {
+		result.WriteString(fmt.Sprintf("\n\n(body truncated to %d bytes; raise maxBodyBytes to see more)\n", maxBodyBytes))
+	}
+	return result.String()
+}
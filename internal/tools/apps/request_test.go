@@ -0,0 +1,225 @@
+package apps
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport/tshtest"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// newAppServer starts a TLS server that requires a client certificate, and
+// returns a fake tsh that reports it as the grafana app with matching certificates
+func newAppServer(t *testing.T) (*httptest.Server, *tshtest.Runner) {
+	t.Helper()
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 {
+			http.Error(w, "no client certificate", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, "https://example.com/", http.StatusFound)
+		case "/large":
+			io.WriteString(w, strings.Repeat("x", 100))
+		default:
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"method":%q,"path":%q,"query":%q,"user":%q,"header":%q,"body":%q}`,
+				r.Method, r.URL.Path, r.URL.RawQuery, r.TLS.PeerCertificates[0].Subject.CommonName, r.Header.Get("X-Test"), body)
+		}
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	caPath := filepath.Join(dir, "ca.pem")
+	writePEM(t, caPath, "CERTIFICATE", srv.Certificate().Raw)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "alice"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	certPath := filepath.Join(dir, "grafana-x509.pem")
+	keyPath := filepath.Join(dir, "alice")
+	writePEM(t, certPath, "CERTIFICATE", certDER)
+	writePEM(t, keyPath, "EC PRIVATE KEY", keyDER)
+
+	config, _ := json.Marshal(AppConfig{Name: "grafana", URI: srv.URL, CA: caPath, Cert: certPath, Key: keyPath})
+	runner := tshtest.NewRunner().
+		On(`^tsh apps config --format json grafana$`, tshtest.Response{Stdout: string(config)}).
+		On(`^tsh apps config `, tshtest.Response{Stderr: "ERROR: not logged into app \"missing\"\n", ExitCode: 1})
+	return srv, runner
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestHandleAppRequest(t *testing.T) {
+	_, runner := newAppServer(t)
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx, server.WithRunner(runner), server.WithNonDestructiveMode(false))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	tests := []struct {
+		name       string
+		params     map[string]interface{}
+		wantErr    bool
+		statusCode int
+		contains   string
+		truncated  bool
+	}{
+		{
+			name:       "GET with query and headers",
+			params:     map[string]interface{}{"app": "grafana", "path": "/api/health?verbose=1", "headers": map[string]interface{}{"X-Test": "yes"}},
+			statusCode: http.StatusOK,
+			contains:   `{"method":"GET","path":"/api/health","query":"verbose=1","user":"alice","header":"yes","body":""}`,
+		},
+		{
+			name:       "POST with body",
+			params:     map[string]interface{}{"app": "grafana", "method": "post", "path": "/api/annotations", "body": `{"text":"deploy"}`},
+			statusCode: http.StatusOK,
+			contains:   `"method":"POST"`,
+		},
+		{
+			name:       "redirects are not followed",
+			params:     map[string]interface{}{"app": "grafana", "path": "/redirect"},
+			statusCode: http.StatusFound,
+			contains:   "https://example.com/",
+		},
+		{
+			name:       "body is truncated",
+			params:     map[string]interface{}{"app": "grafana", "path": "/large", "maxBodyBytes": float64(10)},
+			statusCode: http.StatusOK,
+			contains:   "body truncated to 10 bytes",
+			truncated:  true,
+		},
+		{
+			name:     "path leaving the app",
+			params:   map[string]interface{}{"app": "grafana", "path": "//example.com/steal"},
+			wantErr:  true,
+			contains: "absolute path on the app",
+		},
+		{
+			name:     "not logged in",
+			params:   map[string]interface{}{"app": "missing"},
+			wantErr:  true,
+			contains: "teleport_app_login",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handleAppRequest(ctx, createTestRequest(tt.params), sc)
+			if err != nil {
+				t.Fatalf("handleAppRequest() error = %v", err)
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+			if result.IsError != tt.wantErr {
+				t.Fatalf("handleAppRequest() IsError = %v, want %v: %s", result.IsError, tt.wantErr, text)
+			}
+			if !strings.Contains(text, tt.contains) {
+				t.Errorf("handleAppRequest() result doesn't contain %q. Got: %s", tt.contains, text)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			response := result.StructuredContent.(*AppResponse)
+			if response.StatusCode != tt.statusCode {
+				t.Errorf("handleAppRequest() status = %d, want %d", response.StatusCode, tt.statusCode)
+			}
+			if response.BodyTruncated != tt.truncated {
+				t.Errorf("handleAppRequest() truncated = %v, want %v", response.BodyTruncated, tt.truncated)
+			}
+		})
+	}
+}
+
+func TestHandleAppRequestNonDestructive(t *testing.T) {
+	_, runner := newAppServer(t)
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx, server.WithRunner(runner), server.WithNonDestructiveMode(true))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	result, err := handleAppRequest(ctx, createTestRequest(map[string]interface{}{"app": "grafana"}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("GET should be allowed in non-destructive mode: %v %+v", err, result)
+	}
+
+	for _, method := range []string{"POST", "PUT", "PATCH", "DELETE"} {
+		result, err := handleAppRequest(ctx, createTestRequest(map[string]interface{}{"app": "grafana", "method": method}), sc)
+		if err != nil {
+			t.Fatalf("handleAppRequest() error = %v", err)
+		}
+		if !result.IsError {
+			t.Errorf("%s should be refused in non-destructive mode", method)
+		}
+		if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "non-destructive mode") {
+			t.Errorf("Expected a non-destructive mode error for %s, got: %s", method, text)
+		}
+	}
+
+	for _, header := range []string{"X-HTTP-Method-Override", "x-http-method", "X-Method-Override"} {
+		result, err := handleAppRequest(ctx, createTestRequest(map[string]interface{}{
+			"app":     "grafana",
+			"headers": map[string]interface{}{header: "DELETE"},
+		}), sc)
+		if err != nil {
+			t.Fatalf("handleAppRequest() error = %v", err)
+		}
+		if !result.IsError {
+			t.Errorf("GET with %s should be refused in non-destructive mode", header)
+		}
+		if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "non-destructive mode") {
+			t.Errorf("Expected a non-destructive mode error for %s, got: %s", header, text)
+		}
+	}
+}
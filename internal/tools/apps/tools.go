@@ -1,12 +1,196 @@
 package apps
 
 import (
+	"context"
+
 	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/mark3labs/mcp-go/mcp"
 	mcpserver "github.com/mark3labs/mcp-go/server"
 )
 
 // RegisterAppTools registers application-related tools with the MCP server
 func RegisterAppTools(s *mcpserver.MCPServer, sc *server.ServerContext) error {
-	// TODO: Implement app tools
+	// teleport_app_list tool
+	listTool := mcp.NewTool("teleport_app_list",
+		mcp.WithDescription("List applications available through Teleport"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("proxyParam",
+			mcp.Description("Teleport proxy address"),
+		),
+		mcp.WithString("userParam",
+			mcp.Description("Teleport user, defaults to current local user"),
+		),
+		mcp.WithString("identityParam",
+			mcp.Description("Identity file"),
+		),
+		mcp.WithBoolean("insecureParam",
+			mcp.Description("Do not verify server's certificate and host name. Use only in test environments"),
+		),
+		mcp.WithBoolean("debugParam",
+			mcp.Description("Verbose logging to stdout"),
+		),
+		mcp.WithString("search",
+			mcp.Description("List of comma separated search keywords or phrases enclosed in quotations (e.g. foo,bar,\"some phrase\")"),
+		),
+		mcp.WithString("query",
+			mcp.Description("Query by predicate language enclosed in single quotes. Supports ==, !=, &&, and || (e.g. 'labels[\"key1\"] == \"value1\" && labels[\"key2\"] != \"value2\"')"),
+		),
+		mcp.WithString("labels",
+			mcp.Description("List of comma separated labels to filter by (e.g. key1=value1,key2=value2)"),
+		),
+		mcp.WithBoolean("verbose",
+			mcp.Description("Show application URIs and labels"),
+		),
+		mcp.WithString("cluster",
+			mcp.Description("Specify the Teleport cluster to connect"),
+		),
+		server.TimeoutOption(),
+	)
+
+	s.AddTool(listTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleAppList(ctx, request, sc)
+	})
+
+	// teleport_app_login tool
+	loginTool := mcp.NewTool("teleport_app_login",
+		mcp.WithDescription("Retrieve short-lived certificates for an application available through Teleport, including AWS, Azure and GCP console apps. Certificates are stored in the local tsh profile."),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("proxyParam",
+			mcp.Description("Teleport proxy address"),
+		),
+		mcp.WithString("userParam",
+			mcp.Description("Teleport user, defaults to current local user"),
+		),
+		mcp.WithString("identityParam",
+			mcp.Description("Identity file"),
+		),
+		mcp.WithBoolean("insecureParam",
+			mcp.Description("Do not verify server's certificate and host name. Use only in test environments"),
+		),
+		mcp.WithBoolean("debugParam",
+			mcp.Description("Verbose logging to stdout"),
+		),
+		mcp.WithString("cluster",
+			mcp.Description("Specify the Teleport cluster to connect"),
+		),
+		mcp.WithString("app",
+			mcp.Required(),
+			mcp.Description("Name of the application to login to. Check 'teleport_app_list' for available applications."),
+		),
+		mcp.WithString("awsRole",
+			mcp.Description("AWS role name or ARN to assume, for AWS console apps. Required if more than one role is available."),
+		),
+		mcp.WithString("azureIdentity",
+			mcp.Description("Azure managed identity to use, for Azure apps"),
+		),
+		mcp.WithString("gcpServiceAccount",
+			mcp.Description("GCP service account to use, for GCP apps"),
+		),
+		server.TimeoutOption(),
+	)
+
+	s.AddTool(loginTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleAppLogin(ctx, request, sc)
+	})
+
+	// teleport_app_logout tool
+	logoutTool := mcp.NewTool("teleport_app_logout",
+		mcp.WithDescription("Remove locally stored application certificates obtained with teleport_app_login"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("proxyParam",
+			mcp.Description("Teleport proxy address"),
+		),
+		mcp.WithString("userParam",
+			mcp.Description("Teleport user, defaults to current local user"),
+		),
+		mcp.WithBoolean("debugParam",
+			mcp.Description("Verbose logging to stdout"),
+		),
+		mcp.WithString("cluster",
+			mcp.Description("Specify the Teleport cluster to connect"),
+		),
+		mcp.WithString("app",
+			mcp.Description("Name of the application to logout of"),
+		),
+		mcp.WithBoolean("all",
+			mcp.Description("Logout of all applications. Mutually exclusive with app."),
+		),
+		server.TimeoutOption(),
+	)
+
+	s.AddTool(logoutTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleAppLogout(ctx, request, sc)
+	})
+
+	// teleport_app_config tool
+	configTool := mcp.NewTool("teleport_app_config",
+		mcp.WithDescription("Show connection information (URI, certificate paths and an example curl command) for an application you are logged in to"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("proxyParam",
+			mcp.Description("Teleport proxy address"),
+		),
+		mcp.WithString("userParam",
+			mcp.Description("Teleport user, defaults to current local user"),
+		),
+		mcp.WithBoolean("debugParam",
+			mcp.Description("Verbose logging to stdout"),
+		),
+		mcp.WithString("cluster",
+			mcp.Description("Specify the Teleport cluster to connect"),
+		),
+		mcp.WithString("app",
+			mcp.Description("Name of the application. Optional if logged in to a single application."),
+		),
+		server.TimeoutOption(),
+	)
+
+	s.AddTool(configTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleAppConfig(ctx, request, sc)
+	})
+
+	// teleport_app_request tool
+	requestTool := mcp.NewTool("teleport_app_request",
+		mcp.WithDescription("Send an HTTP request to an application you are logged in to (see teleport_app_login), authenticating with the app certificates from tsh apps config. Returns the status, headers and a size-limited body. Redirects are not followed. In non-destructive mode only GET requests without method override headers are allowed."),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithString("proxyParam",
+			mcp.Description("Teleport proxy address"),
+		),
+		mcp.WithString("userParam",
+			mcp.Description("Teleport user, defaults to current local user"),
+		),
+		mcp.WithString("cluster",
+			mcp.Description("Specify the Teleport cluster to connect"),
+		),
+		mcp.WithString("app",
+			mcp.Required(),
+			mcp.Description("Name of the application"),
+		),
+		mcp.WithString("method",
+			mcp.Description("HTTP method (default GET)"),
+		),
+		mcp.WithString("path",
+			mcp.Description("Request path including any query string, e.g. /api/health?verbose=1 (default /)"),
+		),
+		mcp.WithObject("headers",
+			mcp.Description("Request headers as an object of header names to values"),
+		),
+		mcp.WithString("body",
+			mcp.Description("Request body"),
+		),
+		mcp.WithNumber("maxBodyBytes",
+			mcp.Description("Maximum number of response body bytes to return (default 65536, at most 1048576)"),
+			mcp.Min(1),
+			mcp.Max(1048576),
+		),
+		server.TimeoutOption(),
+	)
+
+	s.AddTool(requestTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleAppRequest(ctx, request, sc)
+	})
+
 	return nil
 }
@@ -3,6 +3,8 @@ package auth
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/giantswarm/mcp-teleport/internal/server"
 	"github.com/giantswarm/mcp-teleport/internal/teleport"
@@ -12,7 +14,7 @@ import (
 // handleLogin handles the teleport_login tool
 func handleLogin(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
 	// Create teleport client
-	client := teleport.NewClient(sc.IsDryRun(), sc.IsDebugMode())
+	client := sc.TeleportClient()
 
 	// Extract parameters
 	params := make(map[string]interface{})
@@ -26,7 +28,7 @@ func handleLogin(ctx context.Context, request mcp.CallToolRequest, sc *server.Se
 	args := teleport.FormatArgs(params)
 
 	// Execute login command
-	result := client.ExecuteCommand("login", args)
+	result := client.ExecuteCommandContext(ctx, "login", args)
 
 	// Build MCP response
 	var content []mcp.Content
@@ -54,7 +56,7 @@ func handleLogin(ctx context.Context, request mcp.CallToolRequest, sc *server.Se
 // handleStatus handles the teleport_status tool
 func handleStatus(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
 	// Create teleport client
-	client := teleport.NewClient(sc.IsDryRun(), sc.IsDebugMode())
+	client := sc.TeleportClient()
 
 	// Extract parameters
 	params := make(map[string]interface{})
@@ -66,9 +68,10 @@ func handleStatus(ctx context.Context, request mcp.CallToolRequest, sc *server.S
 
 	// Format arguments
 	args := teleport.FormatArgs(params)
+	args = append(args, "--format", "json")
 
 	// Execute status command
-	result := client.ExecuteCommand("status", args)
+	result := client.ExecuteCommandContext(ctx, "status", args)
 
 	// Build MCP response
 	var content []mcp.Content
@@ -83,20 +86,89 @@ func handleStatus(ctx context.Context, request mcp.CallToolRequest, sc *server.S
 		}, nil
 	}
 
-	content = append(content, mcp.TextContent{
-		Type: "text",
-		Text: result.Output,
-	})
+	status, err := teleport.ParseStatus(result.Stdout)
+	if err != nil {
+		// If the output cannot be parsed (e.g. in dry-run mode), return it as is
+		return mcp.NewToolResultText(result.Output), nil
+	}
 
-	return &mcp.CallToolResult{
-		Content: content,
-	}, nil
+	return server.WithDiagnostics(mcp.NewToolResultStructured(status, formatStatus(status, time.Now(), sc.ExpiryWarning())), result), nil
+}
+
+// formatStatus summarizes tsh status, flagging certificates that are expired
+// or expire within window
+func formatStatus(status *teleport.Status, now time.Time, window time.Duration) string {
+	var result strings.Builder
+
+	if status.Active == nil {
+		result.WriteString("Not logged in to any cluster. Use teleport_login to log in.\n")
+	} else {
+		writeProfile(&result, status.Active, now, window)
+	}
+
+	if len(status.Profiles) > 0 {
+		result.WriteString("\nOther profiles:\n")
+		for i := range status.Profiles {
+			profile := &status.Profiles[i]
+			result.WriteString(fmt.Sprintf("- %s as %s, %s\n", profile.Cluster, profile.Username, formatValidity(profile, now)))
+		}
+	}
+
+	return result.String()
+}
+
+// writeProfile writes the details of the active profile
+func writeProfile(result *strings.Builder, profile *teleport.Profile, now time.Time, window time.Duration) {
+	result.WriteString(fmt.Sprintf("Logged in to %s as %s (proxy %s)\n", profile.Cluster, profile.Username, profile.ProxyURL))
+	result.WriteString(fmt.Sprintf("Roles: %s\n", formatList(profile.Roles)))
+	result.WriteString(fmt.Sprintf("Logins: %s\n", formatList(profile.Logins)))
+	if profile.KubernetesEnabled {
+		kube := "enabled"
+		if profile.KubernetesCluster != "" {
+			kube += fmt.Sprintf(", cluster %s", profile.KubernetesCluster)
+		}
+		result.WriteString(fmt.Sprintf("Kubernetes: %s; groups: %s; users: %s\n", kube, formatList(profile.KubernetesGroups), formatList(profile.KubernetesUsers)))
+	} else {
+		result.WriteString("Kubernetes: disabled\n")
+	}
+	if len(profile.Databases) > 0 {
+		result.WriteString(fmt.Sprintf("Databases: %s\n", formatList(profile.Databases)))
+	}
+	if len(profile.ActiveRequests) > 0 {
+		result.WriteString(fmt.Sprintf("Active access requests: %s\n", formatList(profile.ActiveRequests)))
+	}
+	result.WriteString(fmt.Sprintf("Certificates: %s\n", formatValidity(profile, now)))
+
+	switch {
+	case profile.Expired(now):
+		result.WriteString("\nWARNING: Certificates have expired. Use teleport_login to log in again.\n")
+	case window > 0 && profile.ExpiresWithin(now, window):
+		result.WriteString(fmt.Sprintf("\nWARNING: Certificates expire in %s. Use teleport_login to renew them before starting long-running operations.\n",
+			profile.ValidUntil.Sub(now).Round(time.Minute)))
+	}
+}
+
+// formatValidity describes when the certificates of a profile expire
+func formatValidity(profile *teleport.Profile, now time.Time) string {
+	validUntil := profile.ValidUntil.Format(time.RFC3339)
+	if profile.Expired(now) {
+		return fmt.Sprintf("expired at %s", validUntil)
+	}
+	return fmt.Sprintf("valid until %s (in %s)", validUntil, profile.ValidUntil.Sub(now).Round(time.Minute))
+}
+
+// formatList joins values for display
+func formatList(values []string) string {
+	if len(values) == 0 {
+		return "none"
+	}
+	return strings.Join(values, ", ")
 }
 
 // handleListClusters handles the teleport_list_clusters tool
 func handleListClusters(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
 	// Create teleport client
-	client := teleport.NewClient(sc.IsDryRun(), sc.IsDebugMode())
+	client := sc.TeleportClient()
 
 	// Extract parameters
 	params := make(map[string]interface{})
@@ -110,7 +182,7 @@ func handleListClusters(ctx context.Context, request mcp.CallToolRequest, sc *se
 	args := teleport.FormatArgs(params)
 
 	// Execute clusters command
-	result := client.ExecuteCommand("clusters", args)
+	result := client.ExecuteCommandContext(ctx, "clusters", args)
 
 	// Build MCP response
 	var content []mcp.Content
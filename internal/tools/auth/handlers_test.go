@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport"
+	"github.com/giantswarm/mcp-teleport/internal/teleport/tshtest"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// statusJSON returns tsh status --format=json output for a profile valid until validUntil
+func statusJSON(validUntil time.Time) string {
+	return fmt.Sprintf(`{
+  "active": {
+    "profile_url": "https://teleport.example.com:443",
+    "username": "alice",
+    "cluster": "example.com",
+    "roles": ["access", "editor"],
+    "logins": ["alice", "ubuntu"],
+    "kubernetes_enabled": true,
+    "kubernetes_groups": ["developers"],
+    "kubernetes_users": ["alice"],
+    "valid_until": %q
+  },
+  "profiles": []
+}`, validUntil.Format(time.RFC3339))
+}
+
+func TestHandleStatus(t *testing.T) {
+	tests := []struct {
+		name          string
+		response      tshtest.Response
+		expiryWarning time.Duration
+		wantErr       bool
+		contains      []string
+		excludes      []string
+	}{
+		{
+			name:          "valid certificates",
+			response:      tshtest.Response{Stdout: statusJSON(time.Now().Add(8 * time.Hour))},
+			expiryWarning: time.Hour,
+			contains:      []string{"Logged in to example.com as alice", "Roles: access, editor", "Logins: alice, ubuntu", "groups: developers", "valid until"},
+			excludes:      []string{"WARNING"},
+		},
+		{
+			name:          "expiring soon",
+			response:      tshtest.Response{Stdout: statusJSON(time.Now().Add(20 * time.Minute))},
+			expiryWarning: time.Hour,
+			contains:      []string{"WARNING: Certificates expire in", "teleport_login"},
+		},
+		{
+			name:          "warning disabled",
+			response:      tshtest.Response{Stdout: statusJSON(time.Now().Add(20 * time.Minute))},
+			expiryWarning: 0,
+			excludes:      []string{"WARNING"},
+		},
+		{
+			name:          "expired",
+			response:      tshtest.Response{Stdout: statusJSON(time.Now().Add(-time.Hour))},
+			expiryWarning: time.Hour,
+			contains:      []string{"expired at", "WARNING: Certificates have expired"},
+		},
+		{
+			name:     "not logged in",
+			response: tshtest.Response{Stderr: "ERROR: Not logged in.\n", ExitCode: 1},
+			wantErr:  true,
+			contains: []string{"Not logged in"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := tshtest.NewRunner().On(`^tsh status --format json$`, tt.response)
+
+			ctx := context.Background()
+			sc, err := server.NewServerContext(ctx, server.WithRunner(runner), server.WithExpiryWarning(tt.expiryWarning))
+			if err != nil {
+				t.Fatalf("Failed to create server context: %v", err)
+			}
+			defer sc.Shutdown()
+
+			result, err := handleStatus(ctx, createTestRequest(nil), sc)
+			if err != nil {
+				t.Fatalf("handleStatus() error = %v", err)
+			}
+			if result.IsError != tt.wantErr {
+				t.Fatalf("handleStatus() IsError = %v, want %v", result.IsError, tt.wantErr)
+			}
+
+			text := result.Content[0].(mcp.TextContent).Text
+			for _, expected := range tt.contains {
+				if !strings.Contains(text, expected) {
+					t.Errorf("handleStatus() result doesn't contain %q. Got: %s", expected, text)
+				}
+			}
+			for _, unexpected := range tt.excludes {
+				if strings.Contains(text, unexpected) {
+					t.Errorf("handleStatus() result contains %q. Got: %s", unexpected, text)
+				}
+			}
+
+			if tt.wantErr {
+				return
+			}
+			status, ok := result.StructuredContent.(*teleport.Status)
+			if !ok || status.Active == nil || status.Active.Username != "alice" {
+				t.Errorf("handleStatus() structured content = %#v", result.StructuredContent)
+			}
+		})
+	}
+}
+
+func createTestRequest(params map[string]interface{}) mcp.CallToolRequest {
+	var request mcp.CallToolRequest
+	request.Params.Arguments = params
+	return request
+}
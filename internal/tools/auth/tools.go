@@ -13,6 +13,8 @@ func RegisterAuthTools(s *mcpserver.MCPServer, sc *server.ServerContext) error {
 	// teleport_login tool
 	loginTool := mcp.NewTool("teleport_login",
 		mcp.WithDescription("Login to a Teleport cluster"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
 		mcp.WithString("loginParam",
 			mcp.Description("Remote host login"),
 		),
@@ -34,6 +36,7 @@ func RegisterAuthTools(s *mcpserver.MCPServer, sc *server.ServerContext) error {
 		mcp.WithBoolean("debugParam",
 			mcp.Description("Verbose logging to stdout"),
 		),
+		server.TimeoutOption(),
 	)
 
 	s.AddTool(loginTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -42,7 +45,8 @@ func RegisterAuthTools(s *mcpserver.MCPServer, sc *server.ServerContext) error {
 
 	// teleport_status tool
 	statusTool := mcp.NewTool("teleport_status",
-		mcp.WithDescription("Display the list of proxy servers and retrieved certificates"),
+		mcp.WithDescription("Show the logged-in user, roles, logins, Kubernetes groups and certificate expiry (structured), warning when certificates expire soon"),
+		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithString("loginParam",
 			mcp.Description("Remote host login"),
 		),
@@ -64,6 +68,7 @@ func RegisterAuthTools(s *mcpserver.MCPServer, sc *server.ServerContext) error {
 		mcp.WithBoolean("debugParam",
 			mcp.Description("Verbose logging to stdout"),
 		),
+		server.TimeoutOption(),
 	)
 
 	s.AddTool(statusTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -73,6 +78,7 @@ func RegisterAuthTools(s *mcpserver.MCPServer, sc *server.ServerContext) error {
 	// teleport_list_clusters tool
 	listClustersTool := mcp.NewTool("teleport_list_clusters",
 		mcp.WithDescription("List available Teleport clusters"),
+		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithString("loginParam",
 			mcp.Description("Remote host login"),
 		),
@@ -94,6 +100,7 @@ func RegisterAuthTools(s *mcpserver.MCPServer, sc *server.ServerContext) error {
 		mcp.WithBoolean("debugParam",
 			mcp.Description("Verbose logging to stdout"),
 		),
+		server.TimeoutOption(),
 	)
 
 	s.AddTool(listClustersTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
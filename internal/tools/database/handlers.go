@@ -0,0 +1,482 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Database represents a database from tsh db ls JSON output
+type Database struct {
+	Metadata struct {
+		Name        string            `json:"name"`
+		Description string            `json:"description"`
+		Labels      map[string]string `json:"labels"`
+	} `json:"metadata"`
+	Spec struct {
+		Protocol string `json:"protocol"`
+		URI      string `json:"uri"`
+	} `json:"spec"`
+	Users struct {
+		Allowed []string `json:"allowed"`
+		Denied  []string `json:"denied"`
+	} `json:"users"`
+	DatabaseRoles struct {
+		Allowed []string `json:"allowed"`
+	} `json:"database_roles"`
+}
+
+// DatabaseConfig represents connection information from tsh db config JSON output
+type DatabaseConfig struct {
+	Name     string `json:"name"`
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	User     string `json:"user,omitempty"`
+	Database string `json:"database,omitempty"`
+	CA       string `json:"ca"`
+	Cert     string `json:"cert"`
+	Key      string `json:"key"`
+}
+
+// handleDBList handles the teleport_db_list tool
+func handleDBList(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Create teleport client
+	client := sc.TeleportClient()
+
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	// Build db ls command arguments
+	var args []string
+
+	// Add common parameters (proxy, user, etc.)
+	commonArgs := teleport.FormatArgs(params)
+	args = append(args, commonArgs...)
+
+	// Always use JSON format for parsing
+	args = append(args, "--format", "json")
+
+	if search, ok := params["search"].(string); ok && search != "" {
+		args = append(args, "--search", search)
+	}
+
+	if query, ok := params["query"].(string); ok && query != "" {
+		args = append(args, "--query", query)
+	}
+
+	if cluster, ok := params["cluster"].(string); ok && cluster != "" {
+		args = append(args, "--cluster", cluster)
+	}
+
+	// Add labels as positional arguments if provided
+	if labels, ok := params["labels"].(string); ok && labels != "" {
+		args = append(args, labels)
+	}
+
+	// Execute db ls command
+	result := client.ExecuteCommandContext(ctx, "db ls", args)
+
+	// Build MCP response
+	var content []mcp.Content
+	if !result.Success {
+		content = append(content, mcp.TextContent{
+			Type: "text",
+			Text: fmt.Sprintf("Error: %s\n%s", result.ErrorMessage, result.Output),
+		})
+		return &mcp.CallToolResult{
+			Content: content,
+			IsError: true,
+		}, nil
+	}
+
+	// Parse JSON output and format for user
+	formattedOutput, err := formatDatabasesOutput(result.Stdout, params)
+	if err != nil {
+		// If JSON parsing fails, return raw output
+		content = append(content, mcp.TextContent{
+			Type: "text",
+			Text: result.Output,
+		})
+		return &mcp.CallToolResult{
+			Content: content,
+		}, nil
+	}
+
+	content = append(content, mcp.TextContent{
+		Type: "text",
+		Text: formattedOutput,
+	})
+
+	// Surface tsh warnings that were kept out of the parsed output
+	return server.WithDiagnostics(&mcp.CallToolResult{
+		Content: content,
+	}, result), nil
+}
+
+// handleDBLogin handles the teleport_db_login tool
+func handleDBLogin(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Create teleport client
+	client := sc.TeleportClient()
+
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	db, _ := params["db"].(string)
+	labels, _ := params["labels"].(string)
+	query, _ := params["query"].(string)
+
+	// Validate that the database is selected by name, labels or query
+	if db == "" && labels == "" && query == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: "Error: Either 'db', 'labels' or 'query' must be specified to select the database.",
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	// Build db login command arguments
+	var args []string
+
+	// Add common parameters (proxy, user, etc.)
+	commonArgs := teleport.FormatArgs(params)
+	args = append(args, commonArgs...)
+
+	if cluster, ok := params["cluster"].(string); ok && cluster != "" {
+		args = append(args, "--cluster", cluster)
+	}
+
+	if labels != "" {
+		args = append(args, "--labels", labels)
+	}
+
+	if query != "" {
+		args = append(args, "--query", query)
+	}
+
+	if dbUser, ok := params["dbUser"].(string); ok && dbUser != "" {
+		args = append(args, "--db-user", dbUser)
+	}
+
+	if dbName, ok := params["dbName"].(string); ok && dbName != "" {
+		args = append(args, "--db-name", dbName)
+	}
+
+	if dbRoles, ok := params["dbRoles"].(string); ok && dbRoles != "" {
+		args = append(args, "--db-roles", dbRoles)
+	}
+
+	if requestReason, ok := params["requestReason"].(string); ok && requestReason != "" {
+		args = append(args, "--request-reason", requestReason)
+	}
+
+	if disableAccessRequest, ok := params["disableAccessRequest"].(bool); ok && disableAccessRequest {
+		args = append(args, "--disable-access-request")
+	}
+
+	// Add the database name as the final argument if specified
+	if db != "" {
+		args = append(args, db)
+	}
+
+	// Execute db login command
+	result := client.ExecuteCommandContext(ctx, "db login", args)
+
+	// Build MCP response
+	var content []mcp.Content
+	if !result.Success {
+		content = append(content, mcp.TextContent{
+			Type: "text",
+			Text: fmt.Sprintf("Error: %s\n%s", result.ErrorMessage, result.Output),
+		})
+		return &mcp.CallToolResult{
+			Content: content,
+			IsError: true,
+		}, nil
+	}
+
+	// Format success message
+	var successMessage strings.Builder
+	if db != "" {
+		successMessage.WriteString(fmt.Sprintf("Successfully logged in to database: %s\n", db))
+	} else {
+		successMessage.WriteString("Successfully logged in to the selected database.\n")
+	}
+	successMessage.WriteString("Use teleport_db_config to get connection information.\n\n")
+
+	if result.Output != "" {
+		successMessage.WriteString("Command output:\n")
+		successMessage.WriteString(result.Output)
+	}
+
+	content = append(content, mcp.TextContent{
+		Type: "text",
+		Text: successMessage.String(),
+	})
+
+	return &mcp.CallToolResult{
+		Content: content,
+	}, nil
+}
+
+// handleDBLogout handles the teleport_db_logout tool
+func handleDBLogout(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Create teleport client
+	client := sc.TeleportClient()
+
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	// tsh db logout without a name logs out of every database, so require it to be explicit
+	db, _ := params["db"].(string)
+	all, _ := params["all"].(bool)
+
+	if db == "" && !all {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: "Error: Either 'db' must be specified, or 'all' must be true to logout of all databases.",
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	if db != "" && all {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: "Error: 'db' and 'all' are mutually exclusive.",
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	// Build db logout command arguments
+	var args []string
+
+	// Add common parameters (proxy, user, etc.)
+	commonArgs := teleport.FormatArgs(params)
+	args = append(args, commonArgs...)
+
+	if cluster, ok := params["cluster"].(string); ok && cluster != "" {
+		args = append(args, "--cluster", cluster)
+	}
+
+	if db != "" {
+		args = append(args, db)
+	}
+
+	// Execute db logout command
+	result := client.ExecuteCommandContext(ctx, "db logout", args)
+
+	// Build MCP response
+	var content []mcp.Content
+	if !result.Success {
+		content = append(content, mcp.TextContent{
+			Type: "text",
+			Text: fmt.Sprintf("Error: %s\n%s", result.ErrorMessage, result.Output),
+		})
+		return &mcp.CallToolResult{
+			Content: content,
+			IsError: true,
+		}, nil
+	}
+
+	content = append(content, mcp.TextContent{
+		Type: "text",
+		Text: result.Output,
+	})
+
+	return &mcp.CallToolResult{
+		Content: content,
+	}, nil
+}
+
+// handleDBConfig handles the teleport_db_config tool
+func handleDBConfig(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Create teleport client
+	client := sc.TeleportClient()
+
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	// Build db config command arguments
+	var args []string
+
+	// Add common parameters (proxy, user, etc.)
+	commonArgs := teleport.FormatArgs(params)
+	args = append(args, commonArgs...)
+
+	// Always use JSON format for parsing
+	args = append(args, "--format", "json")
+
+	if cluster, ok := params["cluster"].(string); ok && cluster != "" {
+		args = append(args, "--cluster", cluster)
+	}
+
+	if db, ok := params["db"].(string); ok && db != "" {
+		args = append(args, db)
+	}
+
+	// Execute db config command
+	result := client.ExecuteCommandContext(ctx, "db config", args)
+
+	// Build MCP response
+	if !result.Success {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %s\n%s", result.ErrorMessage, result.Output),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	config, err := parseDatabaseConfig(result.Stdout)
+	if err != nil {
+		// If JSON parsing fails (e.g. in dry-run mode), return raw output
+		return mcp.NewToolResultText(result.Output), nil
+	}
+
+	return server.WithDiagnostics(mcp.NewToolResultStructured(config, formatDatabaseConfig(config)), result), nil
+}
+
+// parseDatabaseConfig parses JSON output from tsh db config
+func parseDatabaseConfig(jsonOutput string) (*DatabaseConfig, error) {
+	var config DatabaseConfig
+	if err := json.Unmarshal([]byte(jsonOutput), &config); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON output: %w", err)
+	}
+	return &config, nil
+}
+
+// formatDatabaseConfig formats database connection information for display
+func formatDatabaseConfig(config *DatabaseConfig) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Connection information for database: %s\n\n", config.Name))
+	result.WriteString(fmt.Sprintf("Host: %s\n", config.Host))
+	result.WriteString(fmt.Sprintf("Port: %d\n", config.Port))
+	if config.User != "" {
+		result.WriteString(fmt.Sprintf("User: %s\n", config.User))
+	}
+	if config.Database != "" {
+		result.WriteString(fmt.Sprintf("Database: %s\n", config.Database))
+	}
+	result.WriteString(fmt.Sprintf("CA: %s\n", config.CA))
+	result.WriteString(fmt.Sprintf("Cert: %s\n", config.Cert))
+	result.WriteString(fmt.Sprintf("Key: %s\n", config.Key))
+	return result.String()
+}
+
+// formatDatabasesOutput formats JSON output from tsh db ls command
+func formatDatabasesOutput(jsonOutput string, params map[string]interface{}) (string, error) {
+	if strings.TrimSpace(jsonOutput) == "" {
+		return "No databases found", nil
+	}
+
+	var databases []Database
+	if err := json.Unmarshal([]byte(jsonOutput), &databases); err != nil {
+		return "", fmt.Errorf("failed to parse JSON output: %w", err)
+	}
+
+	if len(databases) == 0 {
+		return "No databases found", nil
+	}
+
+	// Check if verbose mode is requested
+	verbose, _ := params["verbose"].(bool)
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Found %d database(s):\n\n", len(databases)))
+
+	// Sort databases by name for consistent output
+	sort.Slice(databases, func(i, j int) bool {
+		return databases[i].Metadata.Name < databases[j].Metadata.Name
+	})
+
+	for _, db := range databases {
+		result.WriteString(fmt.Sprintf("• %s", db.Metadata.Name))
+		if db.Spec.Protocol != "" {
+			result.WriteString(fmt.Sprintf(" (%s)", db.Spec.Protocol))
+		}
+		result.WriteString("\n")
+
+		if db.Metadata.Description != "" {
+			result.WriteString(fmt.Sprintf("  Description: %s\n", db.Metadata.Description))
+		}
+
+		if verbose {
+			if db.Spec.URI != "" {
+				result.WriteString(fmt.Sprintf("  URI: %s\n", db.Spec.URI))
+			}
+			if len(db.Users.Allowed) > 0 {
+				result.WriteString(fmt.Sprintf("  Allowed users: %s\n", strings.Join(db.Users.Allowed, ", ")))
+			}
+			if len(db.Users.Denied) > 0 {
+				result.WriteString(fmt.Sprintf("  Denied users: %s\n", strings.Join(db.Users.Denied, ", ")))
+			}
+			if len(db.DatabaseRoles.Allowed) > 0 {
+				result.WriteString(fmt.Sprintf("  Database roles: %s\n", strings.Join(db.DatabaseRoles.Allowed, ", ")))
+			}
+		}
+
+		if len(db.Metadata.Labels) > 0 {
+			result.WriteString("  Labels: ")
+			// Sort labels for consistent output
+			var labelKeys []string
+			for k := range db.Metadata.Labels {
+				labelKeys = append(labelKeys, k)
+			}
+			sort.Strings(labelKeys)
+
+			var labelPairs []string
+			for _, k := range labelKeys {
+				labelPairs = append(labelPairs, fmt.Sprintf("%s=%s", k, db.Metadata.Labels[k]))
+			}
+			result.WriteString(strings.Join(labelPairs, ", "))
+			result.WriteString("\n")
+		}
+		result.WriteString("\n")
+	}
+
+	if !verbose {
+		result.WriteString("Tip: Use verbose=true to see URIs and allowed database users.\n")
+	}
+
+	return result.String(), nil
+}
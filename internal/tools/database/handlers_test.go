@@ -0,0 +1,223 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport/tshtest"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleDBList(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh db ls --format json`, tshtest.Response{Stdout: tshtest.Fixture(t, "testdata/tsh_db_ls.json")})
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx, server.WithRunner(runner))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	tests := []struct {
+		name        string
+		params      map[string]interface{}
+		contains    []string
+		notContains []string
+		wantArgs    string
+	}{
+		{
+			name:        "basic list",
+			params:      map[string]interface{}{},
+			contains:    []string{"Found 2 database(s)", "• analytics-mysql (mysql)", "• orders-postgres (postgres)", "Description: Orders service database", "Labels: env=prod"},
+			notContains: []string{"Allowed users"},
+			wantArgs:    "tsh db ls --format json",
+		},
+		{
+			name:     "verbose list with filters",
+			params:   map[string]interface{}{"verbose": true, "search": "orders", "labels": "env=prod", "cluster": "leaf"},
+			contains: []string{"URI: orders-db.internal:5432", "Allowed users: readonly, orders", "Denied users: postgres"},
+			wantArgs: "tsh db ls --format json --search orders --cluster leaf env=prod",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handleDBList(ctx, createTestRequest(tt.params), sc)
+			if err != nil {
+				t.Fatalf("handleDBList() error = %v", err)
+			}
+			if result.IsError {
+				t.Fatalf("handleDBList() returned error: %+v", result.Content)
+			}
+
+			text := result.Content[0].(mcp.TextContent).Text
+			for _, expected := range tt.contains {
+				if !strings.Contains(text, expected) {
+					t.Errorf("handleDBList() result doesn't contain %q. Got: %s", expected, text)
+				}
+			}
+			for _, unexpected := range tt.notContains {
+				if strings.Contains(text, unexpected) {
+					t.Errorf("handleDBList() result shouldn't contain %q. Got: %s", unexpected, text)
+				}
+			}
+
+			calls := runner.Calls()
+			if got := strings.Join(calls[len(calls)-1], " "); got != tt.wantArgs {
+				t.Errorf("handleDBList() ran %q, want %q", got, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestHandleDBLogin(t *testing.T) {
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithDryRun(true),
+		server.WithNonDestructiveMode(true),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	tests := []struct {
+		name     string
+		params   map[string]interface{}
+		wantErr  bool
+		contains string
+	}{
+		{
+			name:     "login with user and database",
+			params:   map[string]interface{}{"db": "orders-postgres", "dbUser": "readonly", "dbName": "orders"},
+			contains: "tsh db login --db-user readonly --db-name orders orders-postgres",
+		},
+		{
+			name:     "login by labels with roles",
+			params:   map[string]interface{}{"labels": "env=prod", "dbRoles": "reader,auditor"},
+			contains: "tsh db login --labels env=prod --db-roles reader,auditor",
+		},
+		{
+			name:     "database not selected",
+			params:   map[string]interface{}{"dbUser": "readonly"},
+			wantErr:  true,
+			contains: "must be specified",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handleDBLogin(ctx, createTestRequest(tt.params), sc)
+			if err != nil {
+				t.Fatalf("handleDBLogin() error = %v", err)
+			}
+			if result.IsError != tt.wantErr {
+				t.Errorf("handleDBLogin() IsError = %v, want %v", result.IsError, tt.wantErr)
+			}
+			if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, tt.contains) {
+				t.Errorf("handleDBLogin() result doesn't contain %q. Got: %s", tt.contains, text)
+			}
+		})
+	}
+}
+
+func TestHandleDBLogout(t *testing.T) {
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx, server.WithDryRun(true))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	tests := []struct {
+		name     string
+		params   map[string]interface{}
+		wantErr  bool
+		contains string
+	}{
+		{
+			name:     "logout of one database",
+			params:   map[string]interface{}{"db": "orders-postgres"},
+			contains: "tsh db logout orders-postgres",
+		},
+		{
+			name:     "logout of all databases",
+			params:   map[string]interface{}{"all": true},
+			contains: "tsh db logout",
+		},
+		{
+			name:     "nothing selected",
+			params:   map[string]interface{}{},
+			wantErr:  true,
+			contains: "must be specified",
+		},
+		{
+			name:     "db and all",
+			params:   map[string]interface{}{"db": "orders-postgres", "all": true},
+			wantErr:  true,
+			contains: "mutually exclusive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handleDBLogout(ctx, createTestRequest(tt.params), sc)
+			if err != nil {
+				t.Fatalf("handleDBLogout() error = %v", err)
+			}
+			if result.IsError != tt.wantErr {
+				t.Errorf("handleDBLogout() IsError = %v, want %v", result.IsError, tt.wantErr)
+			}
+			if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, tt.contains) {
+				t.Errorf("handleDBLogout() result doesn't contain %q. Got: %s", tt.contains, text)
+			}
+		})
+	}
+}
+
+func TestHandleDBConfig(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh db config --format json orders-postgres$`, tshtest.Response{Stdout: tshtest.Fixture(t, "testdata/tsh_db_config.json")}).
+		On(`^tsh db config `, tshtest.Response{Stderr: "ERROR: you are not logged into any databases\n", ExitCode: 1})
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx, server.WithRunner(runner))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	result, err := handleDBConfig(ctx, createTestRequest(map[string]interface{}{"db": "orders-postgres"}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleDBConfig() failed: %v %+v", err, result)
+	}
+
+	config, ok := result.StructuredContent.(*DatabaseConfig)
+	if !ok {
+		t.Fatalf("handleDBConfig() structured content is %T, want *DatabaseConfig", result.StructuredContent)
+	}
+	if config.Host != "teleport.example.com" || config.Port != 443 || config.User != "readonly" || config.Database != "orders" {
+		t.Errorf("handleDBConfig() unexpected config: %+v", config)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "Port: 443") {
+		t.Errorf("handleDBConfig() text doesn't contain the port. Got: %s", text)
+	}
+
+	result, err = handleDBConfig(ctx, createTestRequest(map[string]interface{}{}), sc)
+	if err != nil {
+		t.Fatalf("handleDBConfig() error = %v", err)
+	}
+	if !result.IsError {
+		t.Error("handleDBConfig() should fail when not logged in")
+	}
+}
+
+// Helper function to create test request
+func createTestRequest(params map[string]interface{}) mcp.CallToolRequest {
+	var request mcp.CallToolRequest
+	request.Params.Arguments = params
+	return request
+}
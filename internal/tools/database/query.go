@@ -0,0 +1,328 @@
+package database
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/giantswarm/mcp-teleport/internal/policy"
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// defaultMaxRows is the number of rows returned when maxRows is not set
+	defaultMaxRows = 100
+	// maxMaxRows is the upper bound for maxRows
+	maxMaxRows = 1000
+	// maxCellLength is the number of characters shown per value in the table
+	maxCellLength = 200
+)
+
+// QueryResult is the result set of a query run with teleport_db_query
+type QueryResult struct {
+	Columns []string   `json:"columns"`
+	Rows    [][]string `json:"rows"`
+	// RowCount is the number of rows the statement returned, including rows not shown
+	RowCount int `json:"rowCount"`
+	// Truncated is set when rows beyond maxRows were dropped
+	Truncated bool `json:"truncated"`
+}
+
+// handleDBQuery handles the teleport_db_query tool
+func handleDBQuery(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	// Validate required parameters
+	db, _ := params["db"].(string)
+	statement, _ := params["sql"].(string)
+	if db == "" || strings.TrimSpace(statement) == "" {
+		return queryError("Error: Both 'db' and 'sql' are required"), nil
+	}
+
+	// The statement is passed to psql -c or mysql -e, so it must be a single
+	// statement without client commands regardless of the mode
+	if err := policy.CheckSQLStatement(statement); err != nil {
+		return queryError(fmt.Sprintf("Error: Statement rejected: %v", err)), nil
+	}
+
+	// In non-destructive mode only read-only statements may run
+	readOnly := sc.IsNonDestructiveMode()
+	if readOnly {
+		if err := policy.CheckReadOnlySQL(statement); err != nil {
+			return queryError(fmt.Sprintf("Error: Statement rejected in non-destructive mode: %v. Only single SELECT, SHOW or EXPLAIN statements are allowed; restart the server with --non-destructive=false to run other statements.", err)), nil
+		}
+	}
+
+	maxRows := defaultMaxRows
+	if value, ok := params["maxRows"].(float64); ok && value > 0 {
+		maxRows = int(value)
+		if maxRows > maxMaxRows {
+			maxRows = maxMaxRows
+		}
+	}
+
+	// Create teleport client
+	client := sc.TeleportClient()
+
+	// Add common parameters (proxy, user, etc.)
+	commonArgs := teleport.FormatArgs(params)
+	if cluster, ok := params["cluster"].(string); ok && cluster != "" {
+		commonArgs = append(commonArgs, "--cluster", cluster)
+	}
+
+	dbUser, _ := params["dbUser"].(string)
+	dbName, _ := params["dbName"].(string)
+	protocol, _ := params["protocol"].(string)
+
+	if sc.IsDryRun() {
+		return mcp.NewToolResultText(fmt.Sprintf("DRY RUN: Would open a tunnel with: tsh proxy db --tunnel %s %s\nDRY RUN: Would run on %s: %s",
+			strings.Join(tunnelArgs(commonArgs, dbUser, dbName), " "), db, db, statement)), nil
+	}
+
+	// Fall back to the defaults chosen at teleport_db_login
+	if dbUser == "" || dbName == "" {
+		result := client.ExecuteCommandContext(ctx, "db config", append(append([]string{}, commonArgs...), "--format", "json", db))
+		if result.Success {
+			if config, err := parseDatabaseConfig(result.Stdout); err == nil {
+				if dbUser == "" {
+					dbUser = config.User
+				}
+				if dbName == "" {
+					dbName = config.Database
+				}
+			}
+		}
+	}
+
+	if protocol == "" {
+		var err error
+		protocol, err = lookupProtocol(ctx, client, commonArgs, db)
+		if err != nil {
+			return queryError(fmt.Sprintf("Error: %v", err)), nil
+		}
+	}
+
+	// Open the tunnel and run the statement through it
+	tunnel, err := client.StartDBTunnel(ctx, db, tunnelArgs(commonArgs, dbUser, dbName))
+	if err != nil {
+		return queryError(fmt.Sprintf("Error: Failed to open database tunnel: %v", err)), nil
+	}
+	defer tunnel.Close()
+
+	program, args, err := sqlClientCommand(protocol, tunnel.Addr, dbUser, dbName, statement, readOnly)
+	if err != nil {
+		return queryError(fmt.Sprintf("Error: %v", err)), nil
+	}
+
+	result := client.ExecuteProgramContext(ctx, program, args)
+	if !result.Success {
+		return queryError(fmt.Sprintf("Error: %s\n%s", result.ErrorMessage, result.Output)), nil
+	}
+
+	queryResult, err := parseQueryOutput(protocol, result.Stdout, maxRows)
+	if err != nil {
+		// If the output cannot be parsed, return it as is
+		return mcp.NewToolResultText(result.Output), nil
+	}
+
+	// Notices and warnings from the database client go to stderr
+	return server.WithDiagnostics(mcp.NewToolResultStructured(queryResult, formatQueryResult(queryResult)), result), nil
+}
+
+// queryError builds an error result for teleport_db_query
+func queryError(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+		IsError: true,
+	}
+}
+
+// tunnelArgs builds the tsh proxy db arguments selecting the database user and name
+func tunnelArgs(commonArgs []string, dbUser, dbName string) []string {
+	args := append([]string{}, commonArgs...)
+	if dbUser != "" {
+		args = append(args, "--db-user", dbUser)
+	}
+	if dbName != "" {
+		args = append(args, "--db-name", dbName)
+	}
+	return args
+}
+
+// lookupProtocol finds the protocol of db in tsh db ls output
+func lookupProtocol(ctx context.Context, client *teleport.Client, commonArgs []string, db string) (string, error) {
+	args := append(append([]string{}, commonArgs...), "--format", "json", "--search", db)
+	result := client.ExecuteCommandContext(ctx, "db ls", args)
+	if !result.Success {
+		return "", fmt.Errorf("failed to look up database %s: %s\n%s", db, result.ErrorMessage, result.Output)
+	}
+
+	var databases []Database
+	if err := json.Unmarshal([]byte(result.Stdout), &databases); err != nil {
+		return "", fmt.Errorf("failed to parse tsh db ls output: %w", err)
+	}
+	for _, d := range databases {
+		if d.Metadata.Name == db {
+			return d.Spec.Protocol, nil
+		}
+	}
+	return "", fmt.Errorf("database %s not found; check 'teleport_db_list' for available databases", db)
+}
+
+// sqlClientCommand returns the database client invocation that runs statement
+// against the tunnel at addr. With readOnly set, the session is additionally
+// put into read-only mode on the server.
+func sqlClientCommand(protocol, addr, dbUser, dbName, statement string, readOnly bool) (string, []string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid tunnel address %q: %w", addr, err)
+	}
+
+	switch protocol {
+	case "postgres", "cockroachdb":
+		conninfo := []string{
+			"host=" + conninfoValue(host),
+			"port=" + port,
+			"sslmode=disable",
+		}
+		if dbUser != "" {
+			conninfo = append(conninfo, "user="+conninfoValue(dbUser))
+		}
+		if dbName != "" {
+			conninfo = append(conninfo, "dbname="+conninfoValue(dbName))
+		}
+		if readOnly {
+			conninfo = append(conninfo, "options="+conninfoValue("-c default_transaction_read_only=on"))
+		}
+		return "psql", []string{
+			"--no-psqlrc", "--quiet", "--csv",
+			"--set", "ON_ERROR_STOP=1",
+			"--command", statement,
+			strings.Join(conninfo, " "),
+		}, nil
+	case "mysql":
+		args := []string{
+			"--no-defaults", "--protocol=TCP",
+			"--host=" + host, "--port=" + port,
+			"--batch",
+		}
+		if dbUser != "" {
+			args = append(args, "--user="+dbUser)
+		}
+		if dbName != "" {
+			args = append(args, "--database="+dbName)
+		}
+		if readOnly {
+			statement = "SET SESSION TRANSACTION READ ONLY; " + statement
+		}
+		args = append(args, "--execute="+statement)
+		return "mysql", args, nil
+	default:
+		return "", nil, fmt.Errorf("queries are not supported for %q databases (supported: postgres, cockroachdb, mysql)", protocol)
+	}
+}
+
+// conninfoValue quotes a value for a libpq connection string
+func conninfoValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
+
+// parseQueryOutput parses psql --csv or mysql --batch output, keeping at most maxRows rows
+func parseQueryOutput(protocol, output string, maxRows int) (*QueryResult, error) {
+	var records [][]string
+	if protocol == "mysql" {
+		records = parseMySQLBatch(output)
+	} else {
+		reader := csv.NewReader(strings.NewReader(output))
+		reader.FieldsPerRecord = -1
+		var err error
+		if records, err = reader.ReadAll(); err != nil {
+			return nil, fmt.Errorf("failed to parse CSV output: %w", err)
+		}
+	}
+
+	result := &QueryResult{Columns: []string{}, Rows: [][]string{}}
+	if len(records) == 0 {
+		return result, nil
+	}
+
+	result.Columns = records[0]
+	rows := records[1:]
+	result.RowCount = len(rows)
+	if len(rows) > maxRows {
+		rows = rows[:maxRows]
+		result.Truncated = true
+	}
+	result.Rows = rows
+	return result, nil
+}
+
+// parseMySQLBatch parses tab-separated mysql --batch output, undoing its escapes
+func parseMySQLBatch(output string) [][]string {
+	replacer := strings.NewReplacer(`\\`, `\`, `\t`, "\t", `\n`, "\n", `\0`, "\x00")
+	var records [][]string
+	for _, line := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		for i, field := range fields {
+			fields[i] = replacer.Replace(field)
+		}
+		records = append(records, fields)
+	}
+	return records
+}
+
+// formatQueryResult renders a query result as a Markdown table
+func formatQueryResult(result *QueryResult) string {
+	if len(result.Columns) == 0 {
+		return "Statement executed successfully; it returned no rows"
+	}
+
+	var output strings.Builder
+	output.WriteString("| " + strings.Join(formatCells(result.Columns), " | ") + " |\n")
+	output.WriteString("|" + strings.Repeat(" --- |", len(result.Columns)) + "\n")
+	for _, row := range result.Rows {
+		output.WriteString("| " + strings.Join(formatCells(row), " | ") + " |\n")
+	}
+
+	if result.Truncated {
+		output.WriteString(fmt.Sprintf("\n(showing first %d of %d rows; raise maxRows or add a LIMIT to see more)\n", len(result.Rows), result.RowCount))
+	} else {
+		output.WriteString(fmt.Sprintf("\n(%d row(s))\n", result.RowCount))
+	}
+	return output.String()
+}
+
+// formatCells escapes and shortens values for a Markdown table row
+func formatCells(values []string) []string {
+	cells := make([]string, len(values))
+	for i, value := range values {
+		if runes := []rune(value); len(runes) > maxCellLength {
+			value = string(runes[:maxCellLength]) + "…"
+		}
+		value = strings.ReplaceAll(value, "|", `\|`)
+		value = strings.ReplaceAll(value, "\n", `\n`)
+		cells[i] = value
+	}
+	return cells
+}
@@ -0,0 +1,134 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport/tshtest"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const tunnelBanner = "Started authenticated tunnel for the PostgreSQL database \"orders-postgres\" in cluster \"example\" on 127.0.0.1:54321.\n"
+
+func TestHandleDBQuery(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh db config --format json orders-postgres$`, tshtest.Response{Stdout: tshtest.Fixture(t, "testdata/tsh_db_config.json")}).
+		On(`^tsh db ls --format json --search orders-postgres$`, tshtest.Response{Stdout: tshtest.Fixture(t, "testdata/tsh_db_ls.json")}).
+		On(`^tsh proxy db --tunnel --db-user readonly --db-name orders orders-postgres$`, tshtest.Response{Stdout: tunnelBanner, Block: true}).
+		On(`^psql .*--command SELECT id, status FROM orders .*host='127.0.0.1' port=54321 sslmode=disable user='readonly' dbname='orders' options='-c default_transaction_read_only=on'$`,
+			tshtest.Response{Stdout: "id,status\n1,open\n2,\"needs | review\"\n3,closed\n"})
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx, server.WithRunner(runner), server.WithNonDestructiveMode(true))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	result, err := handleDBQuery(ctx, createTestRequest(map[string]interface{}{
+		"db":      "orders-postgres",
+		"sql":     "SELECT id, status FROM orders ORDER BY id",
+		"maxRows": float64(2),
+	}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleDBQuery() failed: %v %+v", err, result)
+	}
+
+	queryResult, ok := result.StructuredContent.(*QueryResult)
+	if !ok {
+		t.Fatalf("handleDBQuery() structured content is %T, want *QueryResult", result.StructuredContent)
+	}
+	if strings.Join(queryResult.Columns, ",") != "id,status" || len(queryResult.Rows) != 2 || queryResult.RowCount != 3 || !queryResult.Truncated {
+		t.Errorf("handleDBQuery() unexpected result: %+v", queryResult)
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, expected := range []string{"| id | status |", "| 1 | open |", `| 2 | needs \| review |`, "showing first 2 of 3 rows"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("handleDBQuery() result doesn't contain %q. Got: %s", expected, text)
+		}
+	}
+}
+
+func TestHandleDBQueryMySQL(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh proxy db --tunnel --db-user analyst analytics-mysql$`, tshtest.Response{Stdout: "Started authenticated tunnel for the MySQL database \"analytics-mysql\" in cluster \"example\" on 127.0.0.1:3307.\n", Block: true}).
+		On(`^mysql --no-defaults --protocol=TCP --host=127.0.0.1 --port=3307 --batch --user=analyst --execute=SHOW TABLES$`,
+			tshtest.Response{Stdout: "Tables_in_analytics\nevents\npage\\tviews\n"})
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx, server.WithRunner(runner), server.WithNonDestructiveMode(false))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	result, err := handleDBQuery(ctx, createTestRequest(map[string]interface{}{
+		"db":       "analytics-mysql",
+		"sql":      "SHOW TABLES",
+		"dbUser":   "analyst",
+		"dbName":   "",
+		"protocol": "mysql",
+	}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleDBQuery() failed: %v %+v", err, result)
+	}
+
+	queryResult := result.StructuredContent.(*QueryResult)
+	if queryResult.RowCount != 2 || queryResult.Rows[1][0] != "page\tviews" || queryResult.Truncated {
+		t.Errorf("handleDBQuery() unexpected result: %+v", queryResult)
+	}
+}
+
+func TestHandleDBQueryRejected(t *testing.T) {
+	runner := tshtest.NewRunner()
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx, server.WithRunner(runner), server.WithNonDestructiveMode(true))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	tests := []struct {
+		name     string
+		params   map[string]interface{}
+		contains string
+	}{
+		{
+			name:     "missing statement",
+			params:   map[string]interface{}{"db": "orders-postgres"},
+			contains: "required",
+		},
+		{
+			name:     "write in non-destructive mode",
+			params:   map[string]interface{}{"db": "orders-postgres", "sql": "DELETE FROM orders"},
+			contains: "non-destructive mode",
+		},
+		{
+			name:     "client meta-command",
+			params:   map[string]interface{}{"db": "orders-postgres", "sql": `\! id`},
+			contains: "meta-commands are not allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handleDBQuery(ctx, createTestRequest(tt.params), sc)
+			if err != nil {
+				t.Fatalf("handleDBQuery() error = %v", err)
+			}
+			if !result.IsError {
+				t.Fatalf("handleDBQuery() should have failed, got: %+v", result)
+			}
+			if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, tt.contains) {
+				t.Errorf("handleDBQuery() result doesn't contain %q. Got: %s", tt.contains, text)
+			}
+		})
+	}
+
+	if calls := runner.Calls(); len(calls) != 0 {
+		t.Errorf("Rejected statements must not run anything, got: %v", calls)
+	}
+}
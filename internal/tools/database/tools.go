@@ -1,12 +1,212 @@
 package database
 
 import (
+	"context"
+
 	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/mark3labs/mcp-go/mcp"
 	mcpserver "github.com/mark3labs/mcp-go/server"
 )
 
 // RegisterDatabaseTools registers database-related tools with the MCP server
 func RegisterDatabaseTools(s *mcpserver.MCPServer, sc *server.ServerContext) error {
-	// TODO: Implement database tools
+	// teleport_db_list tool
+	listTool := mcp.NewTool("teleport_db_list",
+		mcp.WithDescription("List databases available through Teleport"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("proxyParam",
+			mcp.Description("Teleport proxy address"),
+		),
+		mcp.WithString("userParam",
+			mcp.Description("Teleport user, defaults to current local user"),
+		),
+		mcp.WithString("identityParam",
+			mcp.Description("Identity file"),
+		),
+		mcp.WithBoolean("insecureParam",
+			mcp.Description("Do not verify server's certificate and host name. Use only in test environments"),
+		),
+		mcp.WithBoolean("debugParam",
+			mcp.Description("Verbose logging to stdout"),
+		),
+		mcp.WithString("search",
+			mcp.Description("List of comma separated search keywords or phrases enclosed in quotations (e.g. foo,bar,\"some phrase\")"),
+		),
+		mcp.WithString("query",
+			mcp.Description("Query by predicate language enclosed in single quotes. Supports ==, !=, &&, and || (e.g. 'labels[\"key1\"] == \"value1\" && labels[\"key2\"] != \"value2\"')"),
+		),
+		mcp.WithString("labels",
+			mcp.Description("List of comma separated labels to filter by (e.g. key1=value1,key2=value2)"),
+		),
+		mcp.WithBoolean("verbose",
+			mcp.Description("Show database URIs, allowed users and labels"),
+		),
+		mcp.WithString("cluster",
+			mcp.Description("Specify the Teleport cluster to connect"),
+		),
+		server.TimeoutOption(),
+	)
+
+	s.AddTool(listTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleDBList(ctx, request, sc)
+	})
+
+	// teleport_db_login tool
+	loginTool := mcp.NewTool("teleport_db_login",
+		mcp.WithDescription("Retrieve database credentials for a database available through Teleport. Certificates are stored in the local tsh profile; no database is modified."),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("proxyParam",
+			mcp.Description("Teleport proxy address"),
+		),
+		mcp.WithString("userParam",
+			mcp.Description("Teleport user, defaults to current local user"),
+		),
+		mcp.WithString("identityParam",
+			mcp.Description("Identity file"),
+		),
+		mcp.WithBoolean("insecureParam",
+			mcp.Description("Do not verify server's certificate and host name. Use only in test environments"),
+		),
+		mcp.WithBoolean("debugParam",
+			mcp.Description("Verbose logging to stdout"),
+		),
+		mcp.WithString("cluster",
+			mcp.Description("Specify the Teleport cluster to connect"),
+		),
+		mcp.WithString("db",
+			mcp.Description("Name of the database to login to. Check 'teleport_db_list' for available databases."),
+		),
+		mcp.WithString("labels",
+			mcp.Description("List of comma separated labels to select the database by, instead of its name (e.g. key1=value1,key2=value2)"),
+		),
+		mcp.WithString("query",
+			mcp.Description("Query by predicate language to select the database by, instead of its name"),
+		),
+		mcp.WithString("dbUser",
+			mcp.Description("Database user to configure as default"),
+		),
+		mcp.WithString("dbName",
+			mcp.Description("Database name to configure as default"),
+		),
+		mcp.WithString("dbRoles",
+			mcp.Description("List of comma separated database roles to use for auto-provisioned users"),
+		),
+		mcp.WithString("requestReason",
+			mcp.Description("Reason for requesting access"),
+		),
+		mcp.WithBoolean("disableAccessRequest",
+			mcp.Description("Disable automatic resource access requests"),
+		),
+		server.TimeoutOption(),
+	)
+
+	s.AddTool(loginTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleDBLogin(ctx, request, sc)
+	})
+
+	// teleport_db_logout tool
+	logoutTool := mcp.NewTool("teleport_db_logout",
+		mcp.WithDescription("Remove locally stored database credentials obtained with teleport_db_login"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("proxyParam",
+			mcp.Description("Teleport proxy address"),
+		),
+		mcp.WithString("userParam",
+			mcp.Description("Teleport user, defaults to current local user"),
+		),
+		mcp.WithBoolean("debugParam",
+			mcp.Description("Verbose logging to stdout"),
+		),
+		mcp.WithString("cluster",
+			mcp.Description("Specify the Teleport cluster to connect"),
+		),
+		mcp.WithString("db",
+			mcp.Description("Name of the database to logout of"),
+		),
+		mcp.WithBoolean("all",
+			mcp.Description("Logout of all databases. Mutually exclusive with db."),
+		),
+		server.TimeoutOption(),
+	)
+
+	s.AddTool(logoutTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleDBLogout(ctx, request, sc)
+	})
+
+	// teleport_db_config tool
+	configTool := mcp.NewTool("teleport_db_config",
+		mcp.WithDescription("Show connection information (host, port, user, database and certificate paths) for a database you are logged in to"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("proxyParam",
+			mcp.Description("Teleport proxy address"),
+		),
+		mcp.WithString("userParam",
+			mcp.Description("Teleport user, defaults to current local user"),
+		),
+		mcp.WithBoolean("debugParam",
+			mcp.Description("Verbose logging to stdout"),
+		),
+		mcp.WithString("cluster",
+			mcp.Description("Specify the Teleport cluster to connect"),
+		),
+		mcp.WithString("db",
+			mcp.Description("Name of the database. Optional if logged in to a single database."),
+		),
+		server.TimeoutOption(),
+	)
+
+	s.AddTool(configTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleDBConfig(ctx, request, sc)
+	})
+
+	// teleport_db_query tool
+	queryTool := mcp.NewTool("teleport_db_query",
+		mcp.WithDescription("Run a single SQL statement against a PostgreSQL or MySQL database through an authenticated Teleport tunnel (tsh proxy db --tunnel), so the query is recorded in Teleport's audit log. Returns the rows as a bounded table. In non-destructive mode only SELECT, SHOW and EXPLAIN statements are allowed and the session is read-only. Requires psql or mysql to be installed."),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithString("proxyParam",
+			mcp.Description("Teleport proxy address"),
+		),
+		mcp.WithString("userParam",
+			mcp.Description("Teleport user, defaults to current local user"),
+		),
+		mcp.WithBoolean("debugParam",
+			mcp.Description("Verbose logging to stdout"),
+		),
+		mcp.WithString("cluster",
+			mcp.Description("Specify the Teleport cluster to connect"),
+		),
+		mcp.WithString("db",
+			mcp.Required(),
+			mcp.Description("Name of the database. Check 'teleport_db_list' for available databases."),
+		),
+		mcp.WithString("sql",
+			mcp.Required(),
+			mcp.Description("The SQL statement to run. Only a single statement is allowed."),
+		),
+		mcp.WithString("dbUser",
+			mcp.Description("Database user; defaults to the user chosen at teleport_db_login"),
+		),
+		mcp.WithString("dbName",
+			mcp.Description("Database name; defaults to the name chosen at teleport_db_login"),
+		),
+		mcp.WithString("protocol",
+			mcp.Description("Database protocol; looked up with tsh db ls if not set"),
+			mcp.Enum("postgres", "cockroachdb", "mysql"),
+		),
+		mcp.WithNumber("maxRows",
+			mcp.Description("Maximum number of rows to return (default 100, at most 1000)"),
+			mcp.Min(1),
+			mcp.Max(1000),
+		),
+		server.TimeoutOption(),
+	)
+
+	s.AddTool(queryTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleDBQuery(ctx, request, sc)
+	})
+
 	return nil
 }
@@ -0,0 +1,209 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// defaultMaxBytes is the number of output bytes returned when maxBytes is not set
+	defaultMaxBytes = 64 * 1024
+	// maxMaxBytes is the upper bound for maxBytes
+	maxMaxBytes = server.JobOutputLimit
+	// cancelWait is how long teleport_job_cancel waits for the job to stop
+	cancelWait = 10 * time.Second
+)
+
+// JobStatus is the state of a background job together with a chunk of its output
+type JobStatus struct {
+	server.JobInfo
+	server.JobOutput
+}
+
+// JobList is the result of teleport_job_list
+type JobList struct {
+	Jobs []server.JobInfo `json:"jobs"`
+}
+
+// handleJobStatus handles the teleport_job_status tool
+func handleJobStatus(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	jobID, _ := params["jobId"].(string)
+	if jobID == "" {
+		return jobError("Error: 'jobId' is required. Use teleport_job_list to find jobs."), nil
+	}
+
+	var offset int64
+	if value, ok := params["offset"].(float64); ok && value > 0 {
+		offset = int64(value)
+	}
+
+	maxBytes := defaultMaxBytes
+	if value, ok := params["maxBytes"].(float64); ok && value > 0 {
+		maxBytes = int(value)
+		if maxBytes > maxMaxBytes {
+			maxBytes = maxMaxBytes
+		}
+	}
+
+	job, err := sc.Jobs().Get(ctx, jobID)
+	if err != nil {
+		return jobError(fmt.Sprintf("Error: %v. Use teleport_job_list to find jobs.", err)), nil
+	}
+
+	// Read the output after the state so a finished job's output is complete
+	status := &JobStatus{JobInfo: job.Info()}
+	status.JobOutput = job.Output(offset, maxBytes)
+
+	return mcp.NewToolResultStructured(status, formatJobStatus(status)), nil
+}
+
+// handleJobCancel handles the teleport_job_cancel tool
+func handleJobCancel(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	jobID, _ := params["jobId"].(string)
+	if jobID == "" {
+		return jobError("Error: 'jobId' is required. Use teleport_job_list to find jobs."), nil
+	}
+
+	job, err := sc.Jobs().Get(ctx, jobID)
+	if err != nil {
+		return jobError(fmt.Sprintf("Error: %v. Use teleport_job_list to find jobs.", err)), nil
+	}
+
+	if info := job.Info(); info.State != server.JobRunning {
+		return mcp.NewToolResultStructured(info, fmt.Sprintf("Job %s already finished: %s", info.ID, info.State)), nil
+	}
+
+	if _, err := sc.Jobs().Cancel(ctx, jobID); err != nil {
+		return jobError(fmt.Sprintf("Error: %v", err)), nil
+	}
+
+	// Wait for the tsh process to exit so the reported state is final
+	select {
+	case <-job.Done():
+	case <-ctx.Done():
+	case <-time.After(cancelWait):
+	}
+
+	info := job.Info()
+	if info.State == server.JobRunning {
+		return mcp.NewToolResultStructured(info, fmt.Sprintf("Cancelled job %s; it is still stopping. Check it with teleport_job_status.", info.ID)), nil
+	}
+	return mcp.NewToolResultStructured(info, fmt.Sprintf("Cancelled job %s: %s", info.ID, info.State)), nil
+}
+
+// handleJobList handles the teleport_job_list tool
+func handleJobList(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	state, _ := params["state"].(string)
+
+	list := &JobList{Jobs: []server.JobInfo{}}
+	for _, info := range sc.Jobs().List(ctx) {
+		if state == "" || string(info.State) == state {
+			list.Jobs = append(list.Jobs, info)
+		}
+	}
+
+	return mcp.NewToolResultStructured(list, formatJobList(list)), nil
+}
+
+// jobError builds an error result for the job tools
+func jobError(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+		IsError: true,
+	}
+}
+
+// formatJobStatus formats a job and a chunk of its output for display
+func formatJobStatus(status *JobStatus) string {
+	var result strings.Builder
+	writeJob(&result, &status.JobInfo)
+
+	if status.OutputBytes == 0 {
+		result.WriteString("\nNo output yet\n")
+	} else {
+		result.WriteString(fmt.Sprintf("\nOutput (bytes %d-%d of %d):\n", status.Offset, status.NextOffset, status.OutputBytes))
+		result.WriteString(status.Output)
+		if status.Output != "" && !strings.HasSuffix(status.Output, "\n") {
+			result.WriteString("\n")
+		}
+	}
+
+	if status.Skipped {
+		result.WriteString(fmt.Sprintf("\n(the first %d bytes were dropped; at least the last %d bytes of output are kept)\n", status.DroppedBytes, server.JobOutputLimit))
+	}
+	switch {
+	case status.More:
+		result.WriteString(fmt.Sprintf("\n(more output is available; call again with offset=%d)\n", status.NextOffset))
+	case status.State == server.JobRunning:
+		result.WriteString(fmt.Sprintf("\n(the job is still running; call again with offset=%d for new output)\n", status.NextOffset))
+	}
+	return result.String()
+}
+
+// formatJobList formats a list of jobs for display
+func formatJobList(list *JobList) string {
+	if len(list.Jobs) == 0 {
+		return "No background jobs"
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Found %d background job(s):\n", len(list.Jobs)))
+	for _, info := range list.Jobs {
+		result.WriteString("\n")
+		writeJob(&result, &info)
+	}
+	return result.String()
+}
+
+// writeJob writes the state of a job
+func writeJob(result *strings.Builder, info *server.JobInfo) {
+	duration := (time.Duration(info.DurationMs) * time.Millisecond).String()
+	if info.State == server.JobRunning {
+		result.WriteString(fmt.Sprintf("Job %s (%s): running for %s\n", info.ID, info.Tool, duration))
+	} else {
+		result.WriteString(fmt.Sprintf("Job %s (%s): %s after %s\n", info.ID, info.Tool, info.State, duration))
+	}
+	result.WriteString(fmt.Sprintf("  Command: %s\n", info.Command))
+	result.WriteString(fmt.Sprintf("  Started: %s\n", info.StartedAt.Format(time.RFC3339)))
+	if info.ExitCode != nil {
+		result.WriteString(fmt.Sprintf("  Exit code: %d\n", *info.ExitCode))
+	}
+	if info.Error != "" {
+		result.WriteString(fmt.Sprintf("  Error: %s\n", info.Error))
+	}
+	result.WriteString(fmt.Sprintf("  Output: %d bytes\n", info.OutputBytes))
+}
@@ -0,0 +1,181 @@
+package jobs
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport"
+	"github.com/giantswarm/mcp-teleport/internal/teleport/tshtest"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func createTestRequest(params map[string]interface{}) mcp.CallToolRequest {
+	var request mcp.CallToolRequest
+	request.Params.Arguments = params
+	return request
+}
+
+func resultText(result *mcp.CallToolResult) string {
+	if len(result.Content) == 0 {
+		return ""
+	}
+	text, _ := result.Content[0].(mcp.TextContent)
+	return text.Text
+}
+
+// startJob starts tsh ssh with args as a background job
+func startJob(t *testing.T, sc *server.ServerContext, args ...string) *server.Job {
+	t.Helper()
+	client := sc.TeleportClient()
+	job, err := sc.Jobs().Start(context.Background(), "teleport_ssh", "tsh ssh "+strings.Join(args, " "), time.Minute, func(ctx context.Context, output io.Writer) *teleport.ExecutionResult {
+		return client.StreamCommandContext(ctx, "ssh", args, output, output)
+	})
+	if err != nil {
+		t.Fatalf("Failed to start job: %v", err)
+	}
+	return job
+}
+
+func newTestContext(t *testing.T, runner *tshtest.Runner) *server.ServerContext {
+	t.Helper()
+	sc, err := server.NewServerContext(context.Background(), server.WithRunner(runner))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	t.Cleanup(func() { sc.Shutdown() })
+	return sc
+}
+
+func TestJobStatus(t *testing.T) {
+	runner := tshtest.NewRunner().On(`^tsh ssh root@node journalctl$`, tshtest.Response{Stdout: "line 1\nline 2\nline 3\n"})
+	sc := newTestContext(t, runner)
+
+	job := startJob(t, sc, "root@node", "journalctl")
+	<-job.Done()
+
+	// Page through the output
+	result, err := handleJobStatus(context.Background(), createTestRequest(map[string]interface{}{"jobId": job.ID(), "maxBytes": float64(7)}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleJobStatus() failed: %v %+v", err, result)
+	}
+	status := result.StructuredContent.(*JobStatus)
+	if status.Output != "line 1\n" || !status.More || status.NextOffset != 7 {
+		t.Errorf("Unexpected first chunk: %+v", status.JobOutput)
+	}
+	text := resultText(result)
+	for _, expected := range []string{"Job job-1 (teleport_ssh): succeeded", "Command: tsh ssh root@node journalctl", "Exit code: 0", "call again with offset=7"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in result, got: %s", expected, text)
+		}
+	}
+
+	result, err = handleJobStatus(context.Background(), createTestRequest(map[string]interface{}{"jobId": job.ID(), "offset": float64(7)}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleJobStatus() failed: %v %+v", err, result)
+	}
+	status = result.StructuredContent.(*JobStatus)
+	if status.Output != "line 2\nline 3\n" || status.More {
+		t.Errorf("Unexpected second chunk: %+v", status.JobOutput)
+	}
+}
+
+func TestJobStatusRunning(t *testing.T) {
+	runner := tshtest.NewRunner().On(`^tsh ssh`, tshtest.Response{Stdout: "tailing\n", Block: true})
+	sc := newTestContext(t, runner)
+
+	job := startJob(t, sc, "root@node", "tail -f /var/log/syslog")
+
+	// Wait for the output to be written
+	deadline := time.Now().Add(5 * time.Second)
+	for job.Info().OutputBytes == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	result, err := handleJobStatus(context.Background(), createTestRequest(map[string]interface{}{"jobId": job.ID()}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleJobStatus() failed: %v %+v", err, result)
+	}
+	text := resultText(result)
+	for _, expected := range []string{"running for", "tailing", "the job is still running; call again with offset=8"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in result, got: %s", expected, text)
+		}
+	}
+}
+
+func TestJobCancel(t *testing.T) {
+	runner := tshtest.NewRunner().On(`^tsh ssh`, tshtest.Response{Block: true})
+	sc := newTestContext(t, runner)
+
+	job := startJob(t, sc, "root@node", "sleep 600")
+
+	result, err := handleJobCancel(context.Background(), createTestRequest(map[string]interface{}{"jobId": job.ID()}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleJobCancel() failed: %v %+v", err, result)
+	}
+	if text := resultText(result); text != "Cancelled job job-1: cancelled" {
+		t.Errorf("Unexpected result: %s", text)
+	}
+
+	// Cancelling again reports the final state
+	result, err = handleJobCancel(context.Background(), createTestRequest(map[string]interface{}{"jobId": job.ID()}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleJobCancel() failed: %v %+v", err, result)
+	}
+	if text := resultText(result); text != "Job job-1 already finished: cancelled" {
+		t.Errorf("Unexpected result: %s", text)
+	}
+}
+
+func TestJobList(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh ssh root@node uptime$`, tshtest.Response{Stdout: "up\n"}).
+		On(`^tsh ssh`, tshtest.Response{Block: true})
+	sc := newTestContext(t, runner)
+
+	finished := startJob(t, sc, "root@node", "uptime")
+	<-finished.Done()
+	startJob(t, sc, "root@node", "sleep 600")
+
+	result, err := handleJobList(context.Background(), createTestRequest(map[string]interface{}{}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleJobList() failed: %v %+v", err, result)
+	}
+	list := result.StructuredContent.(*JobList)
+	if len(list.Jobs) != 2 || list.Jobs[0].ID != "job-2" || list.Jobs[1].ID != "job-1" {
+		t.Errorf("Expected both jobs, most recent first, got: %+v", list.Jobs)
+	}
+	if text := resultText(result); !strings.Contains(text, "Found 2 background job(s)") {
+		t.Errorf("Unexpected result: %s", text)
+	}
+
+	result, err = handleJobList(context.Background(), createTestRequest(map[string]interface{}{"state": "running"}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleJobList() failed: %v %+v", err, result)
+	}
+	list = result.StructuredContent.(*JobList)
+	if len(list.Jobs) != 1 || list.Jobs[0].ID != "job-2" {
+		t.Errorf("Expected only the running job, got: %+v", list.Jobs)
+	}
+}
+
+func TestJobNotFound(t *testing.T) {
+	sc := newTestContext(t, tshtest.NewRunner())
+
+	for name, handler := range map[string]func(context.Context, mcp.CallToolRequest, *server.ServerContext) (*mcp.CallToolResult, error){
+		"status": handleJobStatus,
+		"cancel": handleJobCancel,
+	} {
+		result, err := handler(context.Background(), createTestRequest(map[string]interface{}{"jobId": "job-42"}), sc)
+		if err != nil {
+			t.Fatalf("%s: expected no error from handler, got: %v", name, err)
+		}
+		if !result.IsError || !strings.Contains(resultText(result), "job not found: job-42") {
+			t.Errorf("%s: expected a not found error, got: %+v", name, result)
+		}
+	}
+}
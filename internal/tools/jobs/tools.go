@@ -0,0 +1,66 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// RegisterJobTools registers the tools that follow background jobs started
+// with background=true on teleport_ssh and teleport_scp
+func RegisterJobTools(s *mcpserver.MCPServer, sc *server.ServerContext) error {
+	// teleport_job_status tool
+	statusTool := mcp.NewTool("teleport_job_status",
+		mcp.WithDescription("Show the state of a background job and its output from an offset. Call again with the returned nextOffset to follow the output incrementally (structured)"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("jobId",
+			mcp.Required(),
+			mcp.Description("ID of the job, as returned when it was started (e.g. job-1)"),
+		),
+		mcp.WithNumber("offset",
+			mcp.Description("Position in the job's output to return output from; defaults to 0. Use nextOffset from the previous call to get only new output"),
+			mcp.Min(0),
+		),
+		mcp.WithNumber("maxBytes",
+			mcp.Description("Maximum number of output bytes to return (default 65536, at most 1048576)"),
+			mcp.Min(1),
+		),
+	)
+
+	s.AddTool(statusTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleJobStatus(ctx, request, sc)
+	})
+
+	// teleport_job_cancel tool
+	cancelTool := mcp.NewTool("teleport_job_cancel",
+		mcp.WithDescription("Cancel a running background job, killing its tsh process (structured)"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("jobId",
+			mcp.Required(),
+			mcp.Description("ID of the job to cancel"),
+		),
+	)
+
+	s.AddTool(cancelTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleJobCancel(ctx, request, sc)
+	})
+
+	// teleport_job_list tool
+	listTool := mcp.NewTool("teleport_job_list",
+		mcp.WithDescription("List running and recently finished background jobs, most recent first (structured)"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("state",
+			mcp.Description("Only list jobs in this state"),
+			mcp.Enum("running", "succeeded", "failed", "cancelled", "timed_out"),
+		),
+	)
+
+	s.AddTool(listTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleJobList(ctx, request, sc)
+	})
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/mcp-teleport/internal/policy"
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// KubeExecResult is the result of teleport_kube_exec
+type KubeExecResult struct {
+	Context   string `json:"context"`
+	Namespace string `json:"namespace,omitempty"`
+	Pod       string `json:"pod"`
+	Container string `json:"container,omitempty"`
+	Command   string `json:"command"`
+	Success   bool   `json:"success"`
+	ExitCode  int    `json:"exitCode"`
+	// Stdout and Stderr are the output cut down to the output limit. They are
+	// only rendered in the text content, so the result carries them once.
+	Stdout string `json:"-"`
+	Stderr string `json:"-"`
+	// StdoutBytes and StderrBytes are the sizes of the whole output
+	StdoutBytes int `json:"stdoutBytes"`
+	StderrBytes int `json:"stderrBytes"`
+	// StdoutHandle and StderrHandle name the full output for
+	// teleport_output_read when it was truncated
+	StdoutHandle string `json:"stdoutHandle,omitempty"`
+	StderrHandle string `json:"stderrHandle,omitempty"`
+	// Error describes why the command failed, e.g. a timeout or a non-zero exit code
+	Error string `json:"error,omitempty"`
+}
+
+// handleKubeExec handles the teleport_kube_exec tool
+func handleKubeExec(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Create teleport client
+	client := sc.TeleportClient()
+
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	pod, _ := params["pod"].(string)
+	if pod == "" {
+		return kubectlError("Error: Pod name is required"), nil
+	}
+	command, _ := params["command"].(string)
+	if strings.TrimSpace(command) == "" {
+		return kubectlError("Error: Command is required. Interactive sessions are not supported via MCP - you must provide a specific command to execute."), nil
+	}
+
+	// In non-destructive mode only commands covered by the read-only policy may run
+	if sc.IsNonDestructiveMode() {
+		if err := policy.CheckReadOnly(command); err != nil {
+			return kubectlError(fmt.Sprintf("Error: Command rejected in non-destructive mode: %v. Only read-only commands are allowed; restart the server with --non-destructive=false to run arbitrary commands.", err)), nil
+		}
+	}
+
+	kubeCtx, statusResult, err := kubeContext(ctx, sc, client, params)
+	if err != nil {
+		return server.WithDiagnostics(kubectlError(fmt.Sprintf("Error: %v", err)), statusResult), nil
+	}
+
+	exec := KubeExecResult{Context: kubeCtx, Pod: pod, Command: command}
+	args := []string{"--context=" + kubeCtx, "exec", pod}
+	if namespace, ok := params["namespace"].(string); ok && namespace != "" {
+		exec.Namespace = namespace
+		args = append(args, "--namespace="+namespace)
+	}
+	if container, ok := params["container"].(string); ok && container != "" {
+		exec.Container = container
+		args = append(args, "--container="+container)
+	}
+	args = append(append(args, "--"), execArgv(command)...)
+
+	// kubectl exec is not a read-only kubectl verb, so unlike runKubectl this
+	// relies on the command check above. Without -i and -t the session is
+	// non-interactive.
+	result := client.ExecuteCommandContext(ctx, "kubectl", args)
+
+	exec.Success = result.Success
+	exec.ExitCode = result.StatusCode
+	if !result.Success {
+		exec.Error = result.ErrorMessage
+	}
+
+	// Large output is truncated, with one limit for both streams; the rest can
+	// be read with teleport_output_read
+	limited := sc.LimitOutputs(ctx, result.Stdout, result.Stderr)
+	stdout, stderr := limited[0], limited[1]
+	exec.Stdout, exec.StdoutHandle, exec.StdoutBytes = stdout.Text, stdout.Handle, stdout.TotalBytes
+	exec.Stderr, exec.StderrHandle, exec.StderrBytes = stderr.Text, stderr.Handle, stderr.TotalBytes
+
+	callResult := server.WithDiagnostics(mcp.NewToolResultStructured(exec, formatKubeExecResult(&exec)), statusResult)
+	callResult.IsError = !exec.Success
+	return callResult, nil
+}
+
+// execArgv returns the argv kubectl exec runs for a command line. Plain
+// commands run directly, so they also work in containers without a shell;
+// anything the shell has to interpret runs with sh -c.
+func execArgv(command string) []string {
+	line, err := policy.ParseCommandLine(command)
+	if err == nil && !line.Substitution && !line.Subshell && len(line.Commands) == 1 {
+		c := line.Commands[0]
+		if len(c.Redirects) == 0 && len(c.Argv) > 0 && !strings.Contains(c.Argv[0], "=") && !strings.ContainsAny(command, "$*?[~") {
+			return c.Argv
+		}
+	}
+	return []string{"sh", "-c", command}
+}
+
+// formatKubeExecResult renders the result of a command in a pod as text
+func formatKubeExecResult(exec *KubeExecResult) string {
+	var sb strings.Builder
+	target := exec.Pod
+	if exec.Namespace != "" {
+		target = exec.Namespace + "/" + target
+	}
+	if exec.Container != "" {
+		target += " (" + exec.Container + ")"
+	}
+
+	if exec.Success {
+		sb.WriteString(fmt.Sprintf("%q in %s exited with code 0\n", exec.Command, target))
+	} else {
+		sb.WriteString(fmt.Sprintf("%q in %s failed with code %d: %s\n", exec.Command, target, exec.ExitCode, exec.Error))
+	}
+	if exec.Stdout != "" {
+		sb.WriteString("\nstdout:\n" + exec.Stdout)
+		if !strings.HasSuffix(exec.Stdout, "\n") {
+			sb.WriteString("\n")
+		}
+	}
+	if exec.Stderr != "" {
+		sb.WriteString("\nstderr:\n" + exec.Stderr)
+		if !strings.HasSuffix(exec.Stderr, "\n") {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,165 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport/tshtest"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleKubeExec(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh kubectl --context=example.com-golem exec coredns-7db6d8ff4d-9xk2p --namespace=kube-system --container=coredns -- cat /etc/resolv.conf$`, tshtest.Response{Stdout: "nameserver 10.96.0.10\nsearch kube-system.svc.cluster.local\n"}).
+		On(`^tsh kubectl --context=example.com-golem exec coredns-7db6d8ff4d-9xk2p -- sh -c ls /missing \| head$`, tshtest.Response{Stderr: "ls: /missing: No such file or directory\ncommand terminated with exit code 1\n", ExitCode: 1})
+	sc := newKubectlServerContext(t, runner)
+
+	result, err := handleKubeExec(context.Background(), createTestRequest(map[string]interface{}{
+		"cluster":     "example.com",
+		"kubeCluster": "golem",
+		"namespace":   "kube-system",
+		"pod":         "coredns-7db6d8ff4d-9xk2p",
+		"container":   "coredns",
+		"command":     "cat /etc/resolv.conf",
+	}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleKubeExec() failed: %v %+v", err, result)
+	}
+	exec := result.StructuredContent.(KubeExecResult)
+	if !exec.Success || exec.ExitCode != 0 || !strings.HasPrefix(exec.Stdout, "nameserver 10.96.0.10") {
+		t.Errorf("Unexpected result: %+v", exec)
+	}
+
+	// Failing commands report their exit code and stderr
+	result, err = handleKubeExec(context.Background(), createTestRequest(map[string]interface{}{
+		"cluster":     "example.com",
+		"kubeCluster": "golem",
+		"pod":         "coredns-7db6d8ff4d-9xk2p",
+		"command":     "ls /missing | head",
+	}), sc)
+	if err != nil || !result.IsError {
+		t.Fatalf("Expected a failed command, got: %v %+v", err, result)
+	}
+	exec = result.StructuredContent.(KubeExecResult)
+	if exec.ExitCode != 1 || !strings.Contains(exec.Stderr, "No such file or directory") {
+		t.Errorf("Unexpected result: %+v", exec)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "failed with code 1") {
+		t.Errorf("Unexpected text: %s", text)
+	}
+}
+
+func TestHandleKubeExecNonDestructive(t *testing.T) {
+	runner := tshtest.NewRunner()
+	sc := newKubectlServerContext(t, runner)
+
+	result, err := handleKubeExec(context.Background(), createTestRequest(map[string]interface{}{
+		"contextName": "golem",
+		"pod":         "coredns-7db6d8ff4d-9xk2p",
+		"command":     "rm -rf /etc/coredns",
+	}), sc)
+	if err != nil || !result.IsError {
+		t.Fatalf("Expected the command to be rejected, got: %v %+v", err, result)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "non-destructive mode") {
+		t.Errorf("Unexpected error: %s", text)
+	}
+	if len(runner.Calls()) != 0 {
+		t.Errorf("Expected no tsh calls, got %v", runner.Calls())
+	}
+}
+
+func TestHandleKubeExecOutputLimit(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh kubectl --context=golem exec `, tshtest.Response{Stdout: strings.Repeat("x", 200)})
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithRunner(runner),
+		server.WithOutputLimit(100),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	result, err := handleKubeExec(context.Background(), createTestRequest(map[string]interface{}{
+		"contextName": "golem",
+		"pod":         "coredns-7db6d8ff4d-9xk2p",
+		"command":     "cat /var/log/big.log",
+	}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleKubeExec() failed: %v %+v", err, result)
+	}
+	if exec := result.StructuredContent.(KubeExecResult); exec.StdoutHandle == "" || exec.StderrHandle != "" {
+		t.Errorf("Expected only stdout to be truncated: %+v", exec)
+	}
+}
+
+func TestHandleKubeExecSharedOutputLimit(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh kubectl --context=golem exec `, tshtest.Response{Stdout: strings.Repeat("o", 1500), Stderr: strings.Repeat("e", 1500)})
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithRunner(runner),
+		server.WithOutputLimit(1000),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	result, err := handleKubeExec(context.Background(), createTestRequest(map[string]interface{}{
+		"contextName": "golem",
+		"pod":         "coredns-7db6d8ff4d-9xk2p",
+		"command":     "cat /var/log/big.log",
+	}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleKubeExec() failed: %v %+v", err, result)
+	}
+
+	// Both streams share one limit
+	exec := result.StructuredContent.(KubeExecResult)
+	if exec.StdoutHandle == "" || exec.StderrHandle == "" || exec.StdoutBytes != 1500 || exec.StderrBytes != 1500 {
+		t.Errorf("Expected both streams to be truncated: %+v", exec)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	kept := 0
+	for _, run := range regexp.MustCompile(`o{10,}|e{10,}`).FindAllString(text, -1) {
+		kept += len(run)
+	}
+	if kept > 1000 {
+		t.Errorf("Kept %d bytes of output, more than the limit of 1000: %s", kept, text)
+	}
+
+	// The output is only in the text content
+	structured, err := json.Marshal(result.StructuredContent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(structured), "ooo") || strings.Contains(string(structured), "eee") {
+		t.Errorf("Expected no output in the structured content: %s", structured)
+	}
+}
+
+func TestExecArgv(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"cat /etc/resolv.conf", []string{"cat", "/etc/resolv.conf"}},
+		{`grep "search domain" /etc/resolv.conf`, []string{"grep", "search domain", "/etc/resolv.conf"}},
+		{"ps aux | grep coredns", []string{"sh", "-c", "ps aux | grep coredns"}},
+		{"cat /proc/1/status 2>/dev/null", []string{"sh", "-c", "cat /proc/1/status 2>/dev/null"}},
+		{"ls $HOME", []string{"sh", "-c", "ls $HOME"}},
+		{"ls /var/log/*.log", []string{"sh", "-c", "ls /var/log/*.log"}},
+		{"LANG=C date", []string{"sh", "-c", "LANG=C date"}},
+	}
+	for _, tt := range tests {
+		if got := execArgv(tt.command); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("execArgv(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
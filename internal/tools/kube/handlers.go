@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"sort"
 	"strings"
 
@@ -17,12 +18,16 @@ type KubeCluster struct {
 	KubeClusterName string                 `json:"kube_cluster_name"`
 	Labels          map[string]interface{} `json:"labels"`
 	Selected        bool                   `json:"selected"`
+	// KubernetesUsers and KubernetesGroups are reported by tsh versions that
+	// resolve the identity's Kubernetes principals per cluster
+	KubernetesUsers  []string `json:"kubernetes_users,omitempty"`
+	KubernetesGroups []string `json:"kubernetes_groups,omitempty"`
 }
 
 // handleKubeListClusters handles the teleport_kube_list_clusters tool
 func handleKubeListClusters(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
 	// Create teleport client
-	client := teleport.NewClient(sc.IsDryRun(), sc.IsDebugMode())
+	client := sc.TeleportClient()
 
 	// Extract parameters
 	params := make(map[string]interface{})
@@ -73,7 +78,7 @@ func handleKubeListClusters(ctx context.Context, request mcp.CallToolRequest, sc
 	}
 
 	// Execute kube ls command
-	result := client.ExecuteCommand("kube ls", args)
+	result := client.ExecuteCommandContext(ctx, "kube ls", args)
 
 	// Build MCP response
 	var content []mcp.Content
@@ -88,30 +93,55 @@ func handleKubeListClusters(ctx context.Context, request mcp.CallToolRequest, sc
 		}, nil
 	}
 
+	// Report what the identity may use in each cluster if asked to
+	if permissions, ok := params["permissions"].(bool); ok && permissions && !sc.IsDryRun() {
+		return listKubeClusterAccess(ctx, sc, client, params, result), nil
+	}
+
 	// Parse JSON output and format for user
-	formattedOutput, err := formatKubeClustersOutput(result.Output, params)
+	formattedOutput, err := formatKubeClustersOutput(result.Stdout, params)
 	if err != nil {
 		// If JSON parsing fails, return raw output
 		content = append(content, mcp.TextContent{
 			Type: "text",
 			Text: result.Output,
 		})
-	} else {
-		content = append(content, mcp.TextContent{
-			Type: "text",
-			Text: formattedOutput,
-		})
+		return &mcp.CallToolResult{
+			Content: content,
+		}, nil
 	}
 
-	return &mcp.CallToolResult{
+	content = append(content, mcp.TextContent{
+		Type: "text",
+		Text: formattedOutput,
+	})
+
+	// Surface tsh warnings that were kept out of the parsed output
+	return server.WithDiagnostics(&mcp.CallToolResult{
 		Content: content,
-	}, nil
+	}, result), nil
 }
 
 // handleKubeLogin handles the teleport_kube_login tool
 func handleKubeLogin(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Logging in rewrites the kubeconfig and current context shared with the
+	// user's kubectl, unless the server keeps its own kubeconfigs
+	if !sc.IsKubeconfigIsolated() {
+		if err := sc.CheckMutation("teleport_kube_login"); err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: "text",
+						Text: fmt.Sprintf("Error: %v", err),
+					},
+				},
+				IsError: true,
+			}, nil
+		}
+	}
+
 	// Create teleport client
-	client := teleport.NewClient(sc.IsDryRun(), sc.IsDebugMode())
+	client := sc.TeleportClient()
 
 	// Extract parameters
 	params := make(map[string]interface{})
@@ -203,8 +233,23 @@ func handleKubeLogin(ctx context.Context, request mcp.CallToolRequest, sc *serve
 		}, nil
 	}
 
-	// Execute kube login command
-	result := client.ExecuteCommand("kube login", args)
+	// Remember the current context before the first login so that
+	// teleport_kube_logout can restore it
+	if !sc.IsDryRun() {
+		if kubeconfig, err := sc.Kubeconfig(ctx); err == nil {
+			if config, err := readKubeconfig(kubeconfig); err == nil {
+				sc.RememberKubeContext(kubeconfig, config.CurrentContext)
+			}
+		}
+	}
+
+	// Execute kube login command. Batch logins report each cluster as progress
+	// if the client asked for it.
+	var progress io.Writer
+	if hasAll && all {
+		progress = server.ProgressWriter(ctx, request)
+	}
+	result := client.StreamCommandContext(ctx, "kube login", args, progress, nil)
 
 	// Build MCP response
 	var content []mcp.Content
@@ -227,7 +272,11 @@ func handleKubeLogin(ctx context.Context, request mcp.CallToolRequest, sc *serve
 		successMessage.WriteString(fmt.Sprintf("Successfully logged in to Kubernetes cluster: %s\n", kubeCluster))
 	}
 
-	successMessage.WriteString("Your kubeconfig has been updated. You can now use kubectl to interact with the cluster(s).\n\n")
+	if kubeconfig, err := sc.Kubeconfig(ctx); err == nil && sc.IsKubeconfigIsolated() {
+		successMessage.WriteString(fmt.Sprintf("The server's private kubeconfig %s has been updated; your own kubeconfig is unchanged. Use teleport_kube_get and the other teleport_kube tools to interact with the cluster(s).\n\n", kubeconfig))
+	} else {
+		successMessage.WriteString("Your kubeconfig has been updated. You can now use kubectl to interact with the cluster(s).\n\n")
+	}
 
 	if result.Output != "" {
 		successMessage.WriteString("Command output:\n")
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport/tshtest"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -103,12 +104,12 @@ func TestHandleKubeListClusters(t *testing.T) {
 }
 
 func TestHandleKubeLogin(t *testing.T) {
-	// Create test server context with dry run mode
+	// Create test server context with dry run mode; login is a mutating tool
 	ctx := context.Background()
 	sc, err := server.NewServerContext(ctx,
 		server.WithDryRun(true),
 		server.WithDebugMode(false),
-		server.WithNonDestructiveMode(true),
+		server.WithNonDestructiveMode(false),
 	)
 	if err != nil {
 		t.Fatalf("Failed to create server context: %v", err)
@@ -212,6 +213,36 @@ func TestHandleKubeLogin(t *testing.T) {
 	}
 }
 
+func TestHandleKubeLoginNonDestructive(t *testing.T) {
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithDryRun(true),
+		server.WithNonDestructiveMode(true),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	request := createTestRequest(map[string]interface{}{
+		"kubeCluster": "test-cluster",
+	})
+
+	result, err := handleKubeLogin(ctx, request, sc)
+	if err != nil {
+		t.Fatalf("handleKubeLogin() error = %v", err)
+	}
+
+	if !result.IsError {
+		t.Error("handleKubeLogin() should be refused in non-destructive mode")
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "non-destructive mode") {
+		t.Errorf("handleKubeLogin() error should mention non-destructive mode, got: %s", text)
+	}
+}
+
 func TestFormatKubeClustersOutput(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -316,6 +347,53 @@ func TestFormatKubeClustersOutput(t *testing.T) {
 	}
 }
 
+func TestKubeHandlersWithFakeTsh(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh kube ls .*--format json`, tshtest.Response{Stdout: tshtest.Fixture(t, "testdata/tsh_kube_ls.json")}).
+		On(`^tsh kube login .*golem$`, tshtest.Response{Stdout: "Logged into Kubernetes cluster \"golem\". Try 'kubectl version' to test the connection.\n"}).
+		On(`^tsh kube login `, tshtest.Response{Stderr: "ERROR: kubernetes cluster \"missing\" not found\n", ExitCode: 1})
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithRunner(runner),
+		server.WithNonDestructiveMode(false),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	result, err := handleKubeListClusters(ctx, createTestRequest(map[string]interface{}{"verbose": true}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleKubeListClusters() failed: %v %+v", err, result)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, expected := range []string{"Found 2 Kubernetes cluster(s)", "• golem\n", "• wallaby (selected)", "customer=giantswarm"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("handleKubeListClusters() result doesn't contain %q. Got: %s", expected, text)
+		}
+	}
+
+	result, err = handleKubeLogin(ctx, createTestRequest(map[string]interface{}{"kubeCluster": "golem"}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleKubeLogin() failed: %v %+v", err, result)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "Successfully logged in to Kubernetes cluster: golem") {
+		t.Errorf("handleKubeLogin() unexpected result: %s", text)
+	}
+
+	result, err = handleKubeLogin(ctx, createTestRequest(map[string]interface{}{"kubeCluster": "missing"}), sc)
+	if err != nil {
+		t.Fatalf("handleKubeLogin() error = %v", err)
+	}
+	if !result.IsError {
+		t.Error("handleKubeLogin() should fail for an unknown cluster")
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "not found") {
+		t.Errorf("handleKubeLogin() error should include tsh output, got: %s", text)
+	}
+}
+
 // Helper function to create test request
 func createTestRequest(params map[string]interface{}) mcp.CallToolRequest {
 	var request mcp.CallToolRequest
@@ -328,3 +406,29 @@ func stringContains(haystack, needle string) bool {
 	return len(haystack) >= len(needle) && (haystack == needle ||
 		strings.Contains(strings.ToLower(haystack), strings.ToLower(needle)))
 }
+
+// TestKubeListClustersWithWarnings checks that tsh warnings on stderr do not
+// break JSON parsing and are returned as diagnostics
+func TestKubeListClustersWithWarnings(t *testing.T) {
+	warning := "WARNING: tsh v16 is deprecated, please upgrade\n"
+	runner := tshtest.NewRunner().
+		On(`^tsh kube ls .*--format json`, tshtest.Response{Stdout: tshtest.Fixture(t, "testdata/tsh_kube_ls.json"), Stderr: warning})
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx, server.WithRunner(runner))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	result, err := handleKubeListClusters(ctx, createTestRequest(map[string]interface{}{}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleKubeListClusters() failed: %v %+v", err, result)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "Found 2 Kubernetes cluster(s)") || strings.Contains(text, "WARNING") {
+		t.Errorf("Expected parsed output without warnings, got: %s", text)
+	}
+	if len(result.Content) != 2 || !strings.Contains(result.Content[1].(mcp.TextContent).Text, "tsh v16 is deprecated") {
+		t.Errorf("Expected the warning as diagnostics, got: %+v", result.Content)
+	}
+}
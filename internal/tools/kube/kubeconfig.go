@@ -0,0 +1,139 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/mark3labs/mcp-go/mcp"
+	"gopkg.in/yaml.v3"
+)
+
+// KubeconfigInfo is the result of teleport_kube_kubeconfig
+type KubeconfigInfo struct {
+	Path string `json:"path"`
+	// Isolated is set when the kubeconfig is private to the server and the
+	// MCP session rather than the user's kubeconfig
+	Isolated bool `json:"isolated"`
+	// Exists is set once tsh has written the file
+	Exists bool `json:"exists"`
+}
+
+// handleKubeKubeconfig handles the teleport_kube_kubeconfig tool
+func handleKubeKubeconfig(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	path, err := sc.Kubeconfig(ctx)
+	if err != nil {
+		return kubectlError(fmt.Sprintf("Error: %v", err)), nil
+	}
+
+	info := KubeconfigInfo{Path: path, Isolated: sc.IsKubeconfigIsolated()}
+	if _, err := os.Stat(path); err == nil {
+		info.Exists = true
+	}
+	return mcp.NewToolResultStructured(info, formatKubeconfigInfo(&info)), nil
+}
+
+// formatKubeconfigInfo renders the kubeconfig of a session as text
+func formatKubeconfigInfo(info *KubeconfigInfo) string {
+	var sb strings.Builder
+	if !info.Isolated {
+		sb.WriteString(fmt.Sprintf("tsh uses your kubeconfig %s; teleport_kube_login changes it and its current context.\n", info.Path))
+		sb.WriteString("Start the server with --kubeconfig-isolation to keep a private kubeconfig per session instead.\n")
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("Kubeconfig of this session: %s\n", info.Path))
+	if !info.Exists {
+		sb.WriteString("It does not exist yet; teleport_kube_login creates it.\n")
+	}
+	sb.WriteString("It is private to the server, leaves your own kubeconfig unchanged and is removed when the server shuts down.\n")
+	sb.WriteString(fmt.Sprintf("To use it from a terminal while the server runs:\n  export KUBECONFIG=%s\n", info.Path))
+	return sb.String()
+}
+
+// kubeconfigFile is the part of a kubeconfig the kube tools read
+type kubeconfigFile struct {
+	CurrentContext string `yaml:"current-context"`
+	Contexts       []struct {
+		Name string `yaml:"name"`
+	} `yaml:"contexts"`
+}
+
+// readKubeconfig reads a kubeconfig; a missing file is an empty kubeconfig
+func readKubeconfig(path string) (*kubeconfigFile, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &kubeconfigFile{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read kubeconfig: %w", err)
+	}
+	var config kubeconfigFile
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("cannot parse kubeconfig %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// contextNames returns the names of the contexts in a kubeconfig
+func (c *kubeconfigFile) contextNames() []string {
+	var names []string
+	for _, entry := range c.Contexts {
+		names = append(names, entry.Name)
+	}
+	return names
+}
+
+// setCurrentContext sets the current context of a kubeconfig, leaving the
+// rest of it as it is; an empty name leaves it without one
+func setCurrentContext(path, name string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("cannot read kubeconfig: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot read kubeconfig: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("cannot parse kubeconfig %s: %w", path, err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) != 1 || doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("cannot parse kubeconfig %s: not a mapping", path)
+	}
+
+	// Mappings hold keys and values as alternating nodes
+	mapping := doc.Content[0]
+	found := false
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == "current-context" {
+			mapping.Content[i+1].SetString(name)
+			found = true
+			break
+		}
+	}
+	if !found {
+		key := &yaml.Node{}
+		key.SetString("current-context")
+		value := &yaml.Node{}
+		value.SetString(name)
+		mapping.Content = append(mapping.Content, key, value)
+	}
+
+	var sb strings.Builder
+	encoder := yaml.NewEncoder(&sb)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("cannot write kubeconfig %s: %w", path, err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("cannot write kubeconfig %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, []byte(sb.String()), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("cannot write kubeconfig %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package kube
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport/tshtest"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleKubeKubeconfig(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh kube login golem$`, tshtest.Response{Stdout: "Logged into Kubernetes cluster \"golem\". Try 'kubectl version' to test the connection.\n"})
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithRunner(runner),
+		server.WithNonDestructiveMode(true),
+		server.WithKubeconfigIsolation(true),
+		server.WithKubeconfigDir(t.TempDir()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	result, err := handleKubeKubeconfig(context.Background(), createTestRequest(nil), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleKubeKubeconfig() failed: %v %+v", err, result)
+	}
+	info := result.StructuredContent.(KubeconfigInfo)
+	if !info.Isolated || info.Exists || filepath.Base(info.Path) != "default.yaml" {
+		t.Errorf("Unexpected kubeconfig: %+v", info)
+	}
+
+	// With a private kubeconfig, logging in is allowed in non-destructive mode
+	result, err = handleKubeLogin(context.Background(), createTestRequest(map[string]interface{}{"kubeCluster": "golem"}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleKubeLogin() failed: %v %+v", err, result)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "private kubeconfig "+info.Path) {
+		t.Errorf("Expected the private kubeconfig in the result, got: %s", text)
+	}
+
+	if err := os.WriteFile(info.Path, []byte("apiVersion: v1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	result, _ = handleKubeKubeconfig(context.Background(), createTestRequest(nil), sc)
+	if info := result.StructuredContent.(KubeconfigInfo); !info.Exists {
+		t.Errorf("Expected the kubeconfig to exist: %+v", info)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "export KUBECONFIG="+info.Path) {
+		t.Errorf("Unexpected text: %s", text)
+	}
+}
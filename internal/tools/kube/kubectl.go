@@ -0,0 +1,494 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/mcp-teleport/internal/policy"
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// defaultLogTail is how many log lines teleport_kube_logs returns by default
+	defaultLogTail = 200
+
+	// defaultEventLimit is how many events teleport_kube_events returns by default
+	defaultEventLimit = 50
+)
+
+// KubeResources is the result of teleport_kube_get
+type KubeResources struct {
+	Context string `json:"context"`
+	Kind    string `json:"kind"`
+	Count   int    `json:"count"`
+	// Items are the resources without managed fields and last-applied
+	// annotations; they are left out if the output was truncated
+	Items []map[string]interface{} `json:"items,omitempty"`
+	// Handle names the full output for teleport_output_read if it was truncated
+	Handle string `json:"handle,omitempty"`
+}
+
+// KubeEvent is a Kubernetes event as returned by teleport_kube_events
+type KubeEvent struct {
+	Namespace string `json:"namespace,omitempty"`
+	Type      string `json:"type"`
+	Reason    string `json:"reason"`
+	// Object is the involved object as kind/name
+	Object   string `json:"object"`
+	Message  string `json:"message"`
+	Count    int    `json:"count"`
+	LastSeen string `json:"lastSeen,omitempty"`
+}
+
+// KubeEvents is the result of teleport_kube_events
+type KubeEvents struct {
+	Context string      `json:"context"`
+	Events  []KubeEvent `json:"events"`
+	// Total is the number of events before the limit was applied
+	Total int `json:"total"`
+}
+
+// kubeContext returns the kubeconfig context to run kubectl against: the
+// contextName parameter, or the <teleport cluster>-<kube cluster> context
+// teleport_kube_login creates. The Teleport cluster defaults to the one of
+// the active tsh profile.
+func kubeContext(ctx context.Context, sc *server.ServerContext, client *teleport.Client, params map[string]interface{}) (string, *teleport.ExecutionResult, error) {
+	if contextName, ok := params["contextName"].(string); ok && contextName != "" {
+		return contextName, nil, nil
+	}
+	kubeCluster, _ := params["kubeCluster"].(string)
+	if kubeCluster == "" {
+		return "", nil, fmt.Errorf("kubeCluster is required; check teleport_kube_list_clusters for available clusters")
+	}
+	if cluster, ok := params["cluster"].(string); ok && cluster != "" {
+		return cluster + "-" + kubeCluster, nil, nil
+	}
+	if sc.IsDryRun() {
+		return "<active cluster>-" + kubeCluster, nil, nil
+	}
+
+	result := client.ExecuteCommandContext(ctx, "status", []string{"--format", "json"})
+	if !result.Success {
+		return "", result, fmt.Errorf("cannot determine the active Teleport cluster, pass cluster: %s", result.ErrorMessage)
+	}
+	status, err := teleport.ParseStatus(result.Stdout)
+	if err != nil || status.Active == nil {
+		return "", result, fmt.Errorf("cannot determine the active Teleport cluster, pass cluster")
+	}
+	return status.Active.Cluster + "-" + kubeCluster, result, nil
+}
+
+// runKubectl runs tsh kubectl against a kubeconfig context. kubectl verbs
+// that write to the cluster are refused in non-destructive mode.
+func runKubectl(ctx context.Context, sc *server.ServerContext, client *teleport.Client, kubeContext string, args []string) (*teleport.ExecutionResult, error) {
+	// tsh hands every argument after kubectl to kubectl, so tsh options
+	// cannot be passed here
+	args = append([]string{"--context=" + kubeContext}, args...)
+	if sc.IsNonDestructiveMode() {
+		if err := policy.CheckReadOnlyKubectl(args); err != nil {
+			return nil, fmt.Errorf("%w; writing to Kubernetes is disabled in non-destructive mode", err)
+		}
+	}
+	return client.ExecuteCommandContext(ctx, "kubectl", args), nil
+}
+
+// namespaceArgs returns the kubectl namespace options for the namespace and
+// allNamespaces parameters
+func namespaceArgs(params map[string]interface{}) []string {
+	if allNamespaces, ok := params["allNamespaces"].(bool); ok && allNamespaces {
+		return []string{"--all-namespaces"}
+	}
+	if namespace, ok := params["namespace"].(string); ok && namespace != "" {
+		return []string{"--namespace=" + namespace}
+	}
+	return nil
+}
+
+// selectorArgs returns the kubectl options for the labelSelector and
+// fieldSelector parameters
+func selectorArgs(params map[string]interface{}) []string {
+	var args []string
+	if labelSelector, ok := params["labelSelector"].(string); ok && labelSelector != "" {
+		args = append(args, "--selector="+labelSelector)
+	}
+	if fieldSelector, ok := params["fieldSelector"].(string); ok && fieldSelector != "" {
+		args = append(args, "--field-selector="+fieldSelector)
+	}
+	return args
+}
+
+// handleKubeGet handles the teleport_kube_get tool
+func handleKubeGet(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Create teleport client
+	client := sc.TeleportClient()
+
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	kind, _ := params["kind"].(string)
+	if kind == "" {
+		return kubectlError("Error: Resource kind is required (e.g. pods, deployments, nodes)"), nil
+	}
+
+	kubeCtx, statusResult, err := kubeContext(ctx, sc, client, params)
+	if err != nil {
+		return server.WithDiagnostics(kubectlError(fmt.Sprintf("Error: %v", err)), statusResult), nil
+	}
+
+	args := []string{"get", kind}
+	if name, ok := params["name"].(string); ok && name != "" {
+		args = append(args, name)
+	}
+	args = append(args, namespaceArgs(params)...)
+	args = append(args, selectorArgs(params)...)
+	args = append(args, "-o", "json")
+
+	result, err := runKubectl(ctx, sc, client, kubeCtx, args)
+	if err != nil {
+		return kubectlError(fmt.Sprintf("Error: %v", err)), nil
+	}
+	if !result.Success {
+		return kubectlError(fmt.Sprintf("Error: %s\n%s", result.ErrorMessage, result.Output)), nil
+	}
+	if sc.IsDryRun() {
+		return mcp.NewToolResultText(result.Output), nil
+	}
+
+	items, err := parseKubeItems(result.Stdout)
+	if err != nil {
+		return server.WithDiagnostics(kubectlError(fmt.Sprintf("Error: %v\n%s", err, result.Output)), result), nil
+	}
+
+	resources := KubeResources{Context: kubeCtx, Kind: kind, Count: len(items), Items: items}
+	text, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return kubectlError(fmt.Sprintf("Error: %v", err)), nil
+	}
+
+	// Large lists are truncated; the rest can be read with teleport_output_read
+	output := sc.LimitOutput(ctx, string(text))
+	if output.Truncated {
+		resources.Items, resources.Handle = nil, output.Handle
+	}
+	summary := fmt.Sprintf("Found %d %s in context %s:\n\n%s", len(items), kind, kubeCtx, output.Text)
+	return server.WithDiagnostics(mcp.NewToolResultStructured(resources, summary), statusResult, result), nil
+}
+
+// parseKubeItems parses kubectl get -o json output, which is a single
+// resource or a list, into resources stripped of server-side bookkeeping
+func parseKubeItems(jsonOutput string) ([]map[string]interface{}, error) {
+	var object map[string]interface{}
+	if err := json.Unmarshal([]byte(jsonOutput), &object); err != nil {
+		return nil, fmt.Errorf("failed to parse kubectl output: %w", err)
+	}
+
+	items := []map[string]interface{}{object}
+	if list, ok := object["items"].([]interface{}); ok {
+		items = make([]map[string]interface{}, 0, len(list))
+		for _, item := range list {
+			if resource, ok := item.(map[string]interface{}); ok {
+				items = append(items, resource)
+			}
+		}
+	}
+	for _, item := range items {
+		stripResource(item)
+	}
+	return items, nil
+}
+
+// stripResource removes managed fields and the last-applied configuration,
+// which repeat the resource and are rarely useful
+func stripResource(resource map[string]interface{}) {
+	metadata, ok := resource["metadata"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	delete(metadata, "managedFields")
+	if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+		delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
+		if len(annotations) == 0 {
+			delete(metadata, "annotations")
+		}
+	}
+}
+
+// handleKubeDescribe handles the teleport_kube_describe tool
+func handleKubeDescribe(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Create teleport client
+	client := sc.TeleportClient()
+
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	kind, _ := params["kind"].(string)
+	if kind == "" {
+		return kubectlError("Error: Resource kind is required (e.g. pod, deployment, node)"), nil
+	}
+
+	kubeCtx, statusResult, err := kubeContext(ctx, sc, client, params)
+	if err != nil {
+		return server.WithDiagnostics(kubectlError(fmt.Sprintf("Error: %v", err)), statusResult), nil
+	}
+
+	args := []string{"describe", kind}
+	if name, ok := params["name"].(string); ok && name != "" {
+		args = append(args, name)
+	}
+	args = append(args, namespaceArgs(params)...)
+	args = append(args, selectorArgs(params)...)
+
+	result, err := runKubectl(ctx, sc, client, kubeCtx, args)
+	if err != nil {
+		return kubectlError(fmt.Sprintf("Error: %v", err)), nil
+	}
+	if !result.Success {
+		return kubectlError(fmt.Sprintf("Error: %s\n%s", result.ErrorMessage, result.Output)), nil
+	}
+
+	// Describing many resources is truncated; the rest can be read with teleport_output_read
+	output := sc.LimitOutput(ctx, result.Stdout)
+	return server.WithDiagnostics(mcp.NewToolResultText(output.Text), statusResult, result), nil
+}
+
+// handleKubeLogs handles the teleport_kube_logs tool
+func handleKubeLogs(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Create teleport client
+	client := sc.TeleportClient()
+
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	pod, _ := params["pod"].(string)
+	if pod == "" {
+		return kubectlError("Error: Pod name is required"), nil
+	}
+
+	kubeCtx, statusResult, err := kubeContext(ctx, sc, client, params)
+	if err != nil {
+		return server.WithDiagnostics(kubectlError(fmt.Sprintf("Error: %v", err)), statusResult), nil
+	}
+
+	args := []string{"logs", pod}
+	args = append(args, namespaceArgs(params)...)
+	if container, ok := params["container"].(string); ok && container != "" {
+		args = append(args, "--container="+container)
+	}
+	tail := defaultLogTail
+	if value, ok := params["tail"].(float64); ok && value > 0 {
+		tail = int(value)
+	}
+	args = append(args, fmt.Sprintf("--tail=%d", tail))
+	if since, ok := params["since"].(string); ok && since != "" {
+		args = append(args, "--since="+since)
+	}
+	if previous, ok := params["previous"].(bool); ok && previous {
+		args = append(args, "--previous")
+	}
+	if timestamps, ok := params["timestamps"].(bool); ok && timestamps {
+		args = append(args, "--timestamps")
+	}
+
+	result, err := runKubectl(ctx, sc, client, kubeCtx, args)
+	if err != nil {
+		return kubectlError(fmt.Sprintf("Error: %v", err)), nil
+	}
+	if !result.Success {
+		return kubectlError(fmt.Sprintf("Error: %s\n%s", result.ErrorMessage, result.Output)), nil
+	}
+	if result.Stdout == "" {
+		return server.WithDiagnostics(mcp.NewToolResultText(fmt.Sprintf("No log output from pod %s", pod)), statusResult, result), nil
+	}
+
+	// Long logs are truncated; the rest can be read with teleport_output_read
+	output := sc.LimitOutput(ctx, result.Stdout)
+	return server.WithDiagnostics(mcp.NewToolResultText(output.Text), statusResult, result), nil
+}
+
+// handleKubeEvents handles the teleport_kube_events tool
+func handleKubeEvents(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Create teleport client
+	client := sc.TeleportClient()
+
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	kubeCtx, statusResult, err := kubeContext(ctx, sc, client, params)
+	if err != nil {
+		return server.WithDiagnostics(kubectlError(fmt.Sprintf("Error: %v", err)), statusResult), nil
+	}
+
+	// Filter by the involved object and event type on the API server
+	var selectors []string
+	if kind, ok := params["kind"].(string); ok && kind != "" {
+		selectors = append(selectors, "involvedObject.kind="+kind)
+	}
+	if name, ok := params["name"].(string); ok && name != "" {
+		selectors = append(selectors, "involvedObject.name="+name)
+	}
+	if eventType, ok := params["type"].(string); ok && eventType != "" {
+		selectors = append(selectors, "type="+eventType)
+	}
+
+	args := append([]string{"get", "events"}, namespaceArgs(params)...)
+	if len(selectors) > 0 {
+		args = append(args, "--field-selector="+strings.Join(selectors, ","))
+	}
+	args = append(args, "-o", "json")
+
+	result, err := runKubectl(ctx, sc, client, kubeCtx, args)
+	if err != nil {
+		return kubectlError(fmt.Sprintf("Error: %v", err)), nil
+	}
+	if !result.Success {
+		return kubectlError(fmt.Sprintf("Error: %s\n%s", result.ErrorMessage, result.Output)), nil
+	}
+	if sc.IsDryRun() {
+		return mcp.NewToolResultText(result.Output), nil
+	}
+
+	events, err := parseKubeEvents(result.Stdout)
+	if err != nil {
+		return server.WithDiagnostics(kubectlError(fmt.Sprintf("Error: %v\n%s", err, result.Output)), result), nil
+	}
+
+	limit := defaultEventLimit
+	if value, ok := params["limit"].(float64); ok && value > 0 {
+		limit = int(value)
+	}
+	list := KubeEvents{Context: kubeCtx, Events: events, Total: len(events)}
+	if len(list.Events) > limit {
+		list.Events = list.Events[:limit]
+	}
+
+	return server.WithDiagnostics(mcp.NewToolResultStructured(list, formatKubeEvents(&list)), statusResult, result), nil
+}
+
+// kubeEventJSON is the part of a Kubernetes event teleport_kube_events uses
+type kubeEventJSON struct {
+	Metadata struct {
+		Namespace         string `json:"namespace"`
+		CreationTimestamp string `json:"creationTimestamp"`
+	} `json:"metadata"`
+	InvolvedObject struct {
+		Kind string `json:"kind"`
+		Name string `json:"name"`
+	} `json:"involvedObject"`
+	Type           string `json:"type"`
+	Reason         string `json:"reason"`
+	Message        string `json:"message"`
+	Count          int    `json:"count"`
+	LastTimestamp  string `json:"lastTimestamp"`
+	EventTime      string `json:"eventTime"`
+	FirstTimestamp string `json:"firstTimestamp"`
+}
+
+// parseKubeEvents parses kubectl get events -o json output, newest first
+func parseKubeEvents(jsonOutput string) ([]KubeEvent, error) {
+	var list struct {
+		Items []kubeEventJSON `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(jsonOutput), &list); err != nil {
+		return nil, fmt.Errorf("failed to parse kubectl output: %w", err)
+	}
+
+	events := make([]KubeEvent, 0, len(list.Items))
+	for _, item := range list.Items {
+		// Events from newer reporters only set eventTime
+		lastSeen := item.LastTimestamp
+		for _, timestamp := range []string{item.EventTime, item.FirstTimestamp, item.Metadata.CreationTimestamp} {
+			if lastSeen == "" {
+				lastSeen = timestamp
+			}
+		}
+		count := item.Count
+		if count == 0 {
+			count = 1
+		}
+		events = append(events, KubeEvent{
+			Namespace: item.Metadata.Namespace,
+			Type:      item.Type,
+			Reason:    item.Reason,
+			Object:    strings.ToLower(item.InvolvedObject.Kind) + "/" + item.InvolvedObject.Name,
+			Message:   strings.TrimSpace(item.Message),
+			Count:     count,
+			LastSeen:  lastSeen,
+		})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTime(events[i]).After(eventTime(events[j]))
+	})
+	return events, nil
+}
+
+// eventTime parses when an event was last seen; unknown times sort last
+func eventTime(event KubeEvent) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, event.LastSeen)
+	return t
+}
+
+// formatKubeEvents renders events as text
+func formatKubeEvents(list *KubeEvents) string {
+	if len(list.Events) == 0 {
+		return fmt.Sprintf("No events found in context %s", list.Context)
+	}
+
+	var sb strings.Builder
+	if len(list.Events) < list.Total {
+		sb.WriteString(fmt.Sprintf("Showing the %d most recent of %d events in context %s:\n\n", len(list.Events), list.Total, list.Context))
+	} else {
+		sb.WriteString(fmt.Sprintf("Found %d event(s) in context %s:\n\n", list.Total, list.Context))
+	}
+	for _, event := range list.Events {
+		object := event.Object
+		if event.Namespace != "" {
+			object = event.Namespace + "/" + object
+		}
+		sb.WriteString(fmt.Sprintf("%s %s %s %s", event.LastSeen, event.Type, event.Reason, object))
+		if event.Count > 1 {
+			sb.WriteString(fmt.Sprintf(" (x%d)", event.Count))
+		}
+		sb.WriteString(fmt.Sprintf(": %s\n", event.Message))
+	}
+	return sb.String()
+}
+
+// kubectlError returns a kubectl error result
+func kubectlError(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+		IsError: true,
+	}
+}
@@ -0,0 +1,135 @@
+package kube
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport/tshtest"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// newKubectlServerContext returns a non-destructive server context running tsh with runner
+func newKubectlServerContext(t *testing.T, runner *tshtest.Runner) *server.ServerContext {
+	t.Helper()
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithRunner(runner),
+		server.WithNonDestructiveMode(true),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	t.Cleanup(func() { sc.Shutdown() })
+	return sc
+}
+
+func TestHandleKubeGet(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh status --format json$`, tshtest.Response{Stdout: tshtest.Fixture(t, "testdata/tsh_status.json")}).
+		On(`^tsh kubectl --context=example.com-golem get pods --namespace=kube-system --selector=k8s-app=kube-dns -o json$`, tshtest.Response{Stdout: tshtest.Fixture(t, "testdata/kubectl_get_pods.json")})
+	sc := newKubectlServerContext(t, runner)
+
+	result, err := handleKubeGet(context.Background(), createTestRequest(map[string]interface{}{
+		"kubeCluster":   "golem",
+		"kind":          "pods",
+		"namespace":     "kube-system",
+		"labelSelector": "k8s-app=kube-dns",
+	}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleKubeGet() failed: %v %+v", err, result)
+	}
+
+	resources := result.StructuredContent.(KubeResources)
+	if resources.Context != "example.com-golem" || resources.Count != 2 || len(resources.Items) != 2 {
+		t.Fatalf("Unexpected resources: %+v", resources)
+	}
+	metadata := resources.Items[0]["metadata"].(map[string]interface{})
+	if _, ok := metadata["managedFields"]; ok {
+		t.Error("Expected managed fields to be removed")
+	}
+	if _, ok := metadata["annotations"]; ok {
+		t.Error("Expected the last-applied configuration to be removed")
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, expected := range []string{"Found 2 pods in context example.com-golem", `"name": "coredns-7db6d8ff4d-t5v8m"`} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in result, got: %s", expected, text)
+		}
+	}
+}
+
+func TestHandleKubeLogs(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh kubectl --context=example.com-golem logs coredns-7db6d8ff4d-9xk2p --namespace=kube-system --container=coredns --tail=50 --since=1h --previous$`, tshtest.Response{Stdout: "[INFO] plugin/reload: Running configuration SHA512 = 591cf328\n"})
+	sc := newKubectlServerContext(t, runner)
+
+	result, err := handleKubeLogs(context.Background(), createTestRequest(map[string]interface{}{
+		"cluster":     "example.com",
+		"kubeCluster": "golem",
+		"pod":         "coredns-7db6d8ff4d-9xk2p",
+		"namespace":   "kube-system",
+		"container":   "coredns",
+		"tail":        float64(50),
+		"since":       "1h",
+		"previous":    true,
+	}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleKubeLogs() failed: %v %+v", err, result)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "Running configuration") {
+		t.Errorf("Unexpected logs: %s", text)
+	}
+
+	// Without a kube cluster there is nothing to run against
+	result, err = handleKubeLogs(context.Background(), createTestRequest(map[string]interface{}{"pod": "coredns-7db6d8ff4d-9xk2p"}), sc)
+	if err != nil || !result.IsError {
+		t.Errorf("Expected an error without kubeCluster, got: %v %+v", err, result)
+	}
+}
+
+func TestHandleKubeEvents(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh kubectl --context=golem get events --all-namespaces --field-selector=type=Warning -o json$`, tshtest.Response{Stdout: tshtest.Fixture(t, "testdata/kubectl_get_events.json")})
+	sc := newKubectlServerContext(t, runner)
+
+	result, err := handleKubeEvents(context.Background(), createTestRequest(map[string]interface{}{
+		"contextName":   "golem",
+		"allNamespaces": true,
+		"type":          "Warning",
+		"limit":         float64(2),
+	}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleKubeEvents() failed: %v %+v", err, result)
+	}
+
+	list := result.StructuredContent.(KubeEvents)
+	if list.Total != 3 || len(list.Events) != 2 {
+		t.Fatalf("Unexpected events: %+v", list)
+	}
+	if event := list.Events[0]; event.Reason != "BackOff" || event.Object != "pod/coredns-7db6d8ff4d-t5v8m" || event.Count != 14 {
+		t.Errorf("Expected the newest event first, got %+v", event)
+	}
+	if event := list.Events[1]; event.Reason != "NodeHasDiskPressure" || event.LastSeen != "2026-10-16T07:15:42.318204Z" {
+		t.Errorf("Expected the event time of new-style events, got %+v", event)
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "Showing the 2 most recent of 3 events") || !strings.Contains(text, "kube-system/pod/coredns-7db6d8ff4d-t5v8m (x14): Back-off pulling image") {
+		t.Errorf("Unexpected events text: %s", text)
+	}
+}
+
+func TestRunKubectlNonDestructive(t *testing.T) {
+	runner := tshtest.NewRunner()
+	sc := newKubectlServerContext(t, runner)
+
+	_, err := runKubectl(context.Background(), sc, sc.TeleportClient(), "example.com-golem", []string{"delete", "pod", "coredns-7db6d8ff4d-9xk2p"})
+	if err == nil || !strings.Contains(err.Error(), "non-destructive mode") {
+		t.Errorf("Expected write verbs to be refused, got: %v", err)
+	}
+	if len(runner.Calls()) != 0 {
+		t.Errorf("Expected no tsh calls, got %v", runner.Calls())
+	}
+}
@@ -0,0 +1,146 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// KubeLogoutResult is the result of teleport_kube_logout
+type KubeLogoutResult struct {
+	Kubeconfig string `json:"kubeconfig"`
+	// RemovedContexts are the kubeconfig contexts tsh kube logout removed
+	RemovedContexts []string `json:"removedContexts"`
+	// CurrentContext is the current context of the kubeconfig after logout
+	CurrentContext string `json:"currentContext"`
+	// Restored is set when the current context was reset to the one before
+	// the server first logged in; RestoreError explains why it was not
+	Restored     bool   `json:"restored"`
+	RestoreError string `json:"restoreError,omitempty"`
+}
+
+// handleKubeLogout handles the teleport_kube_logout tool
+func handleKubeLogout(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Like logging in, logging out rewrites the kubeconfig shared with the
+	// user's kubectl, unless the server keeps its own kubeconfigs
+	if !sc.IsKubeconfigIsolated() {
+		if err := sc.CheckMutation("teleport_kube_logout"); err != nil {
+			return kubectlError(fmt.Sprintf("Error: %v", err)), nil
+		}
+	}
+
+	// Create teleport client
+	client := sc.TeleportClient()
+
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	kubeCluster, _ := params["kubeCluster"].(string)
+	all, _ := params["all"].(bool)
+	if kubeCluster == "" && !all {
+		return kubectlError("Error: Either 'kubeCluster' must be specified to log out of a single cluster, or 'all' must be true to log out of all Kubernetes clusters."), nil
+	}
+	if kubeCluster != "" && all {
+		return kubectlError("Error: 'kubeCluster' and 'all' are mutually exclusive."), nil
+	}
+
+	args := teleport.FormatArgs(params)
+	if cluster, ok := params["cluster"].(string); ok && cluster != "" {
+		args = append(args, "--cluster", cluster)
+	}
+	if all {
+		args = append(args, "--all")
+	} else {
+		args = append(args, kubeCluster)
+	}
+
+	if sc.IsDryRun() {
+		result := client.ExecuteCommandContext(ctx, "kube logout", args)
+		return server.WithDiagnostics(mcp.NewToolResultText(result.Output), result), nil
+	}
+
+	kubeconfig, err := sc.Kubeconfig(ctx)
+	if err != nil {
+		return kubectlError(fmt.Sprintf("Error: %v", err)), nil
+	}
+	before, err := readKubeconfig(kubeconfig)
+	if err != nil {
+		return kubectlError(fmt.Sprintf("Error: %v", err)), nil
+	}
+
+	result := client.ExecuteCommandContext(ctx, "kube logout", args)
+	if !result.Success {
+		return server.WithDiagnostics(kubectlError(fmt.Sprintf("Error: %s\n%s", result.ErrorMessage, result.Output)), result), nil
+	}
+
+	after, err := readKubeconfig(kubeconfig)
+	if err != nil {
+		return server.WithDiagnostics(kubectlError(fmt.Sprintf("Error: %v", err)), result), nil
+	}
+
+	logout := KubeLogoutResult{Kubeconfig: kubeconfig, CurrentContext: after.CurrentContext}
+	remaining := make(map[string]bool)
+	for _, name := range after.contextNames() {
+		remaining[name] = true
+	}
+	for _, name := range before.contextNames() {
+		if !remaining[name] {
+			logout.RemovedContexts = append(logout.RemovedContexts, name)
+		}
+	}
+
+	if restore, ok := params["restoreContext"].(bool); ok && restore {
+		original, ok := sc.OriginalKubeContext(kubeconfig)
+		switch {
+		case !ok:
+			logout.RestoreError = "the server has not logged in to Kubernetes with this kubeconfig"
+		case original != "" && !remaining[original]:
+			logout.RestoreError = fmt.Sprintf("context %s no longer exists", original)
+		default:
+			if err := setCurrentContext(kubeconfig, original); err != nil {
+				logout.RestoreError = err.Error()
+				break
+			}
+			logout.Restored = true
+			logout.CurrentContext = original
+		}
+	}
+
+	return server.WithDiagnostics(mcp.NewToolResultStructured(logout, formatKubeLogoutResult(&logout)), result), nil
+}
+
+// formatKubeLogoutResult renders the result of a Kubernetes logout as text
+func formatKubeLogoutResult(logout *KubeLogoutResult) string {
+	var sb strings.Builder
+	if len(logout.RemovedContexts) == 0 {
+		sb.WriteString(fmt.Sprintf("Logged out; no contexts were removed from %s.\n", logout.Kubeconfig))
+	} else {
+		sb.WriteString(fmt.Sprintf("Logged out; removed %d context(s) from %s:\n", len(logout.RemovedContexts), logout.Kubeconfig))
+		for _, name := range logout.RemovedContexts {
+			sb.WriteString(fmt.Sprintf("  • %s\n", name))
+		}
+	}
+
+	current := logout.CurrentContext
+	if current == "" {
+		current = "(none)"
+	}
+	switch {
+	case logout.Restored:
+		sb.WriteString(fmt.Sprintf("Current context restored to the one before the first login: %s\n", current))
+	case logout.RestoreError != "":
+		sb.WriteString(fmt.Sprintf("Current context not restored: %s. Current context: %s\n", logout.RestoreError, current))
+	default:
+		sb.WriteString(fmt.Sprintf("Current context: %s\n", current))
+	}
+	return sb.String()
+}
@@ -0,0 +1,184 @@
+package kube
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport"
+	"github.com/giantswarm/mcp-teleport/internal/teleport/tshtest"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	kubeconfigBefore = `apiVersion: v1
+kind: Config
+contexts:
+- name: dev
+  context:
+    cluster: dev
+current-context: dev
+`
+	kubeconfigLoggedIn = `apiVersion: v1
+kind: Config
+contexts:
+- name: dev
+  context:
+    cluster: dev
+- name: example.com-golem
+  context:
+    cluster: example.com
+- name: example.com-thor
+  context:
+    cluster: example.com
+current-context: example.com-golem
+`
+	kubeconfigLoggedOut = `apiVersion: v1
+kind: Config
+contexts:
+- name: dev
+  context:
+    cluster: dev
+- name: example.com-thor
+  context:
+    cluster: example.com
+current-context: ""
+`
+)
+
+// kubeconfigRunner writes a kubeconfig whenever tsh runs a matching
+// command, as tsh kube login and logout do
+type kubeconfigRunner struct {
+	*tshtest.Runner
+	path    string
+	configs map[string]string
+}
+
+func (r *kubeconfigRunner) Run(ctx context.Context, cmd teleport.Command) (int, error) {
+	line := strings.Join(append([]string{cmd.Name}, cmd.Args...), " ")
+	for prefix, config := range r.configs {
+		if strings.HasPrefix(line, prefix) {
+			if err := os.WriteFile(r.path, []byte(config), 0o600); err != nil {
+				return 1, err
+			}
+		}
+	}
+	return r.Runner.Run(ctx, cmd)
+}
+
+func TestHandleKubeLogout(t *testing.T) {
+	runner := &kubeconfigRunner{
+		Runner: tshtest.NewRunner().
+			On(`^tsh kube login golem$`, tshtest.Response{Stdout: "Logged into Kubernetes cluster \"golem\".\n"}).
+			On(`^tsh kube logout golem$`, tshtest.Response{Stdout: "Logged out of Kubernetes cluster \"golem\".\n"}),
+		configs: map[string]string{
+			"tsh kube login":  kubeconfigLoggedIn,
+			"tsh kube logout": kubeconfigLoggedOut,
+		},
+	}
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithRunner(runner),
+		server.WithNonDestructiveMode(true),
+		server.WithKubeconfigIsolation(true),
+		server.WithKubeconfigDir(t.TempDir()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	runner.path, err = sc.Kubeconfig(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(runner.path, []byte(kubeconfigBefore), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := handleKubeLogin(context.Background(), createTestRequest(map[string]interface{}{"kubeCluster": "golem"}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleKubeLogin() failed: %v %+v", err, result)
+	}
+
+	result, err = handleKubeLogout(context.Background(), createTestRequest(map[string]interface{}{
+		"kubeCluster":    "golem",
+		"restoreContext": true,
+	}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleKubeLogout() failed: %v %+v", err, result)
+	}
+
+	logout := result.StructuredContent.(KubeLogoutResult)
+	if len(logout.RemovedContexts) != 1 || logout.RemovedContexts[0] != "example.com-golem" {
+		t.Errorf("Expected example.com-golem to be removed, got %v", logout.RemovedContexts)
+	}
+	if !logout.Restored || logout.CurrentContext != "dev" {
+		t.Errorf("Expected the current context to be restored to dev: %+v", logout)
+	}
+	config, err := readKubeconfig(runner.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.CurrentContext != "dev" || len(config.Contexts) != 2 {
+		t.Errorf("Unexpected kubeconfig after logout: %+v", config)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "• example.com-golem") || !strings.Contains(text, "restored") {
+		t.Errorf("Unexpected text: %s", text)
+	}
+}
+
+func TestHandleKubeLogoutValidation(t *testing.T) {
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithRunner(tshtest.NewRunner()),
+		server.WithKubeconfigIsolation(true),
+		server.WithKubeconfigDir(t.TempDir()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		want   string
+	}{
+		{"neither", nil, "Either 'kubeCluster'"},
+		{"both", map[string]interface{}{"kubeCluster": "golem", "all": true}, "mutually exclusive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handleKubeLogout(context.Background(), createTestRequest(tt.params), sc)
+			if err != nil || !result.IsError {
+				t.Fatalf("Expected an error result, got %v %+v", err, result)
+			}
+			if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, tt.want) {
+				t.Errorf("Expected %q in %s", tt.want, text)
+			}
+		})
+	}
+}
+
+func TestHandleKubeLogoutWithoutIsolation(t *testing.T) {
+	t.Setenv("KUBECONFIG", t.TempDir()+"/config")
+	runner := tshtest.NewRunner()
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithRunner(runner),
+		server.WithNonDestructiveMode(true),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	// Logging out would change the user's kubeconfig
+	result, err := handleKubeLogout(context.Background(), createTestRequest(map[string]interface{}{"all": true}), sc)
+	if err != nil || !result.IsError {
+		t.Fatalf("Expected logout to be refused, got %v %+v", err, result)
+	}
+	if calls := runner.Calls(); len(calls) != 0 {
+		t.Errorf("Expected no tsh calls, got %v", calls)
+	}
+}
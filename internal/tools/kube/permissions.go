@@ -0,0 +1,155 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// KubeClusterAccess is a Kubernetes cluster together with what the current
+// identity may use in it
+type KubeClusterAccess struct {
+	Name     string                 `json:"name"`
+	Labels   map[string]interface{} `json:"labels,omitempty"`
+	Selected bool                   `json:"selected"`
+	// Users and Groups are valid values for asUser and asGroups of
+	// teleport_kube_login
+	Users  []string `json:"users"`
+	Groups []string `json:"groups"`
+	// Namespaces are the namespaces the identity can list, valid values for
+	// kubeNamespace; NamespacesError explains why they are unknown
+	Namespaces      []string `json:"namespaces,omitempty"`
+	NamespacesError string   `json:"namespacesError,omitempty"`
+}
+
+// KubeClusterAccessList is the result of teleport_kube_list_clusters with permissions
+type KubeClusterAccessList struct {
+	// Cluster and Username describe the active tsh profile
+	Cluster  string              `json:"cluster"`
+	Username string              `json:"username"`
+	Clusters []KubeClusterAccess `json:"clusters"`
+}
+
+// listKubeClusterAccess adds the Kubernetes users and groups of the current
+// identity and the namespaces it can list to the clusters of a tsh kube ls
+// result. Users and groups reported per cluster by tsh kube ls take
+// precedence over those of the active profile in tsh status.
+func listKubeClusterAccess(ctx context.Context, sc *server.ServerContext, client *teleport.Client, params map[string]interface{}, lsResult *teleport.ExecutionResult) *mcp.CallToolResult {
+	var clusters []KubeCluster
+	if strings.TrimSpace(lsResult.Stdout) != "" {
+		if err := json.Unmarshal([]byte(lsResult.Stdout), &clusters); err != nil {
+			return server.WithDiagnostics(kubectlError(fmt.Sprintf("Error: failed to parse tsh kube ls output: %v", err)), lsResult)
+		}
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		return clusters[i].KubeClusterName < clusters[j].KubeClusterName
+	})
+
+	statusResult := client.ExecuteCommandContext(ctx, "status", []string{"--format", "json"})
+	if !statusResult.Success {
+		return kubectlError(fmt.Sprintf("Error: %s\n%s", statusResult.ErrorMessage, statusResult.Output))
+	}
+	status, err := teleport.ParseStatus(statusResult.Stdout)
+	if err != nil || status.Active == nil {
+		return server.WithDiagnostics(kubectlError("Error: Not logged in to Teleport; use teleport_login first"), statusResult)
+	}
+
+	list := KubeClusterAccessList{Cluster: status.Active.Cluster, Username: status.Active.Username}
+	teleportCluster := status.Active.Cluster
+	if cluster, ok := params["cluster"].(string); ok && cluster != "" {
+		teleportCluster = cluster
+	}
+
+	executions := []*teleport.ExecutionResult{lsResult, statusResult}
+	for _, cluster := range clusters {
+		access := KubeClusterAccess{
+			Name:     cluster.KubeClusterName,
+			Labels:   cluster.Labels,
+			Selected: cluster.Selected,
+			Users:    cluster.KubernetesUsers,
+			Groups:   cluster.KubernetesGroups,
+		}
+		if access.Users == nil {
+			access.Users = status.Active.KubernetesUsers
+		}
+		if access.Groups == nil {
+			access.Groups = status.Active.KubernetesGroups
+		}
+
+		// Listing namespaces needs a login to the cluster; without one the
+		// error says so
+		result, err := runKubectl(ctx, sc, client, teleportCluster+"-"+cluster.KubeClusterName, []string{"get", "namespaces", "-o", "json"})
+		switch {
+		case err != nil:
+			access.NamespacesError = err.Error()
+		case !result.Success:
+			access.NamespacesError = strings.TrimSpace(firstNonEmpty(result.Stderr, result.ErrorMessage))
+		default:
+			items, err := parseKubeItems(result.Stdout)
+			if err != nil {
+				access.NamespacesError = err.Error()
+				break
+			}
+			for _, item := range items {
+				if metadata, ok := item["metadata"].(map[string]interface{}); ok {
+					if name, ok := metadata["name"].(string); ok {
+						access.Namespaces = append(access.Namespaces, name)
+					}
+				}
+			}
+		}
+		list.Clusters = append(list.Clusters, access)
+	}
+
+	return server.WithDiagnostics(mcp.NewToolResultStructured(list, formatKubeClusterAccess(&list)), executions...)
+}
+
+// firstNonEmpty returns the first value that is not empty
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// formatKubeClusterAccess renders Kubernetes clusters with permissions as text
+func formatKubeClusterAccess(list *KubeClusterAccessList) string {
+	if len(list.Clusters) == 0 {
+		return "No Kubernetes clusters found"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Found %d Kubernetes cluster(s) for %s in %s:\n", len(list.Clusters), list.Username, list.Cluster))
+	for _, cluster := range list.Clusters {
+		sb.WriteString(fmt.Sprintf("\n• %s", cluster.Name))
+		if cluster.Selected {
+			sb.WriteString(" (selected)")
+		}
+		sb.WriteString("\n")
+		sb.WriteString(fmt.Sprintf("  Users: %s\n", joinOrNone(cluster.Users)))
+		sb.WriteString(fmt.Sprintf("  Groups: %s\n", joinOrNone(cluster.Groups)))
+		if cluster.NamespacesError != "" {
+			sb.WriteString(fmt.Sprintf("  Namespaces: unknown (%s)\n", cluster.NamespacesError))
+		} else {
+			sb.WriteString(fmt.Sprintf("  Namespaces: %s\n", joinOrNone(cluster.Namespaces)))
+		}
+	}
+	sb.WriteString("\nPass a user as asUser, groups as asGroups and a namespace as kubeNamespace to teleport_kube_login.\n")
+	return sb.String()
+}
+
+// joinOrNone joins values with commas, or returns a marker if there are none
+func joinOrNone(values []string) string {
+	if len(values) == 0 {
+		return "(none)"
+	}
+	return strings.Join(values, ", ")
+}
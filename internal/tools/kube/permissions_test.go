@@ -0,0 +1,50 @@
+package kube
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/mcp-teleport/internal/teleport/tshtest"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleKubeListClustersPermissions(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh kube ls --format json$`, tshtest.Response{Stdout: tshtest.Fixture(t, "testdata/tsh_kube_ls_permissions.json")}).
+		On(`^tsh status --format json$`, tshtest.Response{Stdout: tshtest.Fixture(t, "testdata/tsh_status.json")}).
+		On(`^tsh kubectl --context=example.com-golem get namespaces -o json$`, tshtest.Response{Stdout: tshtest.Fixture(t, "testdata/kubectl_get_namespaces.json")}).
+		On(`^tsh kubectl --context=example.com-wallaby `, tshtest.Response{Stderr: "error: context \"example.com-wallaby\" does not exist\n", ExitCode: 1})
+	sc := newKubectlServerContext(t, runner)
+
+	result, err := handleKubeListClusters(context.Background(), createTestRequest(map[string]interface{}{"permissions": true}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleKubeListClusters() failed: %v %+v", err, result)
+	}
+
+	list := result.StructuredContent.(KubeClusterAccessList)
+	if list.Cluster != "example.com" || list.Username != "alice@example.com" || len(list.Clusters) != 2 {
+		t.Fatalf("Unexpected list: %+v", list)
+	}
+
+	// Principals reported per cluster take precedence over the profile's
+	golem := list.Clusters[0]
+	if golem.Name != "golem" || !reflect.DeepEqual(golem.Groups, []string{"golem-admins"}) || !reflect.DeepEqual(golem.Namespaces, []string{"default", "kube-system"}) {
+		t.Errorf("Unexpected golem access: %+v", golem)
+	}
+	wallaby := list.Clusters[1]
+	if !reflect.DeepEqual(wallaby.Users, []string{"alice@example.com"}) || !reflect.DeepEqual(wallaby.Groups, []string{"developers", "system:authenticated"}) {
+		t.Errorf("Expected the profile's principals for wallaby, got %+v", wallaby)
+	}
+	if wallaby.Namespaces != nil || !strings.Contains(wallaby.NamespacesError, "does not exist") {
+		t.Errorf("Expected unknown namespaces for wallaby, got %+v", wallaby)
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, expected := range []string{"• golem\n  Users: alice@example.com\n  Groups: golem-admins\n  Namespaces: default, kube-system", "• wallaby (selected)"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in result, got: %s", expected, text)
+		}
+	}
+}
@@ -13,6 +13,7 @@ func RegisterKubeTools(s *mcpserver.MCPServer, sc *server.ServerContext) error {
 	// teleport_kube_list_clusters tool
 	listClustersTool := mcp.NewTool("teleport_kube_list_clusters",
 		mcp.WithDescription("Get a list of Kubernetes clusters available through Teleport"),
+		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithString("loginParam",
 			mcp.Description("Remote host login"),
 		),
@@ -56,6 +57,10 @@ func RegisterKubeTools(s *mcpserver.MCPServer, sc *server.ServerContext) error {
 		mcp.WithBoolean("quiet",
 			mcp.Description("Quiet mode"),
 		),
+		mcp.WithBoolean("permissions",
+			mcp.Description("Also report, per cluster, the Kubernetes users and groups of the current identity and the namespaces it can list (structured), to pick asUser, asGroups and kubeNamespace for teleport_kube_login. Namespaces are only known for clusters the session is logged in to"),
+		),
+		server.TimeoutOption(),
 	)
 
 	s.AddTool(listClustersTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -64,7 +69,9 @@ func RegisterKubeTools(s *mcpserver.MCPServer, sc *server.ServerContext) error {
 
 	// teleport_kube_login tool
 	loginTool := mcp.NewTool("teleport_kube_login",
-		mcp.WithDescription("Login to a Kubernetes cluster via Teleport. Updates kubeconfig to enable kubectl access to the specified cluster."),
+		mcp.WithDescription("Login to a Kubernetes cluster via Teleport. Updates the session's private kubeconfig (see teleport_kube_kubeconfig) to enable access to the specified cluster. Without kubeconfig isolation it updates your kubeconfig and is disabled in non-destructive mode."),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
 		mcp.WithString("loginParam",
 			mcp.Description("Remote host login"),
 		),
@@ -120,11 +127,257 @@ func RegisterKubeTools(s *mcpserver.MCPServer, sc *server.ServerContext) error {
 		mcp.WithBoolean("disableAccessRequest",
 			mcp.Description("Disable automatic resource access requests"),
 		),
+		server.TimeoutOption(),
 	)
 
 	s.AddTool(loginTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		return handleKubeLogin(ctx, request, sc)
 	})
 
+	// teleport_kube_logout tool
+	logoutTool := mcp.NewTool("teleport_kube_logout",
+		mcp.WithDescription("Logout of one or all Kubernetes clusters via Teleport. Removes their contexts and credentials from the session's kubeconfig (see teleport_kube_kubeconfig) and reports the removed contexts. Without kubeconfig isolation it updates your kubeconfig and is disabled in non-destructive mode."),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("proxyParam",
+			mcp.Description("Teleport proxy address"),
+		),
+		mcp.WithString("userParam",
+			mcp.Description("Teleport user, defaults to current local user"),
+		),
+		mcp.WithString("identityParam",
+			mcp.Description("Identity file"),
+		),
+		mcp.WithBoolean("insecureParam",
+			mcp.Description("Do not verify server's certificate and host name. Use only in test environments"),
+		),
+		mcp.WithBoolean("debugParam",
+			mcp.Description("Verbose logging to stdout"),
+		),
+		mcp.WithString("cluster",
+			mcp.Description("Specify the Teleport cluster to connect"),
+		),
+		mcp.WithString("kubeCluster",
+			mcp.Description("Name of the Kubernetes cluster to logout of. Mutually exclusive with all."),
+		),
+		mcp.WithBoolean("all",
+			mcp.Description("Logout of every Kubernetes cluster. Mutually exclusive with kubeCluster."),
+		),
+		mcp.WithBoolean("restoreContext",
+			mcp.Description("Reset the kubeconfig's current context to the one it had before the server first logged in with teleport_kube_login"),
+		),
+		server.TimeoutOption(),
+	)
+
+	s.AddTool(logoutTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleKubeLogout(ctx, request, sc)
+	})
+
+	// teleport_kube_kubeconfig tool
+	kubeconfigTool := mcp.NewTool("teleport_kube_kubeconfig",
+		mcp.WithDescription("Show the kubeconfig tsh uses for this session. Unless the server runs with --kubeconfig-isolation=false it is a private file that teleport_kube_login writes instead of your own kubeconfig."),
+		mcp.WithReadOnlyHintAnnotation(true),
+	)
+
+	s.AddTool(kubeconfigTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleKubeKubeconfig(ctx, request, sc)
+	})
+
+	// teleport_kube_get tool
+	getTool := mcp.NewTool("teleport_kube_get",
+		mcp.WithDescription("Get or list Kubernetes resources as JSON through tsh kubectl, without managed fields and last-applied annotations. Large results are truncated; read the rest with teleport_output_read."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("kubeCluster",
+			mcp.Description("Name of the Kubernetes cluster, as listed by teleport_kube_list_clusters. Log in to it with teleport_kube_login first"),
+		),
+		mcp.WithString("cluster",
+			mcp.Description("Teleport cluster the Kubernetes cluster belongs to; defaults to the cluster of the active tsh profile"),
+		),
+		mcp.WithString("contextName",
+			mcp.Description("kubeconfig context to use instead of the <cluster>-<kubeCluster> context teleport_kube_login creates"),
+		),
+		mcp.WithString("kind",
+			mcp.Required(),
+			mcp.Description("Resource kind as accepted by kubectl get (e.g. pods, deployments.apps, nodes)"),
+		),
+		mcp.WithString("name",
+			mcp.Description("Name of a single resource to get; lists all matching resources if omitted"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("Kubernetes namespace; defaults to the namespace of the kubeconfig context"),
+		),
+		mcp.WithBoolean("allNamespaces",
+			mcp.Description("Look in all namespaces"),
+		),
+		mcp.WithString("labelSelector",
+			mcp.Description("Label selector to filter resources by (e.g. app=nginx,tier!=cache)"),
+		),
+		mcp.WithString("fieldSelector",
+			mcp.Description("Field selector to filter resources by (e.g. status.phase=Running)"),
+		),
+		server.TimeoutOption(),
+	)
+
+	s.AddTool(getTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleKubeGet(ctx, request, sc)
+	})
+
+	// teleport_kube_describe tool
+	describeTool := mcp.NewTool("teleport_kube_describe",
+		mcp.WithDescription("Describe Kubernetes resources through tsh kubectl, including their recent events. Long output is truncated; read the rest with teleport_output_read."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("kubeCluster",
+			mcp.Description("Name of the Kubernetes cluster, as listed by teleport_kube_list_clusters. Log in to it with teleport_kube_login first"),
+		),
+		mcp.WithString("cluster",
+			mcp.Description("Teleport cluster the Kubernetes cluster belongs to; defaults to the cluster of the active tsh profile"),
+		),
+		mcp.WithString("contextName",
+			mcp.Description("kubeconfig context to use instead of the <cluster>-<kubeCluster> context teleport_kube_login creates"),
+		),
+		mcp.WithString("kind",
+			mcp.Required(),
+			mcp.Description("Resource kind as accepted by kubectl describe (e.g. pod, deployment, node)"),
+		),
+		mcp.WithString("name",
+			mcp.Description("Name of the resource, or a name prefix; describes all matching resources if omitted"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("Kubernetes namespace; defaults to the namespace of the kubeconfig context"),
+		),
+		mcp.WithBoolean("allNamespaces",
+			mcp.Description("Look in all namespaces"),
+		),
+		mcp.WithString("labelSelector",
+			mcp.Description("Label selector to filter resources by (e.g. app=nginx,tier!=cache)"),
+		),
+		mcp.WithString("fieldSelector",
+			mcp.Description("Field selector to filter resources by (e.g. status.phase=Running)"),
+		),
+		server.TimeoutOption(),
+	)
+
+	s.AddTool(describeTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleKubeDescribe(ctx, request, sc)
+	})
+
+	// teleport_kube_logs tool
+	logsTool := mcp.NewTool("teleport_kube_logs",
+		mcp.WithDescription("Fetch the logs of a Kubernetes pod through tsh kubectl. Long logs are truncated; read the rest with teleport_output_read."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("kubeCluster",
+			mcp.Description("Name of the Kubernetes cluster, as listed by teleport_kube_list_clusters. Log in to it with teleport_kube_login first"),
+		),
+		mcp.WithString("cluster",
+			mcp.Description("Teleport cluster the Kubernetes cluster belongs to; defaults to the cluster of the active tsh profile"),
+		),
+		mcp.WithString("contextName",
+			mcp.Description("kubeconfig context to use instead of the <cluster>-<kubeCluster> context teleport_kube_login creates"),
+		),
+		mcp.WithString("pod",
+			mcp.Required(),
+			mcp.Description("Name of the pod"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("Kubernetes namespace; defaults to the namespace of the kubeconfig context"),
+		),
+		mcp.WithString("container",
+			mcp.Description("Container of the pod; defaults to the pod's default container"),
+		),
+		mcp.WithNumber("tail",
+			mcp.Description("Number of most recent log lines to return (default 200)"),
+			mcp.Min(1),
+		),
+		mcp.WithString("since",
+			mcp.Description("Only return logs newer than this duration (e.g. 10m, 1h)"),
+		),
+		mcp.WithBoolean("previous",
+			mcp.Description("Return the logs of the previous, terminated container instance"),
+		),
+		mcp.WithBoolean("timestamps",
+			mcp.Description("Prefix each log line with its timestamp"),
+		),
+		server.TimeoutOption(),
+	)
+
+	s.AddTool(logsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleKubeLogs(ctx, request, sc)
+	})
+
+	// teleport_kube_events tool
+	eventsTool := mcp.NewTool("teleport_kube_events",
+		mcp.WithDescription("List recent Kubernetes events through tsh kubectl, newest first"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("kubeCluster",
+			mcp.Description("Name of the Kubernetes cluster, as listed by teleport_kube_list_clusters. Log in to it with teleport_kube_login first"),
+		),
+		mcp.WithString("cluster",
+			mcp.Description("Teleport cluster the Kubernetes cluster belongs to; defaults to the cluster of the active tsh profile"),
+		),
+		mcp.WithString("contextName",
+			mcp.Description("kubeconfig context to use instead of the <cluster>-<kubeCluster> context teleport_kube_login creates"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("Kubernetes namespace; defaults to the namespace of the kubeconfig context"),
+		),
+		mcp.WithBoolean("allNamespaces",
+			mcp.Description("Look in all namespaces"),
+		),
+		mcp.WithString("kind",
+			mcp.Description("Only return events about objects of this kind (e.g. Pod, Node)"),
+		),
+		mcp.WithString("name",
+			mcp.Description("Only return events about the object with this name"),
+		),
+		mcp.WithString("type",
+			mcp.Description("Only return events of this type"),
+			mcp.Enum("Normal", "Warning"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of events to return (default 50)"),
+			mcp.Min(1),
+		),
+		server.TimeoutOption(),
+	)
+
+	s.AddTool(eventsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleKubeEvents(ctx, request, sc)
+	})
+
+	// teleport_kube_exec tool
+	execTool := mcp.NewTool("teleport_kube_exec",
+		mcp.WithDescription("Run a one-time, non-interactive command in a Kubernetes pod through tsh kubectl exec; Teleport audits the session. Returns stdout, stderr and the exit code (structured). Plain commands run directly; pipelines and other shell syntax run with sh -c. Output larger than the server's --output-limit is truncated; read the rest with teleport_output_read. In non-destructive mode only read-only commands are allowed."),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithString("kubeCluster",
+			mcp.Description("Name of the Kubernetes cluster, as listed by teleport_kube_list_clusters. Log in to it with teleport_kube_login first"),
+		),
+		mcp.WithString("cluster",
+			mcp.Description("Teleport cluster the Kubernetes cluster belongs to; defaults to the cluster of the active tsh profile"),
+		),
+		mcp.WithString("contextName",
+			mcp.Description("kubeconfig context to use instead of the <cluster>-<kubeCluster> context teleport_kube_login creates"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("Kubernetes namespace of the pod; defaults to the namespace of the kubeconfig context"),
+		),
+		mcp.WithString("pod",
+			mcp.Required(),
+			mcp.Description("Name of the pod"),
+		),
+		mcp.WithString("container",
+			mcp.Description("Container of the pod; defaults to the pod's default container"),
+		),
+		mcp.WithString("command",
+			mcp.Required(),
+			mcp.Description("Command to run in the container (e.g. 'cat /etc/resolv.conf')"),
+		),
+		server.TimeoutOption(),
+	)
+
+	s.AddTool(execTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleKubeExec(ctx, request, sc)
+	})
+
 	return nil
 }
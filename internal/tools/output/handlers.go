@@ -0,0 +1,71 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// handleOutputRead handles the teleport_output_read tool
+func handleOutputRead(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	handle, _ := params["handle"].(string)
+	if handle == "" {
+		return outputError("Error: 'handle' is required. Truncated results name their handle, e.g. handle=out-1."), nil
+	}
+
+	var offset int
+	if value, ok := params["offset"].(float64); ok && value > 0 {
+		offset = int(value)
+	}
+
+	// Pages are never larger than a result may be
+	maxBytes := sc.OutputLimit()
+	if value, ok := params["maxBytes"].(float64); ok && value > 0 && int(value) < maxBytes {
+		maxBytes = int(value)
+	}
+
+	chunk, err := sc.ReadOutput(ctx, handle, offset, maxBytes)
+	if err != nil {
+		return outputError(fmt.Sprintf("Error: %v. Stored output is kept for a limited time; run the command again to get a new handle.", err)), nil
+	}
+
+	return mcp.NewToolResultStructured(chunk, formatOutputChunk(chunk)), nil
+}
+
+// outputError builds an error result for teleport_output_read
+func outputError(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+		IsError: true,
+	}
+}
+
+// formatOutputChunk formats a page of stored output for display
+func formatOutputChunk(chunk *server.OutputChunk) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Output %s, bytes %d-%d of %d:\n", chunk.Handle, chunk.Offset, chunk.NextOffset, chunk.TotalBytes))
+	result.WriteString(chunk.Output)
+	if chunk.More {
+		if !strings.HasSuffix(chunk.Output, "\n") {
+			result.WriteString("\n")
+		}
+		result.WriteString(fmt.Sprintf("\n(more output is available; call again with offset=%d)\n", chunk.NextOffset))
+	}
+	return result.String()
+}
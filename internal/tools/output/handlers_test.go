@@ -0,0 +1,84 @@
+package output
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func createTestRequest(params map[string]interface{}) mcp.CallToolRequest {
+	var request mcp.CallToolRequest
+	request.Params.Arguments = params
+	return request
+}
+
+func resultText(result *mcp.CallToolResult) string {
+	if len(result.Content) == 0 {
+		return ""
+	}
+	text, _ := result.Content[0].(mcp.TextContent)
+	return text.Text
+}
+
+func TestOutputRead(t *testing.T) {
+	sc, err := server.NewServerContext(context.Background(), server.WithOutputLimit(16))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	var lines []string
+	for i := 0; i < 10; i++ {
+		lines = append(lines, "line")
+	}
+	limited := sc.LimitOutput(context.Background(), strings.Join(lines, "\n"))
+	if !limited.Truncated {
+		t.Fatalf("Expected the output to be truncated: %+v", limited)
+	}
+
+	// Pages are capped by the output limit
+	result, err := handleOutputRead(context.Background(), createTestRequest(map[string]interface{}{"handle": limited.Handle, "maxBytes": float64(1000)}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleOutputRead() failed: %v %+v", err, result)
+	}
+	chunk := result.StructuredContent.(*server.OutputChunk)
+	if chunk.Output != "line\nline\nline\nl" || chunk.NextOffset != 16 || !chunk.More {
+		t.Errorf("Unexpected chunk: %+v", chunk)
+	}
+	text := resultText(result)
+	for _, expected := range []string{"Output out-1, bytes 0-16 of 49:", "call again with offset=16"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in result, got: %s", expected, text)
+		}
+	}
+
+	result, err = handleOutputRead(context.Background(), createTestRequest(map[string]interface{}{"handle": limited.Handle, "offset": float64(40)}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleOutputRead() failed: %v %+v", err, result)
+	}
+	chunk = result.StructuredContent.(*server.OutputChunk)
+	if chunk.Output != "line\nline" || chunk.More {
+		t.Errorf("Unexpected chunk: %+v", chunk)
+	}
+}
+
+func TestOutputReadUnknownHandle(t *testing.T) {
+	sc, err := server.NewServerContext(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	for _, params := range []map[string]interface{}{{}, {"handle": "out-42"}} {
+		result, err := handleOutputRead(context.Background(), createTestRequest(params), sc)
+		if err != nil {
+			t.Fatalf("Expected no error from handler, got: %v", err)
+		}
+		if !result.IsError {
+			t.Errorf("Expected an error for %v, got: %s", params, resultText(result))
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package output
+
+import (
+	"context"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// RegisterOutputTools registers the tool that pages through truncated output
+func RegisterOutputTools(s *mcpserver.MCPServer, sc *server.ServerContext) error {
+	// teleport_output_read tool
+	readTool := mcp.NewTool("teleport_output_read",
+		mcp.WithDescription("Read the full output of a tool call whose output was truncated. Truncated results name a handle and the offset where the omitted output starts; call again with the returned nextOffset to page through the rest (structured)"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("handle",
+			mcp.Required(),
+			mcp.Description("Output handle from the truncated result (e.g. out-1)"),
+		),
+		mcp.WithNumber("offset",
+			mcp.Description("Byte offset to read from; defaults to 0"),
+			mcp.Min(0),
+		),
+		mcp.WithNumber("maxBytes",
+			mcp.Description("Maximum number of bytes to return; defaults to the server's output limit"),
+			mcp.Min(1),
+		),
+	)
+
+	s.AddTool(readTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleOutputRead(ctx, request, sc)
+	})
+
+	return nil
+}
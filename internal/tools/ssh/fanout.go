@@ -0,0 +1,302 @@
+package ssh
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/mcp-teleport/internal/policy"
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// NodeResult is the outcome of a command on one node of a label-selector fan-out
+type NodeResult struct {
+	Hostname string `json:"hostname"`
+	NodeID   string `json:"nodeId"`
+	Success  bool   `json:"success"`
+	ExitCode int    `json:"exitCode"`
+	// Stdout and Stderr are the output cut down to the node's share of the
+	// output limit. They are only rendered in the text content, so the
+	// result carries them once.
+	Stdout string `json:"-"`
+	Stderr string `json:"-"`
+	// StdoutBytes and StderrBytes are the sizes of the whole output
+	StdoutBytes int `json:"stdoutBytes"`
+	StderrBytes int `json:"stderrBytes"`
+	// StdoutHandle and StderrHandle name the full output for
+	// teleport_output_read when it was truncated
+	StdoutHandle string `json:"stdoutHandle,omitempty"`
+	StderrHandle string `json:"stderrHandle,omitempty"`
+	// Error describes why the command failed, e.g. a timeout or a connection error
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+	// PolicyRule names the SSH policy rule that allowed the command, if a
+	// policy is configured and a rule rather than its default allowed it
+	PolicyRule string `json:"policyRule,omitempty"`
+}
+
+// FanOutResult is the result of running a command on every node matching a label selector
+type FanOutResult struct {
+	Selector    string       `json:"selector"`
+	Command     string       `json:"command"`
+	Concurrency int          `json:"concurrency"`
+	Succeeded   int          `json:"succeeded"`
+	Failed      int          `json:"failed"`
+	Nodes       []NodeResult `json:"nodes"`
+	DurationMs  int64        `json:"durationMs"`
+}
+
+// sshNode is the part of a tsh ls JSON entry needed to target a node
+type sshNode struct {
+	Metadata struct {
+		Name   string            `json:"name"`
+		Labels map[string]string `json:"labels"`
+	} `json:"metadata"`
+	Spec struct {
+		Hostname string `json:"hostname"`
+	} `json:"spec"`
+}
+
+// isLabelSelector reports whether an SSH destination selects nodes by label
+// (user@key=value,key2=value2) rather than naming a single host
+func isLabelSelector(destination string) bool {
+	_, host := splitDestination(destination)
+	return strings.Contains(host, "=")
+}
+
+// splitDestination splits [login@]host into the login and the host
+func splitDestination(destination string) (string, string) {
+	if login, host, ok := strings.Cut(destination, "@"); ok {
+		return login, host
+	}
+	return "", destination
+}
+
+// handleSSHFanOut runs command on every node matching the label selector in
+// destination, at most the configured number of nodes at a time, and reports
+// the outcome per node
+func handleSSHFanOut(ctx context.Context, sc *server.ServerContext, client *teleport.Client, params map[string]interface{}, destination, command string, options []string) (*mcp.CallToolResult, error) {
+	login, selector := splitDestination(destination)
+
+	concurrency := sc.SSHConcurrency()
+	if value, ok := params["concurrency"].(float64); ok && value > 0 && int(value) < concurrency {
+		concurrency = int(value)
+	}
+
+	// Look up the matching nodes
+	lsArgs := nodeListArgs(params, selector)
+
+	if sc.IsDryRun() {
+		sshArgs := append(append([]string{}, options...), destination, command)
+		text := fmt.Sprintf("DRY RUN: Would look up nodes with: tsh ls %s\nDRY RUN: Would run on each matching node, %d at a time: tsh ssh %s",
+			strings.Join(lsArgs, " "), concurrency, strings.Join(sshArgs, " "))
+		if sc.SSHPolicy() != nil {
+			text += "\nDRY RUN: The SSH policy is evaluated for each matching node before the command runs"
+		}
+		return mcp.NewToolResultText(text), nil
+	}
+
+	nodes, result, err := listNodes(ctx, client, lsArgs, selector)
+	if err != nil {
+		return fanOutError(fmt.Sprintf("Error: %v", err)), nil
+	}
+
+	// Nothing runs unless the SSH policy allows the command on every node
+	var decisions []policy.SSHDecision
+	if sshPolicy := sc.SSHPolicy(); sshPolicy != nil {
+		var denied []string
+		cluster := targetCluster(ctx, client, params, sshPolicy)
+		for _, node := range nodes {
+			decision := sshPolicy.Evaluate(nodeTarget(params, login, cluster, command, node))
+			if !decision.Allowed {
+				denied = append(denied, fmt.Sprintf("- %s: %s", node.Spec.Hostname, decision))
+			}
+			decisions = append(decisions, decision)
+		}
+		if len(denied) > 0 {
+			return fanOutError(fmt.Sprintf("Error: Command denied on %d of %d node(s) matching %s, so it ran nowhere:\n%s\nUse teleport_ssh_policy_explain to see how each rule was evaluated.",
+				len(denied), len(nodes), selector, strings.Join(denied, "\n"))), nil
+		}
+	}
+
+	fanOut := &FanOutResult{
+		Selector:    selector,
+		Command:     command,
+		Concurrency: concurrency,
+		Nodes:       make([]NodeResult, len(nodes)),
+	}
+
+	start := time.Now()
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, node := range nodes {
+		fanOut.Nodes[i] = NodeResult{Hostname: node.Spec.Hostname, NodeID: node.Metadata.Name}
+		if decisions != nil {
+			fanOut.Nodes[i].PolicyRule = decisions[i].Rule
+		}
+
+		// Stop starting new nodes once the call is cancelled
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			fanOut.Nodes[i].Error = fmt.Sprintf("Not started: %v", context.Cause(ctx))
+			continue
+		}
+
+		wg.Add(1)
+		go func(nodeResult *NodeResult) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			runOnNode(ctx, client, options, login, command, nodeResult)
+		}(&fanOut.Nodes[i])
+	}
+	wg.Wait()
+	fanOut.DurationMs = time.Since(start).Milliseconds()
+
+	for _, nodeResult := range fanOut.Nodes {
+		if nodeResult.Success {
+			fanOut.Succeeded++
+		} else {
+			fanOut.Failed++
+		}
+	}
+
+	// The output limit applies to all nodes together; the rest can be read
+	// with teleport_output_read
+	limitNodeOutputs(ctx, sc, fanOut.Nodes)
+
+	callResult := server.WithDiagnostics(mcp.NewToolResultStructured(fanOut, formatFanOutResult(fanOut)), result)
+	// The call only failed as a whole if the command failed everywhere
+	callResult.IsError = fanOut.Succeeded == 0
+	return callResult, nil
+}
+
+// nodeListArgs builds the tsh ls arguments that list the nodes matching a
+// label selector
+func nodeListArgs(params map[string]interface{}, selector string) []string {
+	lsArgs := teleport.FormatArgs(params)
+	lsArgs = append(lsArgs, "--format", "json")
+	if cluster, ok := params["cluster"].(string); ok && cluster != "" {
+		lsArgs = append(lsArgs, "--cluster", cluster)
+	}
+	return append(lsArgs, selector)
+}
+
+// listNodes lists the nodes matching a label selector, sorted by hostname
+func listNodes(ctx context.Context, client *teleport.Client, lsArgs []string, selector string) ([]sshNode, *teleport.ExecutionResult, error) {
+	result := client.ExecuteCommandContext(ctx, "ls", lsArgs)
+	if !result.Success {
+		return nil, result, fmt.Errorf("failed to look up nodes matching %s: %s\n%s", selector, result.ErrorMessage, result.Output)
+	}
+
+	var nodes []sshNode
+	if err := json.Unmarshal([]byte(result.Stdout), &nodes); err != nil {
+		return nil, result, fmt.Errorf("failed to parse tsh ls output: %v", err)
+	}
+	if len(nodes) == 0 {
+		return nil, result, fmt.Errorf("no nodes match %s; use teleport_list_ssh_nodes to check the labels", selector)
+	}
+
+	// Sort nodes by hostname for consistent output
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Spec.Hostname != nodes[j].Spec.Hostname {
+			return nodes[i].Spec.Hostname < nodes[j].Spec.Hostname
+		}
+		return nodes[i].Metadata.Name < nodes[j].Metadata.Name
+	})
+	return nodes, result, nil
+}
+
+// runOnNode runs command on a single node, addressing it by UUID since
+// hostnames need not be unique
+func runOnNode(ctx context.Context, client *teleport.Client, options []string, login, command string, nodeResult *NodeResult) {
+	target := nodeResult.NodeID
+	if login != "" {
+		target = login + "@" + target
+	}
+	args := append(append([]string{}, options...), target, command)
+
+	start := time.Now()
+	result := client.ExecuteCommandContext(ctx, "ssh", args)
+	nodeResult.DurationMs = time.Since(start).Milliseconds()
+
+	nodeResult.Success = result.Success
+	nodeResult.ExitCode = result.StatusCode
+	nodeResult.Stdout = result.Stdout
+	nodeResult.Stderr = result.Stderr
+	if !result.Success {
+		nodeResult.Error = result.ErrorMessage
+	}
+}
+
+// limitNodeOutputs shares the output limit between the stdout and stderr of
+// all nodes
+func limitNodeOutputs(ctx context.Context, sc *server.ServerContext, nodes []NodeResult) {
+	outputs := make([]string, 0, 2*len(nodes))
+	for _, node := range nodes {
+		outputs = append(outputs, node.Stdout, node.Stderr)
+	}
+	limited := sc.LimitOutputs(ctx, outputs...)
+	for i := range nodes {
+		stdout, stderr := limited[2*i], limited[2*i+1]
+		nodes[i].Stdout, nodes[i].StdoutHandle, nodes[i].StdoutBytes = stdout.Text, stdout.Handle, stdout.TotalBytes
+		nodes[i].Stderr, nodes[i].StderrHandle, nodes[i].StderrBytes = stderr.Text, stderr.Handle, stderr.TotalBytes
+	}
+}
+
+// formatFanOutResult formats the per-node results of a fan-out for display
+func formatFanOutResult(fanOut *FanOutResult) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Ran %q on %d node(s) matching %s: %d succeeded, %d failed (%s)\n",
+		fanOut.Command, len(fanOut.Nodes), fanOut.Selector, fanOut.Succeeded, fanOut.Failed, formatDuration(fanOut.DurationMs)))
+
+	for _, node := range fanOut.Nodes {
+		status := "✓"
+		if !node.Success {
+			status = "✗"
+		}
+		result.WriteString(fmt.Sprintf("\n%s %s [%s] exit %d, %s\n", status, node.Hostname, node.NodeID, node.ExitCode, formatDuration(node.DurationMs)))
+		if node.Error != "" {
+			result.WriteString(fmt.Sprintf("  error: %s\n", node.Error))
+		}
+		writeIndented(&result, "stdout", node.Stdout)
+		writeIndented(&result, "stderr", node.Stderr)
+	}
+	return result.String()
+}
+
+// writeIndented writes a labelled, indented block of command output
+func writeIndented(result *strings.Builder, label, output string) {
+	output = strings.TrimRight(output, "\n")
+	if output == "" {
+		return
+	}
+	result.WriteString(fmt.Sprintf("  %s:\n", label))
+	for _, line := range strings.Split(output, "\n") {
+		result.WriteString("    " + line + "\n")
+	}
+}
+
+// formatDuration formats a duration in milliseconds for display
+func formatDuration(ms int64) string {
+	return (time.Duration(ms) * time.Millisecond).String()
+}
+
+// fanOutError builds an error result for a label-selector fan-out
+func fanOutError(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+		IsError: true,
+	}
+}
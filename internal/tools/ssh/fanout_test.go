@@ -0,0 +1,264 @@
+package ssh
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport"
+	"github.com/giantswarm/mcp-teleport/internal/teleport/tshtest"
+)
+
+const (
+	controlPlaneID = "41c3ee63-af98-44b1-9ec6-14cb19ba7e6b"
+	workerID       = "8f0b7d1c-2f4e-4e39-8a7e-0c7f4d2b9a11"
+)
+
+func TestIsLabelSelector(t *testing.T) {
+	tests := map[string]bool{
+		"root@web-server-01":        false,
+		"web-server-01":             false,
+		"root@role=worker":          true,
+		"root@role=worker,env=prod": true,
+		"env=prod":                  true,
+	}
+	for destination, want := range tests {
+		if got := isLabelSelector(destination); got != want {
+			t.Errorf("isLabelSelector(%q) = %v, want %v", destination, got, want)
+		}
+	}
+}
+
+func TestHandleSSHFanOut(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh ls --format json cluster=wallaby$`, tshtest.Response{Stdout: tshtest.Fixture(t, "testdata/tsh_ls.json")}).
+		On(`^tsh ls --format json `, tshtest.Response{Stdout: "[]"}).
+		On(`^tsh ssh root@`+controlPlaneID+` uptime$`, tshtest.Response{Stdout: " 07:15:15 up 92 days\n", Stderr: "WARNING: agent forwarding disabled\n"}).
+		On(`^tsh ssh root@`+workerID+` uptime$`, tshtest.Response{Stderr: "ERROR: access denied to root connecting to wallaby-worker-7x2kq\n", ExitCode: 255})
+
+	sc, err := server.NewServerContext(context.Background(), server.WithRunner(runner))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	result, err := handleSSH(context.Background(), createTestRequest(map[string]interface{}{
+		"destination": "root@cluster=wallaby",
+		"command":     "uptime",
+	}), sc)
+	if err != nil {
+		t.Fatalf("handleSSH() error = %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Partial failure should not fail the call: %s", extractTextFromContent(result.Content[0]))
+	}
+
+	fanOut := result.StructuredContent.(*FanOutResult)
+	if fanOut.Succeeded != 1 || fanOut.Failed != 1 || len(fanOut.Nodes) != 2 {
+		t.Fatalf("Unexpected summary: %+v", fanOut)
+	}
+
+	controlPlane := fanOut.Nodes[0]
+	if controlPlane.Hostname != "wallaby-9wldd" || controlPlane.NodeID != controlPlaneID || !controlPlane.Success {
+		t.Errorf("Unexpected control plane result: %+v", controlPlane)
+	}
+	if controlPlane.Stdout != " 07:15:15 up 92 days\n" || controlPlane.Stderr != "WARNING: agent forwarding disabled\n" {
+		t.Errorf("Streams not separated: stdout=%q stderr=%q", controlPlane.Stdout, controlPlane.Stderr)
+	}
+	if controlPlane.StdoutBytes != 21 || controlPlane.StderrBytes != 35 {
+		t.Errorf("Unexpected output sizes: %+v", controlPlane)
+	}
+
+	worker := fanOut.Nodes[1]
+	if worker.Hostname != "wallaby-worker-7x2kq" || worker.Success || worker.ExitCode != 255 || !strings.Contains(worker.Stderr, "access denied") {
+		t.Errorf("Unexpected worker result: %+v", worker)
+	}
+
+	// The output is only in the text content
+	structured, err := json.Marshal(result.StructuredContent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(structured), "up 92 days") || strings.Contains(string(structured), "access denied") {
+		t.Errorf("Expected no output in the structured content: %s", structured)
+	}
+
+	text := extractTextFromContent(result.Content[0])
+	for _, expected := range []string{"on 2 node(s) matching cluster=wallaby: 1 succeeded, 1 failed", "✓ wallaby-9wldd [" + controlPlaneID + "] exit 0", "✗ wallaby-worker-7x2kq", "access denied"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in result, got: %s", expected, text)
+		}
+	}
+
+	// No matching nodes is an error
+	result, _ = handleSSH(context.Background(), createTestRequest(map[string]interface{}{
+		"destination": "root@cluster=unknown",
+		"command":     "uptime",
+	}), sc)
+	if !result.IsError || !strings.Contains(extractTextFromContent(result.Content[0]), "Error: no nodes match cluster=unknown") {
+		t.Errorf("Expected an error for an empty selection, got: %s", extractTextFromContent(result.Content[0]))
+	}
+}
+
+func TestHandleSSHFanOutOutputLimit(t *testing.T) {
+	var nodes []string
+	for i := 0; i < 8; i++ {
+		nodes = append(nodes, fmt.Sprintf(`{"metadata": {"name": "node-%02d"}, "spec": {"hostname": "host-%02d"}}`, i, i))
+	}
+	runner := tshtest.NewRunner().
+		On(`^tsh ls --format json env=prod$`, tshtest.Response{Stdout: "[" + strings.Join(nodes, ",") + "]"}).
+		On(`^tsh ssh root@node-00 `, tshtest.Response{Stdout: "ok\n"}).
+		On(`^tsh ssh `, tshtest.Response{Stdout: strings.Repeat("x", 2000)})
+
+	tests := []struct {
+		name      string
+		limit     int
+		wantSpill bool
+	}{
+		{name: "shared limit", limit: 4000},
+		{name: "spilled", limit: 1000, wantSpill: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, err := server.NewServerContext(context.Background(), server.WithRunner(runner), server.WithOutputLimit(tt.limit))
+			if err != nil {
+				t.Fatalf("Failed to create server context: %v", err)
+			}
+			defer sc.Shutdown()
+
+			result, err := handleSSH(context.Background(), createTestRequest(map[string]interface{}{
+				"destination": "root@env=prod",
+				"command":     "cat log",
+			}), sc)
+			if err != nil || result.IsError {
+				t.Fatalf("handleSSH() failed: %v %+v", err, result)
+			}
+
+			fanOut := result.StructuredContent.(*FanOutResult)
+			// Small output is kept whole
+			if node := fanOut.Nodes[0]; node.Stdout != "ok\n" || node.StdoutHandle != "" {
+				t.Errorf("Expected the output of node-00 to be kept, got %+v", node)
+			}
+
+			kept := 0
+			for _, node := range fanOut.Nodes[1:] {
+				if node.StdoutHandle == "" {
+					t.Fatalf("Expected the output of %s to be stored, got %+v", node.NodeID, node)
+				}
+				kept += strings.Count(node.Stdout, "x")
+				if spilled := !strings.Contains(node.Stdout, "x"); spilled != tt.wantSpill {
+					t.Errorf("Unexpected output of %s: %q", node.NodeID, node.Stdout)
+				}
+
+				chunk, err := sc.ReadOutput(context.Background(), node.StdoutHandle, 0, 0)
+				if err != nil || len(chunk.Output) != 2000 {
+					t.Errorf("Expected the whole output of %s to be readable, got %+v %v", node.NodeID, chunk, err)
+				}
+			}
+			if kept > tt.limit {
+				t.Errorf("Kept %d bytes of output, more than the limit of %d", kept, tt.limit)
+			}
+		})
+	}
+}
+
+// concurrencyRunner answers tsh ls with n nodes and records how many tsh ssh
+// invocations run at the same time
+type concurrencyRunner struct {
+	nodes   int
+	mutex   sync.Mutex
+	running int
+	peak    int
+}
+
+func (r *concurrencyRunner) Run(ctx context.Context, cmd teleport.Command) (int, error) {
+	if cmd.Args[0] == "ls" {
+		var nodes []string
+		for i := 0; i < r.nodes; i++ {
+			nodes = append(nodes, fmt.Sprintf(`{"metadata": {"name": "node-%02d"}, "spec": {"hostname": "host-%02d"}}`, i, i))
+		}
+		fmt.Fprintf(cmd.Stdout, "[%s]", strings.Join(nodes, ","))
+		return 0, nil
+	}
+
+	r.mutex.Lock()
+	r.running++
+	if r.running > r.peak {
+		r.peak = r.running
+	}
+	r.mutex.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	r.mutex.Lock()
+	r.running--
+	r.mutex.Unlock()
+	return 0, nil
+}
+
+func TestHandleSSHFanOutConcurrency(t *testing.T) {
+	tests := []struct {
+		name        string
+		serverLimit int
+		params      map[string]interface{}
+		wantPeak    int
+	}{
+		{name: "server limit", serverLimit: 3, params: map[string]interface{}{}, wantPeak: 3},
+		{name: "lower per-call limit", serverLimit: 3, params: map[string]interface{}{"concurrency": float64(2)}, wantPeak: 2},
+		{name: "per-call limit is capped", serverLimit: 3, params: map[string]interface{}{"concurrency": float64(8)}, wantPeak: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := &concurrencyRunner{nodes: 8}
+			sc, err := server.NewServerContext(context.Background(),
+				server.WithRunner(runner),
+				server.WithSSHConcurrency(tt.serverLimit),
+			)
+			if err != nil {
+				t.Fatalf("Failed to create server context: %v", err)
+			}
+			defer sc.Shutdown()
+
+			tt.params["destination"] = "root@env=prod"
+			tt.params["command"] = "uptime"
+			result, err := handleSSH(context.Background(), createTestRequest(tt.params), sc)
+			if err != nil || result.IsError {
+				t.Fatalf("handleSSH() failed: %v %+v", err, result)
+			}
+
+			fanOut := result.StructuredContent.(*FanOutResult)
+			if fanOut.Succeeded != 8 || fanOut.Concurrency != tt.wantPeak {
+				t.Errorf("Unexpected summary: %+v", fanOut)
+			}
+			if runner.peak != tt.wantPeak {
+				t.Errorf("Peak concurrency = %d, want %d", runner.peak, tt.wantPeak)
+			}
+		})
+	}
+}
+
+func TestHandleSSHFanOutNonDestructive(t *testing.T) {
+	runner := tshtest.NewRunner()
+	sc, err := server.NewServerContext(context.Background(), server.WithRunner(runner), server.WithNonDestructiveMode(true))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	result, _ := handleSSH(context.Background(), createTestRequest(map[string]interface{}{
+		"destination": "root@env=prod",
+		"command":     "rm -rf /tmp/cache",
+	}), sc)
+	if !result.IsError {
+		t.Error("Expected mutating fan-out command to be rejected")
+	}
+	if len(runner.Calls()) != 0 {
+		t.Errorf("Expected no tsh invocations, got %v", runner.Calls())
+	}
+}
@@ -0,0 +1,569 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// fileCommonParams are the common tsh parameters the file tools declare
+var fileCommonParams = []string{"loginParam", "proxyParam", "userParam", "identityParam", "insecureParam", "debugParam"}
+
+// defaultMaxEntries is how many directory entries teleport_dir_list returns
+// unless the client asks for another number
+const defaultMaxEntries = 1000
+
+// RemoteFile is the result of teleport_file_read
+type RemoteFile struct {
+	Destination string `json:"destination"`
+	Path        string `json:"path"`
+	SizeBytes   int64  `json:"sizeBytes"`
+	// Encoding is text or base64
+	Encoding string `json:"encoding"`
+	Content  string `json:"content"`
+	// Offset and NextOffset are set for byte ranges; More is set when the
+	// file continues at NextOffset
+	Offset     int64 `json:"offset,omitempty"`
+	NextOffset int64 `json:"nextOffset,omitempty"`
+	More       bool  `json:"more,omitempty"`
+	// StartLine and EndLine are set for line ranges
+	StartLine int `json:"startLine,omitempty"`
+	EndLine   int `json:"endLine,omitempty"`
+	// Handle names the full content for teleport_output_read if a line range
+	// was truncated
+	Handle string `json:"handle,omitempty"`
+}
+
+// WrittenFile is the result of teleport_file_write
+type WrittenFile struct {
+	Destination string `json:"destination"`
+	Path        string `json:"path"`
+	SizeBytes   int64  `json:"sizeBytes"`
+	SHA256      string `json:"sha256"`
+	// Verified is set when the checksum of the remote file matched the content
+	Verified   bool   `json:"verified"`
+	BackupPath string `json:"backupPath,omitempty"`
+}
+
+// DirectoryEntry is a file in a directory listed by teleport_dir_list
+type DirectoryEntry struct {
+	Name string `json:"name"`
+	// Type is file, directory, symlink or other
+	Type       string    `json:"type"`
+	SizeBytes  int64     `json:"sizeBytes"`
+	Mode       string    `json:"mode"`
+	Owner      string    `json:"owner"`
+	Group      string    `json:"group"`
+	Modified   time.Time `json:"modified"`
+	LinkTarget string    `json:"linkTarget,omitempty"`
+}
+
+// DirectoryListing is the result of teleport_dir_list
+type DirectoryListing struct {
+	Destination string           `json:"destination"`
+	Path        string           `json:"path"`
+	Entries     []DirectoryEntry `json:"entries"`
+	// Truncated is set when the directory has more than maxEntries entries
+	Truncated bool `json:"truncated"`
+}
+
+// handleFileRead handles the teleport_file_read tool
+func handleFileRead(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Create teleport client
+	client := sc.TeleportClient()
+
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	destination, path, errResult := fileTarget(params)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	encoding, errResult := fileEncoding(params)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	offset, hasOffset := params["offset"].(float64)
+	length, hasLength := params["length"].(float64)
+	startLine, hasStartLine := params["startLine"].(float64)
+	endLine, hasEndLine := params["endLine"].(float64)
+	if (hasOffset || hasLength) && (hasStartLine || hasEndLine) {
+		return fileError("Error: Use either offset and length or startLine and endLine, not both"), nil
+	}
+	if offset < 0 || (hasLength && length < 1) || (hasStartLine && startLine < 1) || (hasEndLine && endLine < 1) {
+		return fileError("Error: offset must not be negative; length, startLine and endLine must be positive"), nil
+	}
+
+	file := RemoteFile{Destination: destination, Path: path, Encoding: encoding}
+
+	// The first line of output is the file size, the rest the requested range
+	quoted := shellQuote(path)
+	var read string
+	lineRange := hasStartLine || hasEndLine
+	if lineRange {
+		file.StartLine = 1
+		if hasStartLine {
+			file.StartLine = int(startLine)
+		}
+		end := "$"
+		if hasEndLine {
+			if int(endLine) < file.StartLine {
+				return fileError("Error: endLine must not be before startLine"), nil
+			}
+			file.EndLine = int(endLine)
+			end = strconv.Itoa(file.EndLine)
+		}
+		read = fmt.Sprintf("sed -n '%d,%sp' %s", file.StartLine, end, quoted)
+	} else {
+		// Byte ranges are read a page at a time
+		file.Offset = int64(offset)
+		limit := int64(sc.OutputLimit())
+		if hasLength && int64(length) < limit {
+			limit = int64(length)
+		}
+		read = fmt.Sprintf("tail -c +%d %s | head -c %d", file.Offset+1, quoted, limit)
+	}
+	if encoding == "base64" {
+		read += " | base64"
+	}
+	script := fmt.Sprintf("[ -f %[1]s ] || { echo \"not a regular file: \"%[1]s >&2; exit 1; }; wc -c < %[1]s; %[2]s", quoted, read)
+	if errResult := checkFilePolicy(ctx, sc, client, params, destination, script); errResult != nil {
+		return errResult, nil
+	}
+
+	if sc.IsDryRun() {
+		return mcp.NewToolResultText(fmt.Sprintf("DRY RUN: Would run on %s: %s", destination, script)), nil
+	}
+
+	result := client.ExecuteCommandContext(ctx, "ssh", append(fileSSHOptions(params), destination, script))
+	if !result.Success {
+		return fileError(fmt.Sprintf("Error: Failed to read %s on %s: %s\n%s", path, destination, result.ErrorMessage, result.Output)), nil
+	}
+
+	sizeLine, content, _ := strings.Cut(result.Stdout, "\n")
+	size, err := strconv.ParseInt(strings.TrimSpace(sizeLine), 10, 64)
+	if err != nil {
+		return fileError(fmt.Sprintf("Error: Unexpected output reading %s:\n%s", path, result.Output)), nil
+	}
+	file.SizeBytes = size
+
+	if encoding == "base64" {
+		content = strings.Join(strings.Fields(content), "")
+	} else {
+		if !lineRange && content != "" {
+			// A byte range may start or end inside a multibyte character
+			trimmed, skipped := trimSplitRunes(content, file.Offset > 0, file.Offset+int64(len(content)) < size)
+			if trimmed == "" {
+				return fileError(fmt.Sprintf("Error: The %d byte(s) at offset %d of %s are part of a single character; read a longer range or use encoding=base64", len(content), file.Offset, path)), nil
+			}
+			content = trimmed
+			file.Offset += int64(skipped)
+		}
+		if !utf8.ValidString(content) {
+			return fileError(fmt.Sprintf("Error: %s is not valid UTF-8 text; read it with encoding=base64", path)), nil
+		}
+	}
+
+	if lineRange {
+		// Large line ranges are truncated; the rest can be read with teleport_output_read
+		limited := sc.LimitOutput(ctx, content)
+		file.Content, file.Handle = limited.Text, limited.Handle
+	} else {
+		file.Content = content
+		read := int64(len(content))
+		if encoding == "base64" {
+			decoded, err := base64.StdEncoding.DecodeString(content)
+			if err != nil {
+				return fileError(fmt.Sprintf("Error: Unexpected base64 output reading %s: %v", path, err)), nil
+			}
+			read = int64(len(decoded))
+		}
+		file.NextOffset = file.Offset + read
+		file.More = file.NextOffset < size
+	}
+
+	return server.WithDiagnostics(mcp.NewToolResultStructured(file, formatRemoteFile(&file)), result), nil
+}
+
+// handleFileWrite handles the teleport_file_write tool
+func handleFileWrite(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Writing files changes the remote host
+	if err := sc.CheckMutation("teleport_file_write"); err != nil {
+		return fileError(fmt.Sprintf("Error: %v", err)), nil
+	}
+
+	// Create teleport client
+	client := sc.TeleportClient()
+
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	destination, path, errResult := fileTarget(params)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	encoding, errResult := fileEncoding(params)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	content, ok := params["content"].(string)
+	if !ok {
+		return fileError("Error: content is required"), nil
+	}
+	data := []byte(content)
+	if encoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(content)
+		if err != nil {
+			return fileError(fmt.Sprintf("Error: Invalid base64 content: %v", err)), nil
+		}
+		data = decoded
+	}
+	checksum := sha256.Sum256(data)
+
+	backup, _ := params["backup"].(bool)
+	verify := true
+	if value, ok := params["verify"].(bool); ok {
+		verify = value
+	}
+
+	// Write the content from stdin, reporting the backup path, checksum and
+	// size on prefixed lines
+	quoted := shellQuote(path)
+	script := fmt.Sprintf("set -e; [ ! -d %[1]s ] || { echo \"is a directory: \"%[1]s >&2; exit 1; }; ", quoted)
+	if backup {
+		backupPath := fmt.Sprintf("%s.bak.%s", path, time.Now().UTC().Format("20060102T150405Z"))
+		script += fmt.Sprintf("if [ -e %[1]s ]; then cp -p %[1]s %[2]s; echo backup:%[2]s; fi; ", quoted, shellQuote(backupPath))
+	}
+	script += fmt.Sprintf("cat > %s; ", quoted)
+	if verify {
+		script += fmt.Sprintf("echo sha256:$(sha256sum %[1]s 2>/dev/null || shasum -a 256 %[1]s); ", quoted)
+	}
+	script += fmt.Sprintf("echo size:$(wc -c < %s)", quoted)
+	if errResult := checkFilePolicy(ctx, sc, client, params, destination, script); errResult != nil {
+		return errResult, nil
+	}
+
+	if sc.IsDryRun() {
+		return mcp.NewToolResultText(fmt.Sprintf("DRY RUN: Would write %d bytes on %s with: %s", len(data), destination, script)), nil
+	}
+
+	result := client.ExecuteCommandInputContext(ctx, "ssh", append(fileSSHOptions(params), destination, script), bytes.NewReader(data))
+	if !result.Success {
+		return fileError(fmt.Sprintf("Error: Failed to write %s on %s: %s\n%s", path, destination, result.ErrorMessage, result.Output)), nil
+	}
+
+	written := WrittenFile{
+		Destination: destination,
+		Path:        path,
+		SizeBytes:   -1,
+		SHA256:      hex.EncodeToString(checksum[:]),
+	}
+	var remoteChecksum string
+	for _, line := range strings.Split(result.Stdout, "\n") {
+		switch {
+		case strings.HasPrefix(line, "backup:"):
+			written.BackupPath = strings.TrimPrefix(line, "backup:")
+		case strings.HasPrefix(line, "sha256:"):
+			if fields := strings.Fields(strings.TrimPrefix(line, "sha256:")); len(fields) > 0 {
+				remoteChecksum = fields[0]
+			}
+		case strings.HasPrefix(line, "size:"):
+			if size, err := strconv.ParseInt(strings.TrimSpace(strings.TrimPrefix(line, "size:")), 10, 64); err == nil {
+				written.SizeBytes = size
+			}
+		}
+	}
+
+	if verify {
+		written.Verified = remoteChecksum == written.SHA256 && written.SizeBytes == int64(len(data))
+		if !written.Verified {
+			callResult := mcp.NewToolResultStructured(written, fmt.Sprintf("Error: Verification of %s on %s failed: wrote %d bytes with SHA-256 %s, but the remote file has %d bytes with SHA-256 %q",
+				path, destination, len(data), written.SHA256, written.SizeBytes, remoteChecksum))
+			callResult.IsError = true
+			return server.WithDiagnostics(callResult, result), nil
+		}
+	}
+
+	return server.WithDiagnostics(mcp.NewToolResultStructured(written, formatWrittenFile(&written)), result), nil
+}
+
+// handleDirList handles the teleport_dir_list tool
+func handleDirList(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Create teleport client
+	client := sc.TeleportClient()
+
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	destination, path, errResult := fileTarget(params)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	maxEntries := defaultMaxEntries
+	if value, ok := params["maxEntries"].(float64); ok {
+		if value < 1 {
+			return fileError("Error: maxEntries must be positive"), nil
+		}
+		maxEntries = int(value)
+	}
+
+	// One tab-separated line per entry; one more entry than requested is
+	// listed to tell whether the listing was truncated
+	quoted := shellQuote(path)
+	script := fmt.Sprintf("[ -d %[1]s ] || { echo \"not a directory: \"%[1]s >&2; exit 1; }; find %[1]s -mindepth 1 -maxdepth 1 -printf '%%y\\t%%s\\t%%m\\t%%u\\t%%g\\t%%T@\\t%%l\\t%%f\\n' | head -n %[2]d",
+		quoted, maxEntries+1)
+	if errResult := checkFilePolicy(ctx, sc, client, params, destination, script); errResult != nil {
+		return errResult, nil
+	}
+
+	if sc.IsDryRun() {
+		return mcp.NewToolResultText(fmt.Sprintf("DRY RUN: Would run on %s: %s", destination, script)), nil
+	}
+
+	result := client.ExecuteCommandContext(ctx, "ssh", append(fileSSHOptions(params), destination, script))
+	if !result.Success {
+		return fileError(fmt.Sprintf("Error: Failed to list %s on %s: %s\n%s", path, destination, result.ErrorMessage, result.Output)), nil
+	}
+
+	listing := DirectoryListing{Destination: destination, Path: path, Entries: parseDirectoryEntries(result.Stdout)}
+	if len(listing.Entries) > maxEntries {
+		listing.Entries = listing.Entries[:maxEntries]
+		listing.Truncated = true
+	}
+	sort.Slice(listing.Entries, func(i, j int) bool {
+		return listing.Entries[i].Name < listing.Entries[j].Name
+	})
+
+	return server.WithDiagnostics(mcp.NewToolResultStructured(listing, formatDirectoryListing(&listing)), result), nil
+}
+
+// fileTarget returns the destination and path of a file tool call, or an
+// error result if they are missing or invalid
+func fileTarget(params map[string]interface{}) (string, string, *mcp.CallToolResult) {
+	destination, _ := params["destination"].(string)
+	if destination == "" {
+		return "", "", fileError("Error: Destination host is required")
+	}
+	if isLabelSelector(destination) || strings.HasPrefix(destination, "-") {
+		return "", "", fileError(fmt.Sprintf("Error: Invalid destination %q, expected a single host as [user@]host", destination))
+	}
+
+	path, _ := params["path"].(string)
+	if path == "" {
+		return "", "", fileError("Error: path is required")
+	}
+	if strings.ContainsAny(path, "\x00\n") {
+		return "", "", fileError("Error: path must not contain NUL or newline characters")
+	}
+	return destination, path, nil
+}
+
+// checkFilePolicy returns an error result if the SSH policy denies running the
+// script of a file tool on destination
+func checkFilePolicy(ctx context.Context, sc *server.ServerContext, client *teleport.Client, params map[string]interface{}, destination, script string) *mcp.CallToolResult {
+	if decision := checkSSHPolicy(ctx, sc, client, params, destination, script); decision != nil && !decision.Allowed {
+		return fileError(fmt.Sprintf("Error: Command %s. Use teleport_ssh_policy_explain to see how each rule was evaluated.", decision))
+	}
+	return nil
+}
+
+// fileSSHOptions builds the tsh ssh arguments of the file tools from the
+// parameters they declare, so that no other tsh flag reaches tsh
+func fileSSHOptions(params map[string]interface{}) []string {
+	declared := make(map[string]interface{})
+	for _, name := range fileCommonParams {
+		if value, ok := params[name]; ok {
+			declared[name] = value
+		}
+	}
+	args := teleport.FormatArgs(declared)
+
+	if cluster, ok := params["cluster"].(string); ok && cluster != "" {
+		args = append(args, "--cluster", cluster)
+	}
+	if port, ok := params["port"].(float64); ok {
+		args = append(args, fmt.Sprintf("--port=%d", int(port)))
+	}
+	return args
+}
+
+// trimSplitRunes drops the bytes of multibyte characters cut off at the start
+// and, if atEnd is set, the end of a byte range. It returns the rest and how
+// many bytes were dropped from the start.
+func trimSplitRunes(content string, atStart, atEnd bool) (string, int) {
+	start := 0
+	if atStart {
+		for start < len(content) && start < utf8.UTFMax && !utf8.RuneStart(content[start]) {
+			start++
+		}
+	}
+	content = content[start:]
+
+	if atEnd {
+		for i := len(content) - 1; i >= 0 && i >= len(content)-utf8.UTFMax; i-- {
+			if utf8.RuneStart(content[i]) {
+				if !utf8.FullRuneInString(content[i:]) {
+					content = content[:i]
+				}
+				break
+			}
+		}
+	}
+	return content, start
+}
+
+// fileEncoding returns the content encoding of a file tool call
+func fileEncoding(params map[string]interface{}) (string, *mcp.CallToolResult) {
+	encoding, _ := params["encoding"].(string)
+	switch encoding {
+	case "":
+		return "text", nil
+	case "text", "base64":
+		return encoding, nil
+	default:
+		return "", fileError(fmt.Sprintf("Error: Invalid encoding %q, expected text or base64", encoding))
+	}
+}
+
+// parseDirectoryEntries parses the find -printf output of teleport_dir_list
+func parseDirectoryEntries(output string) []DirectoryEntry {
+	entries := []DirectoryEntry{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(line, "\t", 8)
+		if len(fields) != 8 {
+			continue
+		}
+		entry := DirectoryEntry{
+			Name:       fields[7],
+			Type:       "other",
+			Mode:       fields[2],
+			Owner:      fields[3],
+			Group:      fields[4],
+			LinkTarget: fields[6],
+		}
+		switch fields[0] {
+		case "f":
+			entry.Type = "file"
+		case "d":
+			entry.Type = "directory"
+		case "l":
+			entry.Type = "symlink"
+		}
+		entry.SizeBytes, _ = strconv.ParseInt(fields[1], 10, 64)
+		if seconds, err := strconv.ParseFloat(fields[5], 64); err == nil {
+			whole, fraction := math.Modf(seconds)
+			entry.Modified = time.Unix(int64(whole), int64(fraction*1e9)).UTC().Truncate(time.Second)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// formatRemoteFile formats the result of teleport_file_read for display
+func formatRemoteFile(file *RemoteFile) string {
+	var result strings.Builder
+	switch {
+	case file.StartLine > 0 && file.EndLine > 0:
+		result.WriteString(fmt.Sprintf("%s:%s (%d bytes), lines %d-%d:\n\n", file.Destination, file.Path, file.SizeBytes, file.StartLine, file.EndLine))
+	case file.StartLine > 0:
+		result.WriteString(fmt.Sprintf("%s:%s (%d bytes), from line %d:\n\n", file.Destination, file.Path, file.SizeBytes, file.StartLine))
+	default:
+		result.WriteString(fmt.Sprintf("%s:%s (%d bytes), bytes %d-%d:\n\n", file.Destination, file.Path, file.SizeBytes, file.Offset, file.NextOffset))
+	}
+	result.WriteString(file.Content)
+	if file.More {
+		result.WriteString(fmt.Sprintf("\n\n[the file continues; call again with offset=%d]", file.NextOffset))
+	}
+	return result.String()
+}
+
+// formatWrittenFile formats the result of teleport_file_write for display
+func formatWrittenFile(written *WrittenFile) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Wrote %d bytes to %s:%s\n", written.SizeBytes, written.Destination, written.Path))
+	result.WriteString(fmt.Sprintf("SHA-256: %s", written.SHA256))
+	if written.Verified {
+		result.WriteString(" (verified)")
+	}
+	result.WriteString("\n")
+	if written.BackupPath != "" {
+		result.WriteString(fmt.Sprintf("Backup: %s\n", written.BackupPath))
+	}
+	return result.String()
+}
+
+// formatDirectoryListing formats the result of teleport_dir_list for display
+func formatDirectoryListing(listing *DirectoryListing) string {
+	if len(listing.Entries) == 0 {
+		return fmt.Sprintf("%s:%s is empty", listing.Destination, listing.Path)
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("%s:%s (%d entries):\n\n", listing.Destination, listing.Path, len(listing.Entries)))
+	for _, entry := range listing.Entries {
+		name := entry.Name
+		switch entry.Type {
+		case "directory":
+			name += "/"
+		case "symlink":
+			name += " -> " + entry.LinkTarget
+		}
+		result.WriteString(fmt.Sprintf("%-4s %-8s %-8s %10d %s %s\n",
+			entry.Mode, entry.Owner, entry.Group, entry.SizeBytes, entry.Modified.Format(time.RFC3339), name))
+	}
+	if listing.Truncated {
+		result.WriteString("\n[listing truncated; raise maxEntries to see more]")
+	}
+	return result.String()
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// fileError builds an error result for a remote file request
+func fileError(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+		IsError: true,
+	}
+}
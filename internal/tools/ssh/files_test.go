@@ -0,0 +1,263 @@
+package ssh
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport/tshtest"
+)
+
+func TestHandleFileRead(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh ssh root@wallaby-9wldd .*; `+regexp.QuoteMeta(`sed -n '2,3p' '/etc/kubernetes/kubelet.conf'`)+`$`, tshtest.Response{Stdout: "1234\nclusters:\n- cluster:\n"}).
+		On(`^tsh ssh root@wallaby-9wldd .*; `+regexp.QuoteMeta(`tail -c +3 '/var/log/syslog' | head -c 5`)+`$`, tshtest.Response{Stdout: "20\nllo w"}).
+		On(`^tsh ssh root@wallaby-9wldd .*; `+regexp.QuoteMeta(`tail -c +3 '/srv/greeting' | head -c 7`)+`$`, tshtest.Response{Stdout: "13\n\xa9llo w\xc3"}).
+		On(`^tsh ssh root@wallaby-9wldd .*`+regexp.QuoteMeta(`| base64`)+`$`, tshtest.Response{Stdout: "3\nAAEC\n"})
+
+	sc, err := server.NewServerContext(context.Background(), server.WithRunner(runner))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	tests := []struct {
+		name     string
+		params   map[string]interface{}
+		expected RemoteFile
+	}{
+		{
+			name:   "line range",
+			params: map[string]interface{}{"path": "/etc/kubernetes/kubelet.conf", "startLine": float64(2), "endLine": float64(3)},
+			expected: RemoteFile{
+				Path: "/etc/kubernetes/kubelet.conf", SizeBytes: 1234, Encoding: "text",
+				Content: "clusters:\n- cluster:\n", StartLine: 2, EndLine: 3,
+			},
+		},
+		{
+			name:   "byte range",
+			params: map[string]interface{}{"path": "/var/log/syslog", "offset": float64(2), "length": float64(5)},
+			expected: RemoteFile{
+				Path: "/var/log/syslog", SizeBytes: 20, Encoding: "text",
+				Content: "llo w", Offset: 2, NextOffset: 7, More: true,
+			},
+		},
+		{
+			// héllo wörld, cut inside é and ö
+			name:   "byte range splitting characters",
+			params: map[string]interface{}{"path": "/srv/greeting", "offset": float64(2), "length": float64(7)},
+			expected: RemoteFile{
+				Path: "/srv/greeting", SizeBytes: 13, Encoding: "text",
+				Content: "llo w", Offset: 3, NextOffset: 8, More: true,
+			},
+		},
+		{
+			name:   "base64",
+			params: map[string]interface{}{"path": "/usr/bin/true", "encoding": "base64"},
+			expected: RemoteFile{
+				Path: "/usr/bin/true", SizeBytes: 3, Encoding: "base64",
+				Content: "AAEC", NextOffset: 3,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.params["destination"] = "root@wallaby-9wldd"
+			result, err := handleFileRead(context.Background(), createTestRequest(tt.params), sc)
+			if err != nil || result.IsError {
+				t.Fatalf("handleFileRead() failed: %v %+v", err, result)
+			}
+			file := result.StructuredContent.(RemoteFile)
+			tt.expected.Destination = "root@wallaby-9wldd"
+			if file != tt.expected {
+				t.Errorf("Got %+v, want %+v", file, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHandleFileReadUndeclaredParameters(t *testing.T) {
+	runner := tshtest.NewRunner().On(`^tsh ssh `, tshtest.Response{Stdout: "5\nhello"})
+	sc, err := server.NewServerContext(context.Background(), server.WithRunner(runner), server.WithNonDestructiveMode(true))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	result, err := handleFileRead(context.Background(), createTestRequest(map[string]interface{}{
+		"destination":    "root@wallaby-9wldd",
+		"path":           "/etc/hostname",
+		"loginParam":     "admin",
+		"localCommand":   "rm -rf ~",
+		"openSSHOptions": "ProxyCommand=sh",
+		"localParam":     "rm -rf ~",
+	}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleFileRead() failed: %v %+v", err, result)
+	}
+
+	// Only the parameters the tool declares reach tsh
+	calls := runner.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("Expected one tsh call, got %v", calls)
+	}
+	if got := strings.Join(calls[0][:5], " "); got != "tsh ssh -l admin root@wallaby-9wldd" {
+		t.Errorf("Unexpected tsh arguments: %v", calls[0])
+	}
+}
+
+func TestHandleFileReadInvalidParameters(t *testing.T) {
+	sc := &server.ServerContext{}
+
+	for _, params := range []map[string]interface{}{
+		{"path": "/etc/hosts"},
+		{"destination": "root@env=prod", "path": "/etc/hosts"},
+		{"destination": "root@wallaby-9wldd"},
+		{"destination": "root@wallaby-9wldd", "path": "/etc/hosts", "offset": float64(10), "startLine": float64(1)},
+		{"destination": "root@wallaby-9wldd", "path": "/etc/hosts", "startLine": float64(5), "endLine": float64(2)},
+		{"destination": "root@wallaby-9wldd", "path": "/etc/hosts", "encoding": "hex"},
+	} {
+		result, err := handleFileRead(context.Background(), createTestRequest(params), sc)
+		if err != nil {
+			t.Fatalf("Expected no error from handler, got: %v", err)
+		}
+		if !result.IsError {
+			t.Errorf("Expected %v to be rejected, got: %+v", params, result)
+		}
+	}
+}
+
+func TestHandleFileWrite(t *testing.T) {
+	content := "maxPods: 110\n"
+	checksum := sha256.Sum256([]byte(content))
+	hash := hex.EncodeToString(checksum[:])
+
+	runner := tshtest.NewRunner().
+		On(`^tsh ssh root@wallaby-9wldd .*`+regexp.QuoteMeta(`cat > '/etc/kubernetes/kubelet.yaml'`), tshtest.Response{
+			Stdout: "backup:/etc/kubernetes/kubelet.yaml.bak.20261016T120000Z\nsha256:" + hash + "  /etc/kubernetes/kubelet.yaml\nsize:13\n",
+		}).
+		On(`^tsh ssh root@wallaby-9wldd .*`+regexp.QuoteMeta(`cat > '/etc/motd'`), tshtest.Response{
+			Stdout: "sha256:0000  /etc/motd\nsize:0\n",
+		})
+
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithRunner(runner),
+		server.WithNonDestructiveMode(false),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	result, err := handleFileWrite(context.Background(), createTestRequest(map[string]interface{}{
+		"destination": "root@wallaby-9wldd",
+		"path":        "/etc/kubernetes/kubelet.yaml",
+		"content":     content,
+		"backup":      true,
+	}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleFileWrite() failed: %v %+v", err, result)
+	}
+	written := result.StructuredContent.(WrittenFile)
+	if !written.Verified || written.SHA256 != hash || written.SizeBytes != 13 || written.BackupPath != "/etc/kubernetes/kubelet.yaml.bak.20261016T120000Z" {
+		t.Errorf("Unexpected result: %+v", written)
+	}
+	if inputs := runner.Inputs(); len(inputs) != 1 || inputs[0] != content {
+		t.Errorf("Expected the content on stdin, got %q", inputs)
+	}
+	if command := strings.Join(runner.Calls()[0], " "); !strings.Contains(command, "cp -p '/etc/kubernetes/kubelet.yaml' '/etc/kubernetes/kubelet.yaml.bak.") {
+		t.Errorf("Expected a backup before writing, got: %s", command)
+	}
+
+	// A checksum mismatch fails the call
+	result, err = handleFileWrite(context.Background(), createTestRequest(map[string]interface{}{
+		"destination": "root@wallaby-9wldd",
+		"path":        "/etc/motd",
+		"content":     "aGVsbG8K",
+		"encoding":    "base64",
+	}), sc)
+	if err != nil {
+		t.Fatalf("Expected no error from handler, got: %v", err)
+	}
+	if !result.IsError || !strings.Contains(extractTextFromContent(result.Content[0]), "Verification of /etc/motd on root@wallaby-9wldd failed") {
+		t.Errorf("Expected a verification error, got: %+v", result)
+	}
+	if inputs := runner.Inputs(); inputs[1] != "hello\n" {
+		t.Errorf("Expected the decoded content on stdin, got %q", inputs[1])
+	}
+}
+
+func TestHandleFileWriteNonDestructive(t *testing.T) {
+	runner := tshtest.NewRunner()
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithRunner(runner),
+		server.WithNonDestructiveMode(true),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	result, err := handleFileWrite(context.Background(), createTestRequest(map[string]interface{}{
+		"destination": "root@wallaby-9wldd",
+		"path":        "/etc/motd",
+		"content":     "hello\n",
+	}), sc)
+	if err != nil {
+		t.Fatalf("Expected no error from handler, got: %v", err)
+	}
+	if !result.IsError || !strings.Contains(extractTextFromContent(result.Content[0]), "non-destructive") {
+		t.Errorf("Expected the write to be refused, got: %+v", result)
+	}
+	if len(runner.Calls()) != 0 {
+		t.Errorf("Expected no tsh calls, got %v", runner.Calls())
+	}
+}
+
+func TestHandleDirList(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh ssh root@wallaby-9wldd .*find '/etc/kubernetes' .*`+regexp.QuoteMeta(`| head -n 3`)+`$`, tshtest.Response{
+			Stdout: "f\t13\t644\troot\troot\t1760616000.5000000000\t\tkubelet.yaml\n" +
+				"d\t4096\t755\troot\troot\t1760529600.0000000000\t\tmanifests\n" +
+				"l\t22\t777\troot\troot\t1760529600.0000000000\t/etc/kubernetes/pki.d\tpki\n",
+		})
+
+	sc, err := server.NewServerContext(context.Background(), server.WithRunner(runner))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	result, err := handleDirList(context.Background(), createTestRequest(map[string]interface{}{
+		"destination": "root@wallaby-9wldd",
+		"path":        "/etc/kubernetes",
+		"maxEntries":  float64(2),
+	}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleDirList() failed: %v %+v", err, result)
+	}
+
+	listing := result.StructuredContent.(DirectoryListing)
+	if !listing.Truncated || len(listing.Entries) != 2 {
+		t.Fatalf("Expected 2 entries and a truncated listing, got %+v", listing)
+	}
+	expected := DirectoryEntry{
+		Name: "kubelet.yaml", Type: "file", SizeBytes: 13, Mode: "644", Owner: "root", Group: "root",
+		Modified: time.Date(2025, 10, 16, 12, 0, 0, 0, time.UTC),
+	}
+	if listing.Entries[0] != expected {
+		t.Errorf("Got %+v, want %+v", listing.Entries[0], expected)
+	}
+	if listing.Entries[1].Name != "manifests" || listing.Entries[1].Type != "directory" {
+		t.Errorf("Unexpected entry: %+v", listing.Entries[1])
+	}
+	if text := extractTextFromContent(result.Content[0]); !strings.Contains(text, "manifests/") || !strings.Contains(text, "listing truncated") {
+		t.Errorf("Unexpected result: %s", text)
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"slices"
 	"sort"
 	"strings"
 
+	"github.com/giantswarm/mcp-teleport/internal/policy"
 	"github.com/giantswarm/mcp-teleport/internal/server"
 	"github.com/giantswarm/mcp-teleport/internal/teleport"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -15,7 +18,7 @@ import (
 // handleListSSHNodes handles the teleport_list_ssh_nodes tool
 func handleListSSHNodes(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
 	// Create teleport client
-	client := teleport.NewClient(sc.IsDryRun(), sc.IsDebugMode())
+	client := sc.TeleportClient()
 
 	// Extract parameters
 	params := make(map[string]interface{})
@@ -62,7 +65,7 @@ func handleListSSHNodes(ctx context.Context, request mcp.CallToolRequest, sc *se
 	}
 
 	// Execute ls command
-	result := client.ExecuteCommand("ls", args)
+	result := client.ExecuteCommandContext(ctx, "ls", args)
 
 	// Build MCP response
 	var content []mcp.Content
@@ -78,29 +81,33 @@ func handleListSSHNodes(ctx context.Context, request mcp.CallToolRequest, sc *se
 	}
 
 	// Parse JSON output and format for user
-	formattedOutput, err := formatSSHNodesOutput(result.Output)
+	formattedOutput, err := formatSSHNodesOutput(result.Stdout)
 	if err != nil {
 		// If JSON parsing fails, return raw output
 		content = append(content, mcp.TextContent{
 			Type: "text",
 			Text: result.Output,
 		})
-	} else {
-		content = append(content, mcp.TextContent{
-			Type: "text",
-			Text: formattedOutput,
-		})
+		return &mcp.CallToolResult{
+			Content: content,
+		}, nil
 	}
 
-	return &mcp.CallToolResult{
+	content = append(content, mcp.TextContent{
+		Type: "text",
+		Text: formattedOutput,
+	})
+
+	// Surface tsh warnings that were kept out of the parsed output
+	return server.WithDiagnostics(&mcp.CallToolResult{
 		Content: content,
-	}, nil
+	}, result), nil
 }
 
 // handleSSH handles the teleport_ssh tool
 func handleSSH(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
 	// Create teleport client
-	client := teleport.NewClient(sc.IsDryRun(), sc.IsDebugMode())
+	client := sc.TeleportClient()
 
 	// Extract parameters
 	params := make(map[string]interface{})
@@ -141,7 +148,133 @@ func handleSSH(ctx context.Context, request mcp.CallToolRequest, sc *server.Serv
 		}, nil
 	}
 
+	// In non-destructive mode only commands covered by the read-only policy may
+	// run, and nothing may run or listen on the MCP host
+	if sc.IsNonDestructiveMode() {
+		if err := checkSSHParams(params); err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: "text",
+						Text: fmt.Sprintf("Error: %v is not allowed in non-destructive mode; restart the server with --non-destructive=false to use it.", err),
+					},
+				},
+				IsError: true,
+			}, nil
+		}
+		if err := policy.CheckReadOnly(command); err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: "text",
+						Text: fmt.Sprintf("Error: Command rejected in non-destructive mode: %v. Only read-only commands are allowed; restart the server with --non-destructive=false to run arbitrary commands.", err),
+					},
+				},
+				IsError: true,
+			}, nil
+		}
+	}
+
+	// Commands on a named host must pass the SSH policy before they run;
+	// label selectors are checked per node once the nodes are known
+	var decision *policy.SSHDecision
+	if !isLabelSelector(destination) {
+		decision = checkSSHPolicy(ctx, sc, client, params, destination, command)
+		if decision != nil && !decision.Allowed {
+			return sshPolicyError(fmt.Sprintf("Error: Command %s. Use teleport_ssh_policy_explain to see how each rule was evaluated.", decision)), nil
+		}
+	}
+
 	// Build SSH arguments
+	args := sshOptions(params)
+	background, _ := params["background"].(bool)
+
+	// Label selectors run the command on every matching node
+	if isLabelSelector(destination) {
+		if background {
+			return jobError("Error: Background jobs are not supported with label selectors. Run the command on each node with its own job instead."), nil
+		}
+		return handleSSHFanOut(ctx, sc, client, params, destination, command, args)
+	}
+
+	// Add destination and command
+	args = append(args, destination, command)
+
+	// Long-running commands can run as a background job instead
+	if background {
+		return startJob(ctx, sc, request, "ssh", args), nil
+	}
+
+	// Execute SSH command, streaming stdout lines as progress if the client asked for it
+	result := client.StreamCommandContext(ctx, "ssh", args, server.ProgressWriter(ctx, request), nil)
+
+	// Large output is truncated; the rest can be read with teleport_output_read
+	output := sc.LimitOutput(ctx, result.Output)
+
+	// Build MCP response
+	var content []mcp.Content
+	if !result.Success {
+		content = append(content, mcp.TextContent{
+			Type: "text",
+			Text: fmt.Sprintf("Error: %s\n%s", result.ErrorMessage, output.Text),
+		})
+		return &mcp.CallToolResult{
+			Content: content,
+			IsError: true,
+		}, nil
+	}
+
+	content = append(content, mcp.TextContent{
+		Type: "text",
+		Text: output.Text,
+	})
+	if decision != nil {
+		content = append(content, mcp.TextContent{
+			Type: "text",
+			Text: fmt.Sprintf("Command %s", decision),
+		})
+	}
+
+	return &mcp.CallToolResult{
+		Content: content,
+	}, nil
+}
+
+// sshCommonParams are the declared parameters of the SSH tools that
+// FormatArgs turns into tsh flags
+var sshCommonParams = []string{"loginParam", "proxyParam", "userParam", "ttlParam", "identityParam", "insecureParam", "debugParam"}
+
+// sshLocalParams are teleport_ssh parameters that run commands, listen or
+// write files on the MCP host, or change how tsh connects
+var sshLocalParams = []string{"localCommand", "openSSHOptions", "localForward", "remoteForward", "dynamicForward", "logDir"}
+
+// checkSSHParams returns an error naming the first parameter in
+// sshLocalParams, or the flags of parameters teleport_ssh does not declare,
+// which FormatArgs would otherwise pass to tsh
+func checkSSHParams(params map[string]interface{}) error {
+	for _, name := range sshLocalParams {
+		if value, _ := params[name].(string); value != "" {
+			return fmt.Errorf("parameter %s", name)
+		}
+	}
+
+	undeclared := make(map[string]interface{})
+	for name, value := range params {
+		if !slices.Contains(sshCommonParams, name) {
+			undeclared[name] = value
+		}
+	}
+	if flags := teleport.FormatArgs(undeclared); len(flags) > 0 {
+		sort.Strings(flags)
+		return fmt.Errorf("passing %s to tsh", strings.Join(flags, " "))
+	}
+	return nil
+}
+
+// sshOptions builds the tsh ssh arguments that precede the destination.
+// tsh passes everything after the command to the remote host, so all flags
+// must come first.
+func sshOptions(params map[string]interface{}) []string {
 	var args []string
 
 	// Add common parameters (proxy, user, etc.)
@@ -189,14 +322,6 @@ func handleSSH(ctx context.Context, request mcp.CallToolRequest, sc *server.Serv
 		}
 	}
 
-	// Add destination
-	args = append(args, destination)
-
-	// Add command if provided
-	if command != "" {
-		args = append(args, command)
-	}
-
 	// Handle existing SSH-specific parameters
 	if port, ok := params["port"].(float64); ok {
 		args = append(args, fmt.Sprintf("--port=%d", int(port)))
@@ -210,36 +335,26 @@ func handleSSH(ctx context.Context, request mcp.CallToolRequest, sc *server.Serv
 		args = append(args, "--forward-agent")
 	}
 
-	// Execute SSH command
-	result := client.ExecuteCommand("ssh", args)
+	return args
+}
 
-	// Build MCP response
-	var content []mcp.Content
-	if !result.Success {
-		content = append(content, mcp.TextContent{
-			Type: "text",
-			Text: fmt.Sprintf("Error: %s\n%s", result.ErrorMessage, result.Output),
-		})
+// handleSCP handles the teleport_scp tool
+func handleSCP(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// File transfers can overwrite files on either side
+	if err := sc.CheckMutation("teleport_scp"); err != nil {
 		return &mcp.CallToolResult{
-			Content: content,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
 			IsError: true,
 		}, nil
 	}
 
-	content = append(content, mcp.TextContent{
-		Type: "text",
-		Text: result.Output,
-	})
-
-	return &mcp.CallToolResult{
-		Content: content,
-	}, nil
-}
-
-// handleSCP handles the teleport_scp tool
-func handleSCP(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
 	// Create teleport client
-	client := teleport.NewClient(sc.IsDryRun(), sc.IsDebugMode())
+	client := sc.TeleportClient()
 
 	// Extract parameters
 	params := make(map[string]interface{})
@@ -276,6 +391,27 @@ func handleSCP(ctx context.Context, request mcp.CallToolRequest, sc *server.Serv
 		}, nil
 	}
 
+	// Check both ends against the path policy before anything runs, so that
+	// dry runs and background jobs are refused the same way
+	recursive, _ := params["recursive"].(bool)
+	from, to, err := checkTransfer(sc.SCPPolicy(), source, destination, recursive)
+	if err != nil {
+		return scpError(fmt.Sprintf("Error: %v", err)), nil
+	}
+
+	// The remote side of a transfer runs scp on the node, which the SSH
+	// policy must allow
+	preserveAttributes, _ := params["preserveAttributes"].(bool)
+	for i, endpoint := range []scpEndpoint{from, to} {
+		if !endpoint.remote() {
+			continue
+		}
+		command := remoteSCPCommand(endpoint, i == 1, recursive, preserveAttributes)
+		if decision := checkSSHPolicy(ctx, sc, client, params, endpoint.Host, command); decision != nil && !decision.Allowed {
+			return scpError(fmt.Sprintf("Error: Transfer refused: %s on %s %s. Use teleport_ssh_policy_explain to see how each rule was evaluated.", command, endpoint.Host, decision)), nil
+		}
+	}
+
 	// Build SCP arguments
 	var args []string
 
@@ -284,11 +420,11 @@ func handleSCP(ctx context.Context, request mcp.CallToolRequest, sc *server.Serv
 	args = append(args, commonArgs...)
 
 	// Handle SCP-specific parameters
-	if recursive, ok := params["recursive"].(bool); ok && recursive {
+	if recursive {
 		args = append(args, "-r")
 	}
 
-	if preserveAttributes, ok := params["preserveAttributes"].(bool); ok && preserveAttributes {
+	if preserveAttributes {
 		args = append(args, "-p")
 	}
 
@@ -307,15 +443,30 @@ func handleSCP(ctx context.Context, request mcp.CallToolRequest, sc *server.Serv
 	// Add source and destination
 	args = append(args, source, destination)
 
-	// Execute SCP command
-	result := client.ExecuteCommand("scp", args)
+	// Large transfers can run as a background job instead; the transfer is
+	// verified when the job finishes
+	if background, _ := params["background"].(bool); background {
+		return startJobFunc(ctx, sc, request, "tsh scp "+strings.Join(args, " "), func(ctx context.Context, output io.Writer) *teleport.ExecutionResult {
+			result := client.StreamCommandContext(ctx, "scp", args, output, output)
+			if result.Success && !recursive && !sc.IsDryRun() {
+				verifyJobTransfer(ctx, sc, client, params, from, to, result, output)
+			}
+			return result
+		}), nil
+	}
+
+	// Execute SCP command, streaming stdout lines as progress if the client asked for it
+	result := client.StreamCommandContext(ctx, "scp", args, server.ProgressWriter(ctx, request), nil)
+
+	// Large output is truncated; the rest can be read with teleport_output_read
+	output := sc.LimitOutput(ctx, result.Output)
 
 	// Build MCP response
 	var content []mcp.Content
 	if !result.Success {
 		content = append(content, mcp.TextContent{
 			Type: "text",
-			Text: fmt.Sprintf("Error: %s\n%s", result.ErrorMessage, result.Output),
+			Text: fmt.Sprintf("Error: %s\n%s", result.ErrorMessage, output.Text),
 		})
 		return &mcp.CallToolResult{
 			Content: content,
@@ -323,20 +474,36 @@ func handleSCP(ctx context.Context, request mcp.CallToolRequest, sc *server.Serv
 		}, nil
 	}
 
-	content = append(content, mcp.TextContent{
-		Type: "text",
-		Text: fmt.Sprintf("File transfer completed successfully\n%s", result.Output),
-	})
+	if sc.IsDryRun() {
+		content = append(content, mcp.TextContent{
+			Type: "text",
+			Text: fmt.Sprintf("File transfer completed successfully\n%s", output.Text),
+		})
+		return &mcp.CallToolResult{
+			Content: content,
+		}, nil
+	}
 
-	return &mcp.CallToolResult{
-		Content: content,
-	}, nil
+	// Compare the size and SHA-256 of the file on both sides
+	var transfer Transfer
+	var verification *teleport.ExecutionResult
+	if recursive {
+		transfer.Direction = transferDirection(from, to)
+		transfer.Note = "recursive transfers are not verified"
+	} else {
+		transfer, verification = verifyTransfer(ctx, sc, client, params, from, to)
+	}
+	transfer.Source = source
+	transfer.Destination = destination
+	transfer.Output = output.Text
+	transfer.Handle = output.Handle
+	return transferResult(transfer, verification), nil
 }
 
 // handleResolve handles the teleport_resolve tool
 func handleResolve(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
 	// Create teleport client
-	client := teleport.NewClient(sc.IsDryRun(), sc.IsDebugMode())
+	client := sc.TeleportClient()
 
 	// Extract parameters
 	params := make(map[string]interface{})
@@ -379,7 +546,7 @@ func handleResolve(ctx context.Context, request mcp.CallToolRequest, sc *server.
 	args = append(args, host)
 
 	// Execute resolve command
-	result := client.ExecuteCommand("resolve", args)
+	result := client.ExecuteCommandContext(ctx, "resolve", args)
 
 	// Build MCP response
 	var content []mcp.Content
@@ -395,23 +562,27 @@ func handleResolve(ctx context.Context, request mcp.CallToolRequest, sc *server.
 	}
 
 	// Parse JSON output and format for user
-	formattedOutput, err := formatResolveOutput(result.Output)
+	formattedOutput, err := formatResolveOutput(result.Stdout)
 	if err != nil {
 		// If JSON parsing fails, return raw output
 		content = append(content, mcp.TextContent{
 			Type: "text",
 			Text: result.Output,
 		})
-	} else {
-		content = append(content, mcp.TextContent{
-			Type: "text",
-			Text: formattedOutput,
-		})
+		return &mcp.CallToolResult{
+			Content: content,
+		}, nil
 	}
 
-	return &mcp.CallToolResult{
+	content = append(content, mcp.TextContent{
+		Type: "text",
+		Text: formattedOutput,
+	})
+
+	// Surface tsh warnings that were kept out of the parsed output
+	return server.WithDiagnostics(&mcp.CallToolResult{
 		Content: content,
-	}, nil
+	}, result), nil
 }
 
 // formatSSHNodesOutput formats JSON output from tsh ls command
@@ -2,11 +2,14 @@ package ssh
 
 import (
 	"context"
+	"encoding/json"
 	"strings"
 	"testing"
 
 	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport/tshtest"
 	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
 )
 
 // TestDryRunCommandGeneration tests that our handlers generate the correct tsh commands
@@ -406,3 +409,328 @@ func TestSSHRequiresCommand(t *testing.T) {
 		t.Errorf("Expected error message to contain %q, got: %q", expectedMsg, errorText)
 	}
 }
+
+// TestNonDestructiveMode tests that mutating operations are refused in non-destructive mode
+func TestNonDestructiveMode(t *testing.T) {
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithDryRun(true),
+		server.WithNonDestructiveMode(true),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	tests := []struct {
+		name      string
+		handler   func(context.Context, mcp.CallToolRequest, *server.ServerContext) (*mcp.CallToolResult, error)
+		params    map[string]interface{}
+		wantError bool
+	}{
+		{
+			name:    "read-only ssh command is allowed",
+			handler: handleSSH,
+			params: map[string]interface{}{
+				"destination": "root@web-server",
+				"command":     "df -h | head -n 5",
+			},
+			wantError: false,
+		},
+		{
+			name:    "mutating ssh command is rejected",
+			handler: handleSSH,
+			params: map[string]interface{}{
+				"destination": "root@web-server",
+				"command":     "systemctl restart kubelet",
+			},
+			wantError: true,
+		},
+		{
+			name:    "output redirection is rejected",
+			handler: handleSSH,
+			params: map[string]interface{}{
+				"destination": "root@web-server",
+				"command":     "echo hi > /etc/motd",
+			},
+			wantError: true,
+		},
+		{
+			name:    "local command is rejected",
+			handler: handleSSH,
+			params: map[string]interface{}{
+				"destination":  "root@web-server",
+				"command":      "uptime",
+				"localCommand": "rm -rf ~",
+			},
+			wantError: true,
+		},
+		{
+			name:    "OpenSSH options are rejected",
+			handler: handleSSH,
+			params: map[string]interface{}{
+				"destination":    "root@web-server",
+				"command":        "uptime",
+				"openSSHOptions": "ProxyCommand=sh -c 'touch /tmp/x'",
+			},
+			wantError: true,
+		},
+		{
+			name:    "port forwarding is rejected",
+			handler: handleSSH,
+			params: map[string]interface{}{
+				"destination":   "root@web-server",
+				"command":       "uptime",
+				"remoteForward": "8080:localhost:22",
+			},
+			wantError: true,
+		},
+		{
+			name:    "undeclared tsh flags are rejected",
+			handler: handleSSH,
+			params: map[string]interface{}{
+				"destination": "root@web-server",
+				"command":     "uptime",
+				"localParam":  "rm -rf ~",
+			},
+			wantError: true,
+		},
+		{
+			name:    "scp is rejected",
+			handler: handleSCP,
+			params: map[string]interface{}{
+				"source":      "/local/file.txt",
+				"destination": "server:~/file.txt",
+			},
+			wantError: true,
+		},
+		{
+			name:    "resolve is allowed",
+			handler: handleResolve,
+			params: map[string]interface{}{
+				"host": "web-server",
+			},
+			wantError: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.handler(context.Background(), createTestRequest(tt.params), sc)
+			if err != nil {
+				t.Fatalf("Expected no error from handler, got: %v", err)
+			}
+			if result.IsError != tt.wantError {
+				t.Errorf("Expected IsError=%v, got %v: %s", tt.wantError, result.IsError, extractTextFromContent(result.Content[0]))
+			}
+			if tt.wantError && !strings.Contains(extractTextFromContent(result.Content[0]), "non-destructive mode") {
+				t.Errorf("Expected error to mention non-destructive mode, got: %s", extractTextFromContent(result.Content[0]))
+			}
+		})
+	}
+}
+
+// TestHandlersWithFakeTsh tests handler parsing and error paths against canned tsh output
+func TestHandlersWithFakeTsh(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh ls .*--format json`, tshtest.Response{Stdout: tshtest.Fixture(t, "testdata/tsh_ls.json")}).
+		On(`^tsh resolve .*wallaby-9wldd$`, tshtest.Response{Stdout: tshtest.Fixture(t, "testdata/tsh_resolve.json")}).
+		On(`^tsh resolve `, tshtest.Response{Stderr: "ERROR: no matching SSH hosts found\n", ExitCode: 1}).
+		On(`^tsh ssh root@wallaby-9wldd uptime$`, tshtest.Response{Stdout: " 07:15:15 up 92 days, 18:59,  0 user,  load average: 1.71, 1.55, 1.47\n"}).
+		On(`^tsh ssh `, tshtest.Response{Stderr: "ERROR: access denied to root connecting to wallaby-9wldd\n", ExitCode: 1})
+
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithRunner(runner),
+		server.WithNonDestructiveMode(true),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	tests := []struct {
+		name      string
+		handler   func(context.Context, mcp.CallToolRequest, *server.ServerContext) (*mcp.CallToolResult, error)
+		params    map[string]interface{}
+		wantError bool
+		contains  []string
+	}{
+		{
+			name:     "list nodes parses tsh ls output",
+			handler:  handleListSSHNodes,
+			params:   map[string]interface{}{"labels": "cluster=wallaby"},
+			contains: []string{"Found 2 SSH node(s)", "• wallaby-9wldd [41c3ee63-af98-44b1-9ec6-14cb19ba7e6b]", "arch=x86_64", "• wallaby-worker-7x2kq (10.0.4.17:3022)"},
+		},
+		{
+			name:     "resolve parses tsh resolve output",
+			handler:  handleResolve,
+			params:   map[string]interface{}{"host": "wallaby-9wldd"},
+			contains: []string{"Host resolution for: wallaby-9wldd", "Node ID: 41c3ee63-af98-44b1-9ec6-14cb19ba7e6b", "role=control-plane"},
+		},
+		{
+			name:      "resolve reports tsh errors",
+			handler:   handleResolve,
+			params:    map[string]interface{}{"host": "unknown"},
+			wantError: true,
+			contains:  []string{"exit status 1", "no matching SSH hosts found"},
+		},
+		{
+			name:     "ssh returns command output",
+			handler:  handleSSH,
+			params:   map[string]interface{}{"destination": "root@wallaby-9wldd", "command": "uptime"},
+			contains: []string{"load average"},
+		},
+		{
+			name:      "ssh reports access errors",
+			handler:   handleSSH,
+			params:    map[string]interface{}{"destination": "root@wallaby-9wldd", "command": "hostname"},
+			wantError: true,
+			contains:  []string{"access denied"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.handler(context.Background(), createTestRequest(tt.params), sc)
+			if err != nil {
+				t.Fatalf("Expected no error from handler, got: %v", err)
+			}
+			text := extractTextFromContent(result.Content[0])
+			if result.IsError != tt.wantError {
+				t.Errorf("Expected IsError=%v, got %v: %s", tt.wantError, result.IsError, text)
+			}
+			for _, expected := range tt.contains {
+				if !strings.Contains(text, expected) {
+					t.Errorf("Expected %q in result, got: %s", expected, text)
+				}
+			}
+		})
+	}
+}
+
+// TestListSSHNodesWithMFAPrompt checks that prompts tsh prints on stderr do
+// not break JSON parsing and are returned as diagnostics
+func TestListSSHNodesWithMFAPrompt(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh ls .*--format json`, tshtest.Response{
+			Stdout: tshtest.Fixture(t, "testdata/tsh_ls.json"),
+			Stderr: "Tap any security key\nDetected security key tap\n",
+		})
+
+	sc, err := server.NewServerContext(context.Background(), server.WithRunner(runner))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	result, err := handleListSSHNodes(context.Background(), createTestRequest(map[string]interface{}{}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleListSSHNodes() failed: %v %+v", err, result)
+	}
+	if text := extractTextFromContent(result.Content[0]); !strings.Contains(text, "Found 2 SSH node(s)") {
+		t.Errorf("Expected parsed node list, got: %s", text)
+	}
+	if len(result.Content) != 2 || !strings.Contains(extractTextFromContent(result.Content[1]), "Tap any security key") {
+		t.Errorf("Expected the MFA prompt as diagnostics, got: %+v", result.Content)
+	}
+}
+
+// testSession is a client session that collects notifications
+type testSession struct {
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (s *testSession) Initialize()       {}
+func (s *testSession) Initialized() bool { return true }
+func (s *testSession) SessionID() string { return "test-session" }
+func (s *testSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+// TestSSHProgressNotifications checks that stdout lines are sent as progress
+// notifications when the client supplies a progress token, and that the
+// result still holds the full output
+func TestSSHProgressNotifications(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh ssh root@wallaby-9wldd apt-get upgrade -y$`, tshtest.Response{
+			Stdout: "Reading package lists...\nUnpacking curl\n",
+			Stderr: "WARNING: slow link\n",
+		})
+
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithRunner(runner),
+		server.WithNonDestructiveMode(false),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	srv := mcpserver.NewMCPServer("test", "1.0.0", mcpserver.WithToolCapabilities(true))
+	if err := RegisterSSHTools(srv, sc); err != nil {
+		t.Fatalf("Failed to register tools: %v", err)
+	}
+
+	session := &testSession{notifications: make(chan mcp.JSONRPCNotification, 10)}
+	ctx := srv.WithContext(context.Background(), session)
+	message := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"teleport_ssh",` +
+		`"arguments":{"destination":"root@wallaby-9wldd","command":"apt-get upgrade -y"},"_meta":{"progressToken":"upgrade"}}}`
+	response, ok := srv.HandleMessage(ctx, json.RawMessage(message)).(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatalf("Expected a response, got %+v", response)
+	}
+	result := response.Result.(*mcp.CallToolResult)
+	if text := extractTextFromContent(result.Content[0]); text != "Reading package lists...\nUnpacking curl\nWARNING: slow link\n" {
+		t.Errorf("Expected the full output in the result, got: %q", text)
+	}
+
+	close(session.notifications)
+	var messages []string
+	for notification := range session.notifications {
+		if notification.Params.AdditionalFields["progressToken"] != "upgrade" {
+			t.Errorf("Unexpected notification: %+v", notification)
+		}
+		messages = append(messages, notification.Params.AdditionalFields["message"].(string))
+	}
+	if strings.Join(messages, "|") != "Reading package lists...|Unpacking curl" {
+		t.Errorf("Expected stdout lines as progress, got %q", messages)
+	}
+}
+
+// TestSSHOutputLimit checks that large output is truncated in the result and
+// can be read in full through the returned handle
+func TestSSHOutputLimit(t *testing.T) {
+	output := "BEGIN\n" + strings.Repeat("x", 100) + "\nEND\n"
+	runner := tshtest.NewRunner().
+		On(`^tsh ssh root@wallaby-9wldd cat /var/log/syslog$`, tshtest.Response{Stdout: output})
+
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithRunner(runner),
+		server.WithOutputLimit(20),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	result, err := handleSSH(context.Background(), createTestRequest(map[string]interface{}{
+		"destination": "root@wallaby-9wldd",
+		"command":     "cat /var/log/syslog",
+	}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleSSH() failed: %v %+v", err, result)
+	}
+	text := extractTextFromContent(result.Content[0])
+	for _, expected := range []string{"BEGIN\n", "\nEND\n", "bytes omitted; read them with teleport_output_read handle=out-1"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in result, got: %q", expected, text)
+		}
+	}
+
+	chunk, err := sc.ReadOutput(context.Background(), "out-1", 0, 0)
+	if err != nil {
+		t.Fatalf("ReadOutput() failed: %v", err)
+	}
+	if chunk.Output != output {
+		t.Errorf("Expected the full output behind the handle, got: %q", chunk.Output)
+	}
+}
@@ -0,0 +1,53 @@
+package ssh
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// startJob runs tsh command with args as a background job and returns the job
+// so the client can poll it with teleport_job_status
+func startJob(ctx context.Context, sc *server.ServerContext, request mcp.CallToolRequest, command string, args []string) *mcp.CallToolResult {
+	client := sc.TeleportClient()
+	return startJobFunc(ctx, sc, request, fmt.Sprintf("tsh %s %s", command, strings.Join(args, " ")), func(ctx context.Context, output io.Writer) *teleport.ExecutionResult {
+		return client.StreamCommandContext(ctx, command, args, output, output)
+	})
+}
+
+// startJobFunc runs fn as a background job described by commandLine, for
+// jobs that do more than run a single tsh command
+func startJobFunc(ctx context.Context, sc *server.ServerContext, request mcp.CallToolRequest, commandLine string, fn server.JobFunc) *mcp.CallToolResult {
+	// The job outlives the call, so the call's timeout does not apply to it
+	timeout, err := sc.JobTimeout(request.Params.Arguments)
+	if err != nil {
+		return jobError(fmt.Sprintf("Error: Invalid timeout: %v", err))
+	}
+
+	job, err := sc.Jobs().Start(ctx, request.Params.Name, commandLine, timeout, fn)
+	if err != nil {
+		return jobError(fmt.Sprintf("Error: Failed to start background job: %v", err))
+	}
+
+	info := job.Info()
+	return mcp.NewToolResultStructured(info, fmt.Sprintf("Started background job %s: %s\nIt may run for up to %s. Poll its status and output with teleport_job_status and stop it with teleport_job_cancel.",
+		info.ID, info.Command, timeout))
+}
+
+// jobError builds an error result for a background job request
+func jobError(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+		IsError: true,
+	}
+}
@@ -0,0 +1,107 @@
+package ssh
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport/tshtest"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestBackgroundJobs(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh ssh root@wallaby-9wldd journalctl -u kubelet$`, tshtest.Response{Stdout: "kubelet started\n"}).
+		On(`^tsh scp -r ./logs root@wallaby-9wldd:/tmp/logs$`, tshtest.Response{Stderr: "WARNING: slow link\n"})
+
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithRunner(runner),
+		server.WithNonDestructiveMode(false),
+		server.WithJobTimeout(time.Minute),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	tests := []struct {
+		name    string
+		handler func(context.Context, mcp.CallToolRequest, *server.ServerContext) (*mcp.CallToolResult, error)
+		params  map[string]interface{}
+		command string
+		output  string
+	}{
+		{
+			name:    "ssh",
+			handler: handleSSH,
+			params:  map[string]interface{}{"destination": "root@wallaby-9wldd", "command": "journalctl -u kubelet", "background": true},
+			command: "tsh ssh root@wallaby-9wldd journalctl -u kubelet",
+			output:  "kubelet started\n",
+		},
+		{
+			name:    "scp",
+			handler: handleSCP,
+			params:  map[string]interface{}{"source": "./logs", "destination": "root@wallaby-9wldd:/tmp/logs", "recursive": true, "background": true},
+			command: "tsh scp -r ./logs root@wallaby-9wldd:/tmp/logs",
+			output:  "WARNING: slow link\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.handler(context.Background(), createTestRequest(tt.params), sc)
+			if err != nil || result.IsError {
+				t.Fatalf("Handler failed: %v %+v", err, result)
+			}
+
+			info, ok := result.StructuredContent.(server.JobInfo)
+			if !ok {
+				t.Fatalf("Expected JobInfo as structured content, got %T", result.StructuredContent)
+			}
+			if info.Command != tt.command {
+				t.Errorf("Command = %q, want %q", info.Command, tt.command)
+			}
+			if text := extractTextFromContent(result.Content[0]); !strings.Contains(text, "Started background job "+info.ID) {
+				t.Errorf("Expected the job ID in the result, got: %s", text)
+			}
+
+			job, err := sc.Jobs().Get(context.Background(), info.ID)
+			if err != nil {
+				t.Fatalf("Job not found: %v", err)
+			}
+			select {
+			case <-job.Done():
+			case <-time.After(5 * time.Second):
+				t.Fatal("Job did not finish")
+			}
+			if state := job.Info().State; state != server.JobSucceeded {
+				t.Errorf("State = %q, want %q", state, server.JobSucceeded)
+			}
+			if output := job.Output(0, 0).Output; output != tt.output {
+				t.Errorf("Output = %q, want %q", output, tt.output)
+			}
+		})
+	}
+}
+
+func TestBackgroundJobWithLabelSelector(t *testing.T) {
+	sc, err := server.NewServerContext(context.Background(), server.WithRunner(tshtest.NewRunner()))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	result, err := handleSSH(context.Background(), createTestRequest(map[string]interface{}{
+		"destination": "root@env=prod",
+		"command":     "uptime",
+		"background":  true,
+	}), sc)
+	if err != nil {
+		t.Fatalf("Expected no error from handler, got: %v", err)
+	}
+	if !result.IsError || !strings.Contains(extractTextFromContent(result.Content[0]), "not supported with label selectors") {
+		t.Errorf("Expected background label-selector calls to be rejected, got: %+v", result)
+	}
+}
@@ -0,0 +1,305 @@
+package ssh
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// sessionIDPattern matches Teleport session IDs and keeps flags out of tsh play
+var sessionIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// escapeSequencePattern matches terminal escape sequences (CSI, OSC,
+// character set selection and two-byte escapes) and bells in a session
+// transcript
+var escapeSequencePattern = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)|\x1b[()][0-9A-Za-z]|\x1b[=>@-_]|\x07`)
+
+// recordingJSON represents a session end event from tsh recordings ls JSON output
+type recordingJSON struct {
+	SessionID         string    `json:"sid"`
+	Cluster           string    `json:"cluster_name"`
+	User              string    `json:"user"`
+	Login             string    `json:"login"`
+	ServerHostname    string    `json:"server_hostname"`
+	ServerID          string    `json:"server_id"`
+	KubernetesCluster string    `json:"kubernetes_cluster"`
+	DatabaseService   string    `json:"db_service"`
+	DesktopName       string    `json:"desktop_name"`
+	Participants      []string  `json:"participants"`
+	Interactive       bool      `json:"interactive"`
+	InitialCommand    []string  `json:"initial_command"`
+	SessionStart      time.Time `json:"session_start"`
+	SessionStop       time.Time `json:"session_stop"`
+	Time              time.Time `json:"time"`
+}
+
+// Recording is a recorded session as returned by teleport_recordings_list
+type Recording struct {
+	SessionID string `json:"sessionId"`
+	// Kind is ssh, kube, db or desktop
+	Kind    string `json:"kind"`
+	Cluster string `json:"cluster,omitempty"`
+	User    string `json:"user"`
+	Login   string `json:"login,omitempty"`
+	// Target is the node hostname, Kubernetes cluster, database or desktop
+	Target       string    `json:"target"`
+	Participants []string  `json:"participants,omitempty"`
+	Interactive  bool      `json:"interactive"`
+	Command      string    `json:"command,omitempty"`
+	Start        time.Time `json:"start"`
+	End          time.Time `json:"end"`
+	DurationMs   int64     `json:"durationMs"`
+}
+
+// RecordingList is the result of teleport_recordings_list
+type RecordingList struct {
+	From       time.Time   `json:"from"`
+	To         time.Time   `json:"to"`
+	Recordings []Recording `json:"recordings"`
+}
+
+// handleRecordingsList handles the teleport_recordings_list tool
+func handleRecordingsList(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Create teleport client
+	client := sc.TeleportClient()
+
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	// tsh lists the last 24 hours by default
+	to := time.Now().UTC()
+	if value, ok := params["to"].(string); ok && value != "" {
+		parsed, err := parseTime(value)
+		if err != nil {
+			return recordingError(fmt.Sprintf("Error: Invalid to: %v", err)), nil
+		}
+		to = parsed
+	}
+	from := to.Add(-24 * time.Hour)
+	if value, ok := params["from"].(string); ok && value != "" {
+		parsed, err := parseTime(value)
+		if err != nil {
+			return recordingError(fmt.Sprintf("Error: Invalid from: %v", err)), nil
+		}
+		from = parsed
+	}
+	if from.After(to) {
+		return recordingError("Error: from must not be after to"), nil
+	}
+
+	// Build recordings ls command arguments. tsh only takes whole days, so
+	// the range is widened to days here and narrowed again below.
+	args := teleport.FormatArgs(params)
+	args = append(args, "--format", "json",
+		"--from-utc", from.Format(time.DateOnly),
+		"--to-utc", to.AddDate(0, 0, 1).Format(time.DateOnly),
+	)
+
+	if limit, ok := params["limit"].(float64); ok && limit > 0 {
+		args = append(args, "--limit", fmt.Sprintf("%d", int(limit)))
+	}
+
+	if cluster, ok := params["cluster"].(string); ok && cluster != "" {
+		args = append(args, "--cluster", cluster)
+	}
+
+	// Execute recordings ls command
+	result := client.ExecuteCommandContext(ctx, "recordings ls", args)
+	if !result.Success {
+		return recordingError(fmt.Sprintf("Error: %s\n%s", result.ErrorMessage, result.Output)), nil
+	}
+
+	recordings, err := parseRecordings(result.Stdout)
+	if err != nil {
+		// If JSON parsing fails, return raw output
+		return mcp.NewToolResultText(result.Output), nil
+	}
+
+	list := RecordingList{From: from, To: to, Recordings: []Recording{}}
+	for _, recording := range recordings {
+		// Keep sessions that overlap the requested range
+		if !recording.Start.After(to) && !recording.End.Before(from) {
+			list.Recordings = append(list.Recordings, recording)
+		}
+	}
+	sort.Slice(list.Recordings, func(i, j int) bool {
+		return list.Recordings[i].Start.After(list.Recordings[j].Start)
+	})
+
+	return server.WithDiagnostics(mcp.NewToolResultStructured(list, formatRecordings(&list)), result), nil
+}
+
+// handleRecordingPlay handles the teleport_recording_play tool
+func handleRecordingPlay(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Create teleport client
+	client := sc.TeleportClient()
+
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	sessionID, _ := params["sessionId"].(string)
+	if sessionID == "" {
+		return recordingError("Error: sessionId is required"), nil
+	}
+	if !sessionIDPattern.MatchString(sessionID) {
+		return recordingError(fmt.Sprintf("Error: Invalid session ID %q", sessionID)), nil
+	}
+
+	// Build play command arguments
+	args := teleport.FormatArgs(params)
+	args = append(args, "--format", "text")
+
+	if cluster, ok := params["cluster"].(string); ok && cluster != "" {
+		args = append(args, "--cluster", cluster)
+	}
+
+	args = append(args, sessionID)
+
+	// Execute play command
+	result := client.ExecuteCommandContext(ctx, "play", args)
+	if !result.Success {
+		return recordingError(fmt.Sprintf("Error: %s\n%s", result.ErrorMessage, result.Output)), nil
+	}
+
+	transcript := cleanTranscript(result.Stdout)
+	if strings.TrimSpace(transcript) == "" {
+		return server.WithDiagnostics(mcp.NewToolResultText(fmt.Sprintf("Session %s recorded no output", sessionID)), result), nil
+	}
+
+	// Long sessions are truncated; the rest can be read with teleport_output_read
+	maxBytes, _ := params["maxBytes"].(float64)
+	output := sc.LimitOutputTo(ctx, transcript, int(maxBytes))
+
+	text := fmt.Sprintf("Transcript of session %s (%d bytes):\n\n%s", sessionID, output.TotalBytes, output.Text)
+	return server.WithDiagnostics(mcp.NewToolResultText(text), result), nil
+}
+
+// parseTime parses an RFC 3339 timestamp or a date, which is taken as midnight UTC
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected an RFC 3339 timestamp or a YYYY-MM-DD date, got %q", value)
+	}
+	return t, nil
+}
+
+// parseRecordings parses tsh recordings ls JSON output
+func parseRecordings(jsonOutput string) ([]Recording, error) {
+	if strings.TrimSpace(jsonOutput) == "" {
+		return []Recording{}, nil
+	}
+
+	var raw []recordingJSON
+	if err := json.Unmarshal([]byte(jsonOutput), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON output: %w", err)
+	}
+
+	recordings := make([]Recording, 0, len(raw))
+	for _, r := range raw {
+		recording := Recording{
+			SessionID:    r.SessionID,
+			Kind:         "ssh",
+			Cluster:      r.Cluster,
+			User:         r.User,
+			Login:        r.Login,
+			Target:       r.ServerHostname,
+			Participants: r.Participants,
+			Interactive:  r.Interactive,
+			Command:      strings.Join(r.InitialCommand, " "),
+			Start:        r.SessionStart,
+			End:          r.SessionStop,
+		}
+		switch {
+		case r.KubernetesCluster != "":
+			recording.Kind, recording.Target = "kube", r.KubernetesCluster
+		case r.DatabaseService != "":
+			recording.Kind, recording.Target = "db", r.DatabaseService
+		case r.DesktopName != "":
+			recording.Kind, recording.Target = "desktop", r.DesktopName
+		case recording.Target == "":
+			recording.Target = r.ServerID
+		}
+		// Older events only carry the time the session ended
+		if recording.End.IsZero() {
+			recording.End = r.Time
+		}
+		if recording.Start.IsZero() {
+			recording.Start = recording.End
+		}
+		recording.DurationMs = recording.End.Sub(recording.Start).Milliseconds()
+		recordings = append(recordings, recording)
+	}
+	return recordings, nil
+}
+
+// formatRecordings formats a list of recorded sessions for display
+func formatRecordings(list *RecordingList) string {
+	if len(list.Recordings) == 0 {
+		return fmt.Sprintf("No recorded sessions between %s and %s", list.From.Format(time.RFC3339), list.To.Format(time.RFC3339))
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Found %d recorded session(s) between %s and %s:\n\n",
+		len(list.Recordings), list.From.Format(time.RFC3339), list.To.Format(time.RFC3339)))
+	for _, recording := range list.Recordings {
+		result.WriteString(fmt.Sprintf("• %s (%s)\n", recording.SessionID, recording.Kind))
+		user := recording.User
+		if recording.Login != "" {
+			user += " as " + recording.Login
+		}
+		result.WriteString(fmt.Sprintf("  User: %s\n", user))
+		result.WriteString(fmt.Sprintf("  Target: %s\n", recording.Target))
+		result.WriteString(fmt.Sprintf("  Started: %s (%s)\n", recording.Start.Format(time.RFC3339), formatDuration(recording.DurationMs)))
+		if recording.Command != "" {
+			result.WriteString(fmt.Sprintf("  Command: %s\n", recording.Command))
+		}
+		if len(recording.Participants) > 1 {
+			result.WriteString(fmt.Sprintf("  Participants: %s\n", strings.Join(recording.Participants, ", ")))
+		}
+		result.WriteString("\n")
+	}
+	result.WriteString("Use teleport_recording_play with a session ID to read its transcript.")
+	return result.String()
+}
+
+// cleanTranscript removes terminal escape sequences and carriage returns
+// from a session transcript so it reads as plain text
+func cleanTranscript(transcript string) string {
+	transcript = escapeSequencePattern.ReplaceAllString(transcript, "")
+	transcript = strings.ReplaceAll(transcript, "\r\n", "\n")
+	return strings.ReplaceAll(transcript, "\r", "\n")
+}
+
+// recordingError builds an error result for a session recording request
+func recordingError(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+		IsError: true,
+	}
+}
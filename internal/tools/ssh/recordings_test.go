@@ -0,0 +1,122 @@
+package ssh
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport/tshtest"
+)
+
+func TestHandleRecordingsList(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh recordings ls --format json --from-utc 2026-10-14 --to-utc 2026-10-15 --limit 50$`, tshtest.Response{
+			Stdout: tshtest.Fixture(t, "testdata/tsh_recordings_ls.json"),
+		})
+
+	sc, err := server.NewServerContext(context.Background(), server.WithRunner(runner))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	result, err := handleRecordingsList(context.Background(), createTestRequest(map[string]interface{}{
+		"from":  "2026-10-14",
+		"to":    "2026-10-14T23:59:59Z",
+		"limit": float64(50),
+	}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleRecordingsList() failed: %v %+v", err, result)
+	}
+
+	// The session from two days earlier is outside the range
+	list := result.StructuredContent.(RecordingList)
+	if len(list.Recordings) != 2 {
+		t.Fatalf("Expected 2 recordings, got %+v", list.Recordings)
+	}
+	kube, node := list.Recordings[0], list.Recordings[1]
+	if kube.Kind != "kube" || kube.Target != "wallaby" || kube.Command != "kubectl exec -n kube-system etcd-0 -- etcdctl endpoint health" {
+		t.Errorf("Unexpected kube recording: %+v", kube)
+	}
+	if node.SessionID != "6f2a9c1e-8d4b-4e7a-b3f5-0c9d2e1a7b84" || node.Kind != "ssh" || node.Target != "wallaby-9wldd" || node.Login != "root" {
+		t.Errorf("Unexpected node recording: %+v", node)
+	}
+	if node.DurationMs != 728380 {
+		t.Errorf("DurationMs = %d, want 728380", node.DurationMs)
+	}
+
+	text := extractTextFromContent(result.Content[0])
+	for _, expected := range []string{"Found 2 recorded session(s)", "User: alice@example.com as root", "Participants: alice@example.com, bob@example.com"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in result, got: %s", expected, text)
+		}
+	}
+}
+
+func TestHandleRecordingsListInvalidRange(t *testing.T) {
+	sc := &server.ServerContext{}
+
+	for _, params := range []map[string]interface{}{
+		{"from": "yesterday"},
+		{"from": "2026-10-15", "to": "2026-10-14"},
+	} {
+		result, err := handleRecordingsList(context.Background(), createTestRequest(params), sc)
+		if err != nil {
+			t.Fatalf("Expected no error from handler, got: %v", err)
+		}
+		if !result.IsError {
+			t.Errorf("Expected %v to be rejected, got: %+v", params, result)
+		}
+	}
+}
+
+func TestHandleRecordingPlay(t *testing.T) {
+	transcript := "\x1b]0;root@wallaby-9wldd\x07\x1b[01;32mroot@wallaby-9wldd\x1b[00m:~# systemctl restart kubelet\r\n" +
+		"\x1b[01;32mroot@wallaby-9wldd\x1b[00m:~# exit\r\nlogout\r\n"
+	runner := tshtest.NewRunner().
+		On(`^tsh play --format text 6f2a9c1e-8d4b-4e7a-b3f5-0c9d2e1a7b84$`, tshtest.Response{Stdout: transcript})
+
+	sc, err := server.NewServerContext(context.Background(), server.WithRunner(runner))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	result, err := handleRecordingPlay(context.Background(), createTestRequest(map[string]interface{}{
+		"sessionId": "6f2a9c1e-8d4b-4e7a-b3f5-0c9d2e1a7b84",
+	}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleRecordingPlay() failed: %v %+v", err, result)
+	}
+	expected := "root@wallaby-9wldd:~# systemctl restart kubelet\nroot@wallaby-9wldd:~# exit\nlogout\n"
+	if text := extractTextFromContent(result.Content[0]); !strings.HasSuffix(text, "\n\n"+expected) {
+		t.Errorf("Expected a plain text transcript, got: %q", text)
+	}
+
+	// A smaller maxBytes truncates the transcript
+	result, err = handleRecordingPlay(context.Background(), createTestRequest(map[string]interface{}{
+		"sessionId": "6f2a9c1e-8d4b-4e7a-b3f5-0c9d2e1a7b84",
+		"maxBytes":  float64(20),
+	}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleRecordingPlay() failed: %v %+v", err, result)
+	}
+	if text := extractTextFromContent(result.Content[0]); !strings.Contains(text, "read them with teleport_output_read handle=out-1") {
+		t.Errorf("Expected a truncated transcript, got: %q", text)
+	}
+}
+
+func TestHandleRecordingPlayInvalidSessionID(t *testing.T) {
+	sc := &server.ServerContext{}
+
+	result, err := handleRecordingPlay(context.Background(), createTestRequest(map[string]interface{}{
+		"sessionId": "--proxy=evil.example.com",
+	}), sc)
+	if err != nil {
+		t.Fatalf("Expected no error from handler, got: %v", err)
+	}
+	if !result.IsError || !strings.Contains(extractTextFromContent(result.Content[0]), "Invalid session ID") {
+		t.Errorf("Expected the session ID to be rejected, got: %+v", result)
+	}
+}
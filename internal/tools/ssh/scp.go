@@ -0,0 +1,285 @@
+package ssh
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/giantswarm/mcp-teleport/internal/policy"
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Transfer is the result of a teleport_scp call that ran in the foreground
+type Transfer struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	// Direction is upload, download, remote or local
+	Direction string `json:"direction"`
+	// LocalPath and RemotePath are the transferred file on either side
+	LocalPath  string `json:"localPath,omitempty"`
+	RemotePath string `json:"remotePath,omitempty"`
+	// SizeBytes and SHA256 describe the local file, RemoteSizeBytes and
+	// RemoteSHA256 the remote one
+	SizeBytes       int64  `json:"sizeBytes,omitempty"`
+	SHA256          string `json:"sha256,omitempty"`
+	RemoteSizeBytes int64  `json:"remoteSizeBytes,omitempty"`
+	RemoteSHA256    string `json:"remoteSha256,omitempty"`
+	// Verified is set when both files have the same size and SHA-256
+	Verified bool `json:"verified"`
+	// Note says why the transfer was not verified
+	Note   string `json:"note,omitempty"`
+	Output string `json:"output,omitempty"`
+	// Handle names the full output for teleport_output_read if it was truncated
+	Handle string `json:"handle,omitempty"`
+}
+
+// scpEndpoint is the source or destination of a transfer
+type scpEndpoint struct {
+	// Host is [user@]host for remote paths and empty for local ones
+	Host string
+	// Path is the resolved local path or the normalized remote path
+	Path string
+}
+
+// remote reports whether the endpoint is on an SSH node
+func (e scpEndpoint) remote() bool {
+	return e.Host != ""
+}
+
+// parseSCPPath splits a [user@]host:path argument into host and path; an
+// argument without a colon before its first slash is a local path
+func parseSCPPath(arg string) (string, string) {
+	if i := strings.Index(arg, ":"); i > 0 && !strings.Contains(arg[:i], "/") {
+		return arg[:i], arg[i+1:]
+	}
+	return "", arg
+}
+
+// checkTransfer checks source and destination against the path policy.
+// Since a destination directory receives the source under its own name,
+// that path is checked as well.
+func checkTransfer(scpPolicy policy.SCPPolicy, source, destination string, recursive bool) (scpEndpoint, scpEndpoint, error) {
+	check := func(host, p string) (string, error) {
+		if host != "" {
+			return scpPolicy.CheckRemote(p, recursive)
+		}
+		return scpPolicy.CheckLocal(p, recursive)
+	}
+
+	var from, to scpEndpoint
+	var err error
+	var sourcePath, destinationPath string
+	from.Host, sourcePath = parseSCPPath(source)
+	if from.Path, err = check(from.Host, sourcePath); err != nil {
+		return from, to, err
+	}
+	to.Host, destinationPath = parseSCPPath(destination)
+	if to.Path, err = check(to.Host, destinationPath); err != nil {
+		return from, to, err
+	}
+
+	if to.remote() {
+		_, err = check(to.Host, path.Join(destinationPath, path.Base(from.Path)))
+	} else {
+		_, err = check(to.Host, filepath.Join(destinationPath, path.Base(from.Path)))
+	}
+	return from, to, err
+}
+
+// verifyTransfer compares the size and SHA-256 of a file on both sides of a
+// finished transfer. Transfers without exactly one remote side are not
+// verified; Note says why.
+func verifyTransfer(ctx context.Context, sc *server.ServerContext, client *teleport.Client, params map[string]interface{}, from, to scpEndpoint) (Transfer, *teleport.ExecutionResult) {
+	transfer := Transfer{Direction: transferDirection(from, to)}
+	var host, remoteScript string
+	switch transfer.Direction {
+	case "upload":
+		// The destination may be a directory receiving the file under its own name
+		transfer.LocalPath = from.Path
+		host = to.Host
+		remoteScript = fmt.Sprintf("p=%s; [ ! -d \"$p\" ] || p=\"$p\"/%s; ", remoteShellPath(to.Path), shellQuote(filepath.Base(from.Path)))
+	case "download":
+		transfer.LocalPath = to.Path
+		if info, err := os.Stat(to.Path); err == nil && info.IsDir() {
+			transfer.LocalPath = filepath.Join(to.Path, path.Base(from.Path))
+		}
+		host = from.Host
+		remoteScript = fmt.Sprintf("p=%s; ", remoteShellPath(from.Path))
+	case "remote":
+		transfer.Note = "transfers between two remote hosts are not verified"
+		return transfer, nil
+	default:
+		transfer.Note = "transfers without a remote side are not verified"
+		return transfer, nil
+	}
+
+	size, checksum, err := localChecksum(transfer.LocalPath)
+	if err != nil {
+		transfer.Note = fmt.Sprintf("cannot read the local file: %v", err)
+		return transfer, nil
+	}
+	transfer.SizeBytes = size
+	transfer.SHA256 = checksum
+
+	remoteScript += "echo path:\"$p\"; echo sha256:$(sha256sum \"$p\" 2>/dev/null || shasum -a 256 \"$p\"); echo size:$(wc -c < \"$p\")"
+	if decision := checkSSHPolicy(ctx, sc, client, params, host, remoteScript); decision != nil && !decision.Allowed {
+		transfer.Note = fmt.Sprintf("cannot checksum the remote file: the command %s", decision)
+		return transfer, nil
+	}
+	result := client.ExecuteCommandContext(ctx, "ssh", append(fileSSHOptions(params), host, remoteScript))
+	if !result.Success {
+		transfer.Note = fmt.Sprintf("cannot checksum the remote file: %s", result.ErrorMessage)
+		return transfer, result
+	}
+
+	transfer.RemoteSizeBytes = -1
+	for _, line := range strings.Split(result.Stdout, "\n") {
+		switch {
+		case strings.HasPrefix(line, "path:"):
+			transfer.RemotePath = strings.TrimPrefix(line, "path:")
+		case strings.HasPrefix(line, "sha256:"):
+			if fields := strings.Fields(strings.TrimPrefix(line, "sha256:")); len(fields) > 0 {
+				transfer.RemoteSHA256 = fields[0]
+			}
+		case strings.HasPrefix(line, "size:"):
+			if size, err := strconv.ParseInt(strings.TrimSpace(strings.TrimPrefix(line, "size:")), 10, 64); err == nil {
+				transfer.RemoteSizeBytes = size
+			}
+		}
+	}
+	transfer.Verified = transfer.SHA256 == transfer.RemoteSHA256 && transfer.SizeBytes == transfer.RemoteSizeBytes
+	return transfer, result
+}
+
+// verifyJobTransfer verifies the transfer of a background job once tsh scp
+// has finished, appending the outcome to the job's output. A mismatch fails
+// the job.
+func verifyJobTransfer(ctx context.Context, sc *server.ServerContext, client *teleport.Client, params map[string]interface{}, from, to scpEndpoint, result *teleport.ExecutionResult, output io.Writer) {
+	transfer, _ := verifyTransfer(ctx, sc, client, params, from, to)
+	if transfer.Note == "" && !transfer.Verified {
+		result.Success = false
+		result.ErrorMessage = fmt.Sprintf("verification of the transfer failed: %s has %d bytes with SHA-256 %s, but %s has %d bytes with SHA-256 %q",
+			transfer.LocalPath, transfer.SizeBytes, transfer.SHA256, transfer.RemotePath, transfer.RemoteSizeBytes, transfer.RemoteSHA256)
+		fmt.Fprintf(output, "Error: %s\n", result.ErrorMessage)
+		return
+	}
+	fmt.Fprint(output, formatVerification(&transfer))
+}
+
+// remoteSCPCommand returns the scp command tsh scp runs on the host of a
+// remote endpoint: scp -t to receive files, scp -f to send them
+func remoteSCPCommand(endpoint scpEndpoint, sink, recursive, preserveAttributes bool) string {
+	command := "scp -f"
+	if sink {
+		command = "scp -t"
+	}
+	if recursive {
+		command += " -r"
+	}
+	if preserveAttributes {
+		command += " -p"
+	}
+	return command + " " + remoteShellPath(endpoint.Path)
+}
+
+// transferDirection returns upload, download, remote or local
+func transferDirection(from, to scpEndpoint) string {
+	switch {
+	case !from.remote() && to.remote():
+		return "upload"
+	case from.remote() && !to.remote():
+		return "download"
+	case from.remote():
+		return "remote"
+	default:
+		return "local"
+	}
+}
+
+// localChecksum returns the size and hex SHA-256 of a local file
+func localChecksum(localPath string) (int64, string, error) {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return 0, "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// remoteShellPath quotes a normalized remote path for the remote shell,
+// keeping a leading ~ expandable
+func remoteShellPath(remotePath string) string {
+	if remotePath == "~" {
+		return "\"$HOME\""
+	}
+	if rest, ok := strings.CutPrefix(remotePath, "~/"); ok {
+		return "\"$HOME\"/" + shellQuote(rest)
+	}
+	return shellQuote(remotePath)
+}
+
+// formatTransfer renders a foreground transfer as text
+func formatTransfer(transfer *Transfer) string {
+	var sb strings.Builder
+	sb.WriteString("File transfer completed successfully\n")
+	if transfer.Output != "" {
+		sb.WriteString(transfer.Output)
+		if !strings.HasSuffix(transfer.Output, "\n") {
+			sb.WriteString("\n")
+		}
+	}
+	sb.WriteString(formatVerification(transfer))
+	return sb.String()
+}
+
+// formatVerification renders whether a transfer was verified as text
+func formatVerification(transfer *Transfer) string {
+	if transfer.Verified {
+		return fmt.Sprintf("Verified: %s and %s are both %d bytes with SHA-256 %s\n",
+			transfer.LocalPath, transfer.RemotePath, transfer.SizeBytes, transfer.SHA256)
+	}
+	if transfer.Note != "" {
+		return fmt.Sprintf("Not verified: %s\n", transfer.Note)
+	}
+	return ""
+}
+
+// scpError returns a teleport_scp error result
+func scpError(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+		IsError: true,
+	}
+}
+
+// transferResult builds the result of a finished foreground transfer,
+// failing it if the checksums of both sides differ
+func transferResult(transfer Transfer, executions ...*teleport.ExecutionResult) *mcp.CallToolResult {
+	if transfer.Note == "" && !transfer.Verified {
+		result := mcp.NewToolResultStructured(transfer, fmt.Sprintf("Error: Verification of the transfer failed: %s has %d bytes with SHA-256 %s, but %s has %d bytes with SHA-256 %q\n%s",
+			transfer.LocalPath, transfer.SizeBytes, transfer.SHA256, transfer.RemotePath, transfer.RemoteSizeBytes, transfer.RemoteSHA256, transfer.Output))
+		result.IsError = true
+		return server.WithDiagnostics(result, executions...)
+	}
+	return server.WithDiagnostics(mcp.NewToolResultStructured(transfer, formatTransfer(&transfer)), executions...)
+}
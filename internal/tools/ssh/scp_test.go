@@ -0,0 +1,219 @@
+package ssh
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/mcp-teleport/internal/policy"
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport/tshtest"
+)
+
+func TestHandleSCPVerifiesTransfer(t *testing.T) {
+	content := "maxPods: 110\n"
+	checksum := sha256.Sum256([]byte(content))
+	hash := hex.EncodeToString(checksum[:])
+
+	dir := t.TempDir()
+	localFile := filepath.Join(dir, "kubelet.yaml")
+	if err := os.WriteFile(localFile, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := tshtest.NewRunner().
+		On(`^tsh scp `, tshtest.Response{}).
+		On(`^tsh ssh root@wallaby-9wldd p='/tmp'; `+regexp.QuoteMeta(`[ ! -d "$p" ] || p="$p"/'kubelet.yaml'; `), tshtest.Response{
+			Stdout: "path:/tmp/kubelet.yaml\nsha256:" + hash + "  /tmp/kubelet.yaml\nsize:13\n",
+		}).
+		On(`^tsh ssh root@wallaby-9wldd p="\$HOME"/'kubelet.yaml'; `, tshtest.Response{
+			Stdout: "path:/root/kubelet.yaml\nsha256:0000  /root/kubelet.yaml\nsize:13\n",
+		})
+
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithRunner(runner),
+		server.WithNonDestructiveMode(false),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	// Upload into a directory
+	result, err := handleSCP(context.Background(), createTestRequest(map[string]interface{}{
+		"source":      localFile,
+		"destination": "root@wallaby-9wldd:/tmp",
+	}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleSCP() failed: %v %+v", err, result)
+	}
+	transfer := result.StructuredContent.(Transfer)
+	if !transfer.Verified || transfer.Direction != "upload" || transfer.SHA256 != hash || transfer.SizeBytes != 13 || transfer.RemotePath != "/tmp/kubelet.yaml" {
+		t.Errorf("Unexpected transfer: %+v", transfer)
+	}
+	if text := extractTextFromContent(result.Content[0]); !strings.Contains(text, "Verified: "+localFile+" and /tmp/kubelet.yaml are both 13 bytes with SHA-256 "+hash) {
+		t.Errorf("Unexpected result: %s", text)
+	}
+
+	// Download whose copy differs from the remote file
+	result, err = handleSCP(context.Background(), createTestRequest(map[string]interface{}{
+		"source":      "root@wallaby-9wldd:kubelet.yaml",
+		"destination": dir,
+	}), sc)
+	if err != nil {
+		t.Fatalf("Expected no error from handler, got: %v", err)
+	}
+	if !result.IsError || !strings.Contains(extractTextFromContent(result.Content[0]), "Verification of the transfer failed") {
+		t.Errorf("Expected a verification error, got: %+v", result)
+	}
+	if transfer := result.StructuredContent.(Transfer); transfer.Direction != "download" || transfer.LocalPath != localFile {
+		t.Errorf("Unexpected transfer: %+v", transfer)
+	}
+
+	// Recursive transfers are not verified
+	result, err = handleSCP(context.Background(), createTestRequest(map[string]interface{}{
+		"source":      dir,
+		"destination": "root@wallaby-9wldd:/tmp",
+		"recursive":   true,
+	}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleSCP() failed: %v %+v", err, result)
+	}
+	if text := extractTextFromContent(result.Content[0]); !strings.Contains(text, "Not verified: recursive transfers are not verified") {
+		t.Errorf("Unexpected result: %s", text)
+	}
+}
+
+func TestHandleSCPVerifiesBackgroundTransfer(t *testing.T) {
+	content := "maxPods: 110\n"
+	checksum := sha256.Sum256([]byte(content))
+	hash := hex.EncodeToString(checksum[:])
+
+	dir := t.TempDir()
+	localFile := filepath.Join(dir, "kubelet.yaml")
+	if err := os.WriteFile(localFile, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := tshtest.NewRunner().
+		On(`^tsh scp `, tshtest.Response{Stdout: "copied\n"}).
+		On(`^tsh ssh root@wallaby-9wldd p='/tmp'; `, tshtest.Response{
+			Stdout: "path:/tmp/kubelet.yaml\nsha256:" + hash + "  /tmp/kubelet.yaml\nsize:13\n",
+		}).
+		On(`^tsh ssh root@wallaby-9wldd p="\$HOME"/'kubelet.yaml'; `, tshtest.Response{
+			Stdout: "path:/root/kubelet.yaml\nsha256:0000  /root/kubelet.yaml\nsize:13\n",
+		})
+
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithRunner(runner),
+		server.WithNonDestructiveMode(false),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		state  server.JobState
+		output string
+	}{
+		{
+			name:   "verified upload",
+			params: map[string]interface{}{"source": localFile, "destination": "root@wallaby-9wldd:/tmp", "background": true},
+			state:  server.JobSucceeded,
+			output: "Verified: " + localFile + " and /tmp/kubelet.yaml are both 13 bytes with SHA-256 " + hash,
+		},
+		{
+			name:   "download that differs",
+			params: map[string]interface{}{"source": "root@wallaby-9wldd:kubelet.yaml", "destination": dir, "background": true},
+			state:  server.JobFailed,
+			output: "Error: verification of the transfer failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handleSCP(context.Background(), createTestRequest(tt.params), sc)
+			if err != nil || result.IsError {
+				t.Fatalf("handleSCP() failed: %v %+v", err, result)
+			}
+			job, err := sc.Jobs().Get(context.Background(), result.StructuredContent.(server.JobInfo).ID)
+			if err != nil {
+				t.Fatalf("Job not found: %v", err)
+			}
+			select {
+			case <-job.Done():
+			case <-time.After(5 * time.Second):
+				t.Fatal("Job did not finish")
+			}
+			if state := job.Info().State; state != tt.state {
+				t.Errorf("State = %q, want %q", state, tt.state)
+			}
+			if output := job.Output(0, 0).Output; !strings.HasPrefix(output, "copied\n") || !strings.Contains(output, tt.output) {
+				t.Errorf("Expected %q in the job output, got %q", tt.output, output)
+			}
+		})
+	}
+}
+
+func TestHandleSCPPathPolicy(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	runner := tshtest.NewRunner()
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithRunner(runner),
+		server.WithNonDestructiveMode(false),
+		server.WithSCPPolicy(policy.SCPPolicy{
+			LocalAllow: []string{"~/transfers"},
+			LocalDeny:  policy.DefaultLocalDeny,
+			RemoteDeny: policy.DefaultRemoteDeny,
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	for _, params := range []map[string]interface{}{
+		{"source": "~/.tsh/keys/teleport.example.com", "destination": "root@wallaby-9wldd:/tmp/"},
+		{"source": "~/transfers/../.tsh", "destination": "root@wallaby-9wldd:/tmp/", "recursive": true},
+		{"source": "/etc/passwd", "destination": "root@wallaby-9wldd:/tmp/"},
+		{"source": "~/transfers/authorized_keys", "destination": "root@wallaby-9wldd:.ssh"},
+		{"source": "~/transfers/shadow", "destination": "root@wallaby-9wldd:/etc"},
+		{"source": "root@wallaby-9wldd:/etc/shadow", "destination": "~/transfers/"},
+		{"source": "~", "destination": "root@wallaby-9wldd:/tmp/", "recursive": true},
+	} {
+		result, err := handleSCP(context.Background(), createTestRequest(params), sc)
+		if err != nil {
+			t.Fatalf("Expected no error from handler, got: %v", err)
+		}
+		if !result.IsError {
+			t.Errorf("Expected %v to be denied, got: %+v", params, result)
+		}
+	}
+
+	// Denied background transfers never start a job
+	result, err := handleSCP(context.Background(), createTestRequest(map[string]interface{}{
+		"source":      "~/.ssh/id_ed25519",
+		"destination": "root@wallaby-9wldd:/tmp/",
+		"background":  true,
+	}), sc)
+	if err != nil {
+		t.Fatalf("Expected no error from handler, got: %v", err)
+	}
+	if !result.IsError || !strings.Contains(extractTextFromContent(result.Content[0]), "is denied by") {
+		t.Errorf("Expected the transfer to be denied, got: %+v", result)
+	}
+	if len(runner.Calls()) != 0 {
+		t.Errorf("Expected no tsh calls, got %v", runner.Calls())
+	}
+}
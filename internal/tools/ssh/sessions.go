@@ -0,0 +1,382 @@
+package ssh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// defaultObserveWindow is how long teleport_session_observe watches a
+	// session unless the client asks for another window
+	defaultObserveWindow = 10 * time.Second
+
+	// maxObserveWindow bounds how long teleport_session_observe stays joined
+	maxObserveWindow = 5 * time.Minute
+
+	// observeTimeoutMargin is how long tsh join may take beyond the window
+	// to connect and detach before the call times out
+	observeTimeoutMargin = 30 * time.Second
+)
+
+// sessionStates are the session tracker states in the order Teleport numbers them
+var sessionStates = []string{"PENDING", "RUNNING", "TERMINATED"}
+
+// errObserveWindowEnded detaches an observer once its window is over
+var errObserveWindowEnded = errors.New("observation window ended")
+
+// sessionState is a session tracker state. tsh serializes it as a number or,
+// depending on the version, as its name.
+type sessionState string
+
+// UnmarshalJSON accepts both the numeric and the string form of a state
+func (s *sessionState) UnmarshalJSON(data []byte) error {
+	var number int
+	if err := json.Unmarshal(data, &number); err == nil {
+		if number < 0 || number >= len(sessionStates) {
+			return fmt.Errorf("unknown session state %d", number)
+		}
+		*s = sessionState(sessionStates[number])
+		return nil
+	}
+
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return fmt.Errorf("invalid session state %s", data)
+	}
+	*s = sessionState(strings.TrimPrefix(strings.ToUpper(name), "SESSION_STATE_"))
+	return nil
+}
+
+// sessionTrackerJSON represents a session from tsh sessions ls and tsh kube
+// sessions JSON output
+type sessionTrackerJSON struct {
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		SessionID         string       `json:"session_id"`
+		Kind              string       `json:"kind"`
+		State             sessionState `json:"state"`
+		Created           time.Time    `json:"created"`
+		ClusterName       string       `json:"cluster_name"`
+		Hostname          string       `json:"hostname"`
+		Address           string       `json:"address"`
+		Login             string       `json:"login"`
+		KubernetesCluster string       `json:"kubernetes_cluster"`
+		TargetName        string       `json:"target_name"`
+		Reason            string       `json:"reason"`
+		Command           []string     `json:"command"`
+		Participants      []struct {
+			User       string    `json:"user"`
+			Mode       string    `json:"mode"`
+			LastActive time.Time `json:"last_active"`
+		} `json:"participants"`
+	} `json:"spec"`
+}
+
+// ActiveSession is a session in progress as returned by teleport_sessions_list
+type ActiveSession struct {
+	SessionID string `json:"sessionId"`
+	// Kind is ssh, kube, db, app or desktop
+	Kind    string `json:"kind"`
+	State   string `json:"state"`
+	Cluster string `json:"cluster,omitempty"`
+	// Target is the node hostname, Kubernetes cluster or other resource name
+	Target       string        `json:"target"`
+	Login        string        `json:"login,omitempty"`
+	Command      string        `json:"command,omitempty"`
+	Reason       string        `json:"reason,omitempty"`
+	Participants []Participant `json:"participants"`
+	Created      time.Time     `json:"created"`
+}
+
+// Participant is a user joined to an active session
+type Participant struct {
+	User string `json:"user"`
+	// Mode is peer, observer or moderator
+	Mode       string    `json:"mode,omitempty"`
+	LastActive time.Time `json:"lastActive,omitempty"`
+}
+
+// ActiveSessionList is the result of teleport_sessions_list
+type ActiveSessionList struct {
+	Sessions []ActiveSession `json:"sessions"`
+}
+
+// handleSessionsList handles the teleport_sessions_list tool
+func handleSessionsList(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Create teleport client
+	client := sc.TeleportClient()
+
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	kind := "all"
+	if value, ok := params["kind"].(string); ok && value != "" {
+		kind = value
+	}
+	if kind != "all" && kind != "ssh" && kind != "kube" {
+		return sessionError(fmt.Sprintf("Error: Invalid kind %q, expected ssh, kube or all", kind)), nil
+	}
+
+	// Build common command arguments
+	args := teleport.FormatArgs(params)
+	args = append(args, "--format", "json")
+
+	if cluster, ok := params["cluster"].(string); ok && cluster != "" {
+		args = append(args, "--cluster", cluster)
+	}
+
+	// SSH sessions come from tsh sessions ls, Kubernetes sessions from tsh kube sessions
+	var commands []string
+	var commandArgs [][]string
+	if kind != "kube" {
+		commands = append(commands, "sessions ls")
+		commandArgs = append(commandArgs, append(append([]string{}, args...), "--kind", "ssh"))
+	}
+	if kind != "ssh" {
+		commands = append(commands, "kube sessions")
+		commandArgs = append(commandArgs, args)
+	}
+
+	list := ActiveSessionList{Sessions: []ActiveSession{}}
+	seen := make(map[string]bool)
+	var results []*teleport.ExecutionResult
+	for i, command := range commands {
+		result := client.ExecuteCommandContext(ctx, command, commandArgs[i])
+		if !result.Success {
+			return sessionError(fmt.Sprintf("Error: %s\n%s", result.ErrorMessage, result.Output)), nil
+		}
+		results = append(results, result)
+
+		sessions, err := parseActiveSessions(result.Stdout)
+		if err != nil {
+			// If JSON parsing fails, return raw output
+			return mcp.NewToolResultText(result.Output), nil
+		}
+		for _, session := range sessions {
+			if !seen[session.SessionID] {
+				seen[session.SessionID] = true
+				list.Sessions = append(list.Sessions, session)
+			}
+		}
+	}
+	sort.Slice(list.Sessions, func(i, j int) bool {
+		return list.Sessions[i].Created.After(list.Sessions[j].Created)
+	})
+
+	return server.WithDiagnostics(mcp.NewToolResultStructured(list, formatActiveSessions(list.Sessions)), results...), nil
+}
+
+// handleSessionObserve handles the teleport_session_observe tool
+func handleSessionObserve(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Create teleport client
+	client := sc.TeleportClient()
+
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	sessionID, _ := params["sessionId"].(string)
+	if sessionID == "" {
+		return sessionError("Error: sessionId is required"), nil
+	}
+	if !sessionIDPattern.MatchString(sessionID) {
+		return sessionError(fmt.Sprintf("Error: Invalid session ID %q", sessionID)), nil
+	}
+
+	command := "join"
+	if kind, ok := params["kind"].(string); ok && kind != "" && kind != "ssh" {
+		if kind != "kube" {
+			return sessionError(fmt.Sprintf("Error: Invalid kind %q, expected ssh or kube", kind)), nil
+		}
+		command = "kube join"
+	}
+
+	window := defaultObserveWindow
+	if seconds, ok := params["durationSeconds"].(float64); ok {
+		if seconds <= 0 {
+			return sessionError("Error: durationSeconds must be positive"), nil
+		}
+		window = min(time.Duration(seconds*float64(time.Second)), maxObserveWindow)
+	}
+
+	// Build join command arguments. Only observer mode is offered, so the
+	// session cannot be written to.
+	args := teleport.FormatArgs(params)
+	args = append(args, "--mode", "observer")
+
+	if cluster, ok := params["cluster"].(string); ok && cluster != "" {
+		args = append(args, "--cluster", cluster)
+	}
+
+	args = append(args, sessionID)
+
+	// The window decides how long tsh join runs, so the timeout must not end
+	// the call first; it only guards against tsh hanging beyond the window
+	timeout, err := sc.ToolTimeout(request.Params.Name, request.Params.Arguments)
+	if err != nil {
+		return sessionError(fmt.Sprintf("Error: Invalid timeout: %v", err)), nil
+	}
+	if timeout < window+observeTimeoutMargin {
+		timeout = sc.CapTimeout(window + observeTimeoutMargin)
+	}
+	ctx = teleport.ContextWithTimeout(ctx, timeout)
+
+	// Detach once the window is over
+	observeCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	timer := time.AfterFunc(window, func() { cancel(errObserveWindowEnded) })
+	defer timer.Stop()
+
+	start := time.Now()
+	result := client.ExecuteCommandContext(observeCtx, command, args)
+	observed := time.Since(start).Round(time.Millisecond)
+
+	var status string
+	switch {
+	case result.Success:
+		status = fmt.Sprintf("Session %s ended after %s of observation", sessionID, observed)
+	case result.Cancelled && errors.Is(context.Cause(observeCtx), errObserveWindowEnded):
+		status = fmt.Sprintf("Observed session %s for %s, then detached", sessionID, window)
+	case result.TimedOut:
+		// The server's maximum timeout is shorter than the window
+		status = fmt.Sprintf("Observed session %s for %s, then detached at the server's maximum timeout", sessionID, observed)
+	default:
+		return sessionError(fmt.Sprintf("Error: %s\n%s", result.ErrorMessage, result.Output)), nil
+	}
+
+	transcript := cleanTranscript(result.Stdout)
+	if strings.TrimSpace(transcript) == "" {
+		return server.WithDiagnostics(mcp.NewToolResultText(status+"; no output was seen"), result), nil
+	}
+
+	// Busy sessions are truncated; the rest can be read with teleport_output_read
+	maxBytes, _ := params["maxBytes"].(float64)
+	output := sc.LimitOutputTo(ctx, transcript, int(maxBytes))
+
+	text := fmt.Sprintf("%s (%d bytes of output):\n\n%s", status, output.TotalBytes, output.Text)
+	return server.WithDiagnostics(mcp.NewToolResultText(text), result), nil
+}
+
+// parseActiveSessions parses tsh sessions ls or tsh kube sessions JSON output
+func parseActiveSessions(jsonOutput string) ([]ActiveSession, error) {
+	if strings.TrimSpace(jsonOutput) == "" {
+		return []ActiveSession{}, nil
+	}
+
+	var raw []sessionTrackerJSON
+	if err := json.Unmarshal([]byte(jsonOutput), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON output: %w", err)
+	}
+
+	sessions := make([]ActiveSession, 0, len(raw))
+	for _, r := range raw {
+		session := ActiveSession{
+			SessionID:    r.Spec.SessionID,
+			Kind:         r.Spec.Kind,
+			State:        string(r.Spec.State),
+			Cluster:      r.Spec.ClusterName,
+			Target:       r.Spec.TargetName,
+			Login:        r.Spec.Login,
+			Command:      strings.Join(r.Spec.Command, " "),
+			Reason:       r.Spec.Reason,
+			Participants: []Participant{},
+			Created:      r.Spec.Created,
+		}
+		if session.SessionID == "" {
+			session.SessionID = r.Metadata.Name
+		}
+		// Teleport calls Kubernetes sessions k8s
+		if session.Kind == "k8s" {
+			session.Kind = "kube"
+		}
+		switch {
+		case session.Kind == "kube" && r.Spec.KubernetesCluster != "":
+			session.Target = r.Spec.KubernetesCluster
+		case r.Spec.Hostname != "":
+			session.Target = r.Spec.Hostname
+		case session.Target == "":
+			session.Target = r.Spec.Address
+		}
+		for _, participant := range r.Spec.Participants {
+			session.Participants = append(session.Participants, Participant{
+				User:       participant.User,
+				Mode:       participant.Mode,
+				LastActive: participant.LastActive,
+			})
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// formatActiveSessions formats a list of active sessions for display
+func formatActiveSessions(sessions []ActiveSession) string {
+	if len(sessions) == 0 {
+		return "No active sessions found"
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Found %d active session(s):\n\n", len(sessions)))
+	for _, session := range sessions {
+		result.WriteString(fmt.Sprintf("• %s (%s, %s)\n", session.SessionID, session.Kind, session.State))
+		target := session.Target
+		if session.Login != "" {
+			target = session.Login + "@" + target
+		}
+		result.WriteString(fmt.Sprintf("  Target: %s\n", target))
+		result.WriteString(fmt.Sprintf("  Started: %s\n", session.Created.Format(time.RFC3339)))
+		if session.Command != "" {
+			result.WriteString(fmt.Sprintf("  Command: %s\n", session.Command))
+		}
+		if session.Reason != "" {
+			result.WriteString(fmt.Sprintf("  Reason: %s\n", session.Reason))
+		}
+		var participants []string
+		for _, participant := range session.Participants {
+			if participant.Mode != "" {
+				participants = append(participants, fmt.Sprintf("%s (%s)", participant.User, participant.Mode))
+			} else {
+				participants = append(participants, participant.User)
+			}
+		}
+		if len(participants) > 0 {
+			result.WriteString(fmt.Sprintf("  Participants: %s\n", strings.Join(participants, ", ")))
+		}
+		result.WriteString("\n")
+	}
+	result.WriteString("Use teleport_session_observe with a session ID to watch a session.")
+	return result.String()
+}
+
+// sessionError builds an error result for an active session request
+func sessionError(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+		IsError: true,
+	}
+}
@@ -0,0 +1,173 @@
+package ssh
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport/tshtest"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleSessionsList(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh sessions ls --format json --kind ssh$`, tshtest.Response{Stdout: tshtest.Fixture(t, "testdata/tsh_sessions_ls.json")}).
+		On(`^tsh kube sessions --format json$`, tshtest.Response{Stdout: tshtest.Fixture(t, "testdata/tsh_kube_sessions.json")})
+
+	sc, err := server.NewServerContext(context.Background(), server.WithRunner(runner))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	result, err := handleSessionsList(context.Background(), createTestRequest(map[string]interface{}{}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleSessionsList() failed: %v %+v", err, result)
+	}
+
+	// Sessions from both commands, most recent first
+	list := result.StructuredContent.(ActiveSessionList)
+	if len(list.Sessions) != 2 {
+		t.Fatalf("Expected 2 sessions, got %+v", list.Sessions)
+	}
+	kube, node := list.Sessions[0], list.Sessions[1]
+	if kube.Kind != "kube" || kube.State != "RUNNING" || kube.Target != "wallaby" || kube.Reason != "INC-1234" {
+		t.Errorf("Unexpected kube session: %+v", kube)
+	}
+	if node.Kind != "ssh" || node.State != "RUNNING" || node.Target != "wallaby-9wldd" || node.Login != "root" || len(node.Participants) != 2 {
+		t.Errorf("Unexpected SSH session: %+v", node)
+	}
+
+	text := extractTextFromContent(result.Content[0])
+	for _, expected := range []string{"Found 2 active session(s)", "Target: root@wallaby-9wldd", "Participants: alice@example.com (peer), bob@example.com (observer)"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in result, got: %s", expected, text)
+		}
+	}
+
+	// Only SSH sessions
+	result, err = handleSessionsList(context.Background(), createTestRequest(map[string]interface{}{"kind": "ssh"}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleSessionsList() failed: %v %+v", err, result)
+	}
+	if list := result.StructuredContent.(ActiveSessionList); len(list.Sessions) != 1 || list.Sessions[0].Kind != "ssh" {
+		t.Errorf("Expected only the SSH session, got %+v", list.Sessions)
+	}
+}
+
+func TestHandleSessionObserve(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh join --mode observer 3a7c9e1f-5b2d-4f8a-9c6e-0d1b2a3c4e5f$`, tshtest.Response{
+			Stdout: "\x1b[01;32mroot@wallaby-9wldd\x1b[00m:~# tail -f /var/log/syslog\r\nkubelet: node ready\r\n",
+			Block:  true,
+		}).
+		On(`^tsh kube join --mode observer 9d8c7b6a-5f4e-4d3c-8b2a-1f0e9d8c7b6a$`, tshtest.Response{Stdout: "# exit\r\n"})
+
+	sc, err := server.NewServerContext(context.Background(), server.WithRunner(runner))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer sc.Shutdown()
+
+	// The observer detaches once the window is over
+	result, err := handleSessionObserve(context.Background(), createTestRequest(map[string]interface{}{
+		"sessionId":       "3a7c9e1f-5b2d-4f8a-9c6e-0d1b2a3c4e5f",
+		"durationSeconds": 0.05,
+	}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleSessionObserve() failed: %v %+v", err, result)
+	}
+	text := extractTextFromContent(result.Content[0])
+	for _, expected := range []string{"Observed session 3a7c9e1f-5b2d-4f8a-9c6e-0d1b2a3c4e5f for 50ms, then detached", "root@wallaby-9wldd:~# tail -f /var/log/syslog\nkubelet: node ready\n"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in result, got: %q", expected, text)
+		}
+	}
+
+	// A session that ends during the window
+	result, err = handleSessionObserve(context.Background(), createTestRequest(map[string]interface{}{
+		"sessionId": "9d8c7b6a-5f4e-4d3c-8b2a-1f0e9d8c7b6a",
+		"kind":      "kube",
+	}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleSessionObserve() failed: %v %+v", err, result)
+	}
+	if text := extractTextFromContent(result.Content[0]); !strings.Contains(text, "Session 9d8c7b6a-5f4e-4d3c-8b2a-1f0e9d8c7b6a ended after") {
+		t.Errorf("Expected the session to have ended, got: %q", text)
+	}
+}
+
+func TestHandleSessionObserveLongerThanTimeout(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh join --mode observer 3a7c9e1f-5b2d-4f8a-9c6e-0d1b2a3c4e5f$`, tshtest.Response{
+			Stdout: "root@wallaby-9wldd:~# uptime\r\n",
+			Block:  true,
+		})
+
+	tests := []struct {
+		name     string
+		options  []server.ServerOption
+		expected string
+	}{
+		{
+			name:     "window longer than the default timeout",
+			options:  []server.ServerOption{server.WithDefaultTimeout(20 * time.Millisecond)},
+			expected: "for 200ms, then detached",
+		},
+		{
+			name:     "window longer than the maximum timeout",
+			options:  []server.ServerOption{server.WithDefaultTimeout(20 * time.Millisecond), server.WithMaxTimeout(100 * time.Millisecond)},
+			expected: "then detached at the server's maximum timeout",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, err := server.NewServerContext(context.Background(), append(tt.options, server.WithRunner(runner))...)
+			if err != nil {
+				t.Fatalf("Failed to create server context: %v", err)
+			}
+			defer sc.Shutdown()
+
+			request := createTestRequest(map[string]interface{}{
+				"sessionId":       "3a7c9e1f-5b2d-4f8a-9c6e-0d1b2a3c4e5f",
+				"durationSeconds": 0.2,
+			})
+			request.Params.Name = "teleport_session_observe"
+			handler := sc.ToolHandlerMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return handleSessionObserve(ctx, request, sc)
+			})
+			result, err := handler(context.Background(), request)
+			if err != nil || result.IsError {
+				t.Fatalf("handleSessionObserve() failed: %v %+v", err, result)
+			}
+			text := extractTextFromContent(result.Content[0])
+			for _, expected := range []string{tt.expected, "root@wallaby-9wldd:~# uptime"} {
+				if !strings.Contains(text, expected) {
+					t.Errorf("Expected %q in result, got: %q", expected, text)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleSessionObserveInvalidParameters(t *testing.T) {
+	sc := &server.ServerContext{}
+
+	for _, params := range []map[string]interface{}{
+		{},
+		{"sessionId": "-mode=peer"},
+		{"sessionId": "3a7c9e1f-5b2d-4f8a-9c6e-0d1b2a3c4e5f", "kind": "db"},
+		{"sessionId": "3a7c9e1f-5b2d-4f8a-9c6e-0d1b2a3c4e5f", "durationSeconds": float64(-1)},
+	} {
+		result, err := handleSessionObserve(context.Background(), createTestRequest(params), sc)
+		if err != nil {
+			t.Fatalf("Expected no error from handler, got: %v", err)
+		}
+		if !result.IsError {
+			t.Errorf("Expected %v to be rejected, got: %+v", params, result)
+		}
+	}
+}
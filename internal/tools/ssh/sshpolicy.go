@@ -0,0 +1,214 @@
+package ssh
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/mcp-teleport/internal/policy"
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// PolicyExplanation is the result of teleport_ssh_policy_explain
+type PolicyExplanation struct {
+	Destination string `json:"destination"`
+	Command     string `json:"command"`
+	// Allowed is set when the command would run on every node
+	Allowed bool           `json:"allowed"`
+	Nodes   []NodeDecision `json:"nodes"`
+}
+
+// NodeDecision is the SSH policy decision for one node
+type NodeDecision struct {
+	Target   policy.SSHTarget   `json:"target"`
+	Decision policy.SSHDecision `json:"decision"`
+}
+
+// hostTarget describes a command on a single named host for the SSH policy.
+// Labels and the cluster are only looked up if a rule needs them.
+func hostTarget(ctx context.Context, client *teleport.Client, params map[string]interface{}, sshPolicy *policy.SSHPolicy, destination, command string) policy.SSHTarget {
+	login, host := splitDestination(destination)
+	target := policy.SSHTarget{
+		Command: command,
+		Host:    host,
+		Login:   targetLogin(params, login),
+		Cluster: targetCluster(ctx, client, params, sshPolicy),
+	}
+
+	if sshPolicy.NeedsLabels() {
+		args := append(teleport.FormatArgs(params), "--format", "json")
+		if cluster, ok := params["cluster"].(string); ok && cluster != "" {
+			args = append(args, "--cluster", cluster)
+		}
+		result := client.ExecuteCommandContext(ctx, "resolve", append(args, host))
+		var node sshNode
+		if result.Success && json.Unmarshal([]byte(result.Stdout), &node) == nil {
+			target.Labels = node.Metadata.Labels
+			if target.Labels == nil {
+				target.Labels = map[string]string{}
+			}
+		}
+	}
+	return target
+}
+
+// checkSSHPolicy evaluates the SSH policy for running command on the single
+// host in destination. It returns nil if the server has no SSH policy.
+func checkSSHPolicy(ctx context.Context, sc *server.ServerContext, client *teleport.Client, params map[string]interface{}, destination, command string) *policy.SSHDecision {
+	sshPolicy := sc.SSHPolicy()
+	if sshPolicy == nil {
+		return nil
+	}
+	decision := sshPolicy.Evaluate(hostTarget(ctx, client, params, sshPolicy, destination, command))
+	return &decision
+}
+
+// nodeTarget describes a command on one node of a label-selector fan-out
+// for the SSH policy
+func nodeTarget(params map[string]interface{}, login, cluster, command string, node sshNode) policy.SSHTarget {
+	labels := node.Metadata.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	return policy.SSHTarget{
+		Command: command,
+		Host:    node.Spec.Hostname,
+		Labels:  labels,
+		Login:   targetLogin(params, login),
+		Cluster: cluster,
+	}
+}
+
+// targetLogin returns the login given in the destination or with loginParam
+func targetLogin(params map[string]interface{}, login string) string {
+	if login != "" {
+		return login
+	}
+	login, _ = params["loginParam"].(string)
+	return login
+}
+
+// targetCluster returns the cluster given with the cluster parameter or, if
+// a rule needs it, the cluster of the active tsh profile
+func targetCluster(ctx context.Context, client *teleport.Client, params map[string]interface{}, sshPolicy *policy.SSHPolicy) string {
+	if cluster, ok := params["cluster"].(string); ok && cluster != "" {
+		return cluster
+	}
+	if !sshPolicy.NeedsCluster() {
+		return ""
+	}
+
+	result := client.ExecuteCommandContext(ctx, "status", append(teleport.FormatArgs(params), "--format", "json"))
+	if !result.Success {
+		return ""
+	}
+	status, err := teleport.ParseStatus(result.Stdout)
+	if err != nil || status.Active == nil {
+		return ""
+	}
+	return status.Active.Cluster
+}
+
+// handleSSHPolicyExplain handles the teleport_ssh_policy_explain tool
+func handleSSHPolicyExplain(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	// Create teleport client
+	client := sc.TeleportClient()
+
+	// Extract parameters
+	params := make(map[string]interface{})
+	if request.Params.Arguments != nil {
+		if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			params = argsMap
+		}
+	}
+
+	destination, _ := params["destination"].(string)
+	if destination == "" {
+		return sshPolicyError("Error: Destination host is required"), nil
+	}
+	command, _ := params["command"].(string)
+	if command == "" {
+		return sshPolicyError("Error: Command is required"), nil
+	}
+
+	sshPolicy := sc.SSHPolicy()
+	if sshPolicy == nil {
+		return mcp.NewToolResultText("No SSH policy is configured; teleport_ssh runs every command that non-destructive mode allows. Start the server with --ssh-policy to configure one."), nil
+	}
+
+	explanation := PolicyExplanation{Destination: destination, Command: command, Allowed: true}
+	var executions []*teleport.ExecutionResult
+	if isLabelSelector(destination) {
+		login, selector := splitDestination(destination)
+		nodes, result, err := listNodes(ctx, client, nodeListArgs(params, selector), selector)
+		if err != nil {
+			return sshPolicyError(fmt.Sprintf("Error: %v", err)), nil
+		}
+		executions = append(executions, result)
+		cluster := targetCluster(ctx, client, params, sshPolicy)
+		for _, node := range nodes {
+			target := nodeTarget(params, login, cluster, command, node)
+			explanation.Nodes = append(explanation.Nodes, NodeDecision{Target: target, Decision: sshPolicy.Evaluate(target)})
+		}
+	} else {
+		target := hostTarget(ctx, client, params, sshPolicy, destination, command)
+		explanation.Nodes = append(explanation.Nodes, NodeDecision{Target: target, Decision: sshPolicy.Evaluate(target)})
+	}
+	for _, node := range explanation.Nodes {
+		explanation.Allowed = explanation.Allowed && node.Decision.Allowed
+	}
+
+	return server.WithDiagnostics(mcp.NewToolResultStructured(explanation, formatPolicyExplanation(&explanation)), executions...), nil
+}
+
+// formatPolicyExplanation renders an SSH policy explanation as text
+func formatPolicyExplanation(explanation *PolicyExplanation) string {
+	var sb strings.Builder
+	verdict := "would run"
+	if !explanation.Allowed {
+		verdict = "would be refused"
+	}
+	sb.WriteString(fmt.Sprintf("%q on %s %s\n", explanation.Command, explanation.Destination, verdict))
+
+	for _, node := range explanation.Nodes {
+		target := node.Target
+		sb.WriteString(fmt.Sprintf("\n%s: %s\n", target.Host, node.Decision))
+		sb.WriteString(fmt.Sprintf("  login: %s, cluster: %s, labels: %s\n", valueOrUnknown(target.Login), valueOrUnknown(target.Cluster), policy.FormatLabels(target.Labels)))
+		for _, trace := range node.Decision.Trace {
+			status := "no match"
+			if trace.Matched {
+				status = "MATCH"
+			}
+			line := fmt.Sprintf("  - %s (%s): %s", trace.Rule, trace.Action, status)
+			if trace.Detail != "" {
+				line += ", " + trace.Detail
+			}
+			sb.WriteString(line + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// valueOrUnknown returns the value, or a marker if it is empty
+func valueOrUnknown(value string) string {
+	if value == "" {
+		return "(unknown)"
+	}
+	return value
+}
+
+// sshPolicyError returns an SSH policy error result
+func sshPolicyError(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+		IsError: true,
+	}
+}
@@ -0,0 +1,191 @@
+package ssh
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/mcp-teleport/internal/policy"
+	"github.com/giantswarm/mcp-teleport/internal/server"
+	"github.com/giantswarm/mcp-teleport/internal/teleport/tshtest"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const testSSHPolicy = `{
+  "default": "deny",
+  "rules": [
+    {"name": "no-root-on-control-plane", "action": "deny", "labels": {"role": "control-plane"}, "logins": ["root"]},
+    {"name": "inspect", "action": "allow", "programs": ["uptime", "df", "journalctl"]}
+  ]
+}`
+
+// newPolicyServerContext returns a server context with the test SSH policy
+func newPolicyServerContext(t *testing.T, runner *tshtest.Runner) *server.ServerContext {
+	t.Helper()
+	sshPolicy, err := policy.ParseSSHPolicy([]byte(testSSHPolicy))
+	if err != nil {
+		t.Fatalf("ParseSSHPolicy() failed: %v", err)
+	}
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithRunner(runner),
+		server.WithSSHPolicy(sshPolicy),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	t.Cleanup(func() { sc.Shutdown() })
+	return sc
+}
+
+func TestHandleSSHPolicy(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh resolve --format json wallaby-9wldd$`, tshtest.Response{Stdout: tshtest.Fixture(t, "testdata/tsh_resolve.json")}).
+		On(`^tsh ssh ubuntu@wallaby-9wldd uptime$`, tshtest.Response{Stdout: " 07:15:15 up 92 days\n"})
+	sc := newPolicyServerContext(t, runner)
+
+	// Allowed commands report the rule that allowed them
+	result, err := handleSSH(context.Background(), createTestRequest(map[string]interface{}{
+		"destination": "ubuntu@wallaby-9wldd",
+		"command":     "uptime",
+	}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleSSH() failed: %v %+v", err, result)
+	}
+	if len(result.Content) != 2 || extractTextFromContent(result.Content[1]) != `Command allowed by SSH policy rule "inspect"` {
+		t.Errorf("Expected the allowing rule in the result, got: %+v", result.Content)
+	}
+
+	// Denied commands do not run
+	for _, params := range []map[string]interface{}{
+		{"destination": "root@wallaby-9wldd", "command": "uptime"},
+		{"destination": "wallaby-9wldd", "loginParam": "root", "command": "uptime", "background": true},
+		{"destination": "ubuntu@wallaby-9wldd", "command": "reboot"},
+	} {
+		result, err := handleSSH(context.Background(), createTestRequest(params), sc)
+		if err != nil {
+			t.Fatalf("Expected no error from handler, got: %v", err)
+		}
+		if !result.IsError || !strings.Contains(extractTextFromContent(result.Content[0]), "Command denied by") {
+			t.Errorf("Expected %v to be denied, got: %+v", params, result)
+		}
+	}
+	for _, call := range runner.Calls() {
+		if line := strings.Join(call, " "); strings.HasPrefix(line, "tsh ssh ") && line != "tsh ssh ubuntu@wallaby-9wldd uptime" {
+			t.Errorf("Denied command ran: %s", line)
+		}
+	}
+}
+
+func TestHandleSSHFanOutPolicy(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh ls --format json cluster=wallaby$`, tshtest.Response{Stdout: tshtest.Fixture(t, "testdata/tsh_ls.json")}).
+		On(`^tsh ssh `, tshtest.Response{Stdout: " 07:15:15 up 92 days\n"})
+	sc := newPolicyServerContext(t, runner)
+
+	// One denied node stops the command everywhere
+	result, err := handleSSH(context.Background(), createTestRequest(map[string]interface{}{
+		"destination": "root@cluster=wallaby",
+		"command":     "uptime",
+	}), sc)
+	if err != nil {
+		t.Fatalf("Expected no error from handler, got: %v", err)
+	}
+	if !result.IsError || !strings.Contains(extractTextFromContent(result.Content[0]), "- wallaby-9wldd: denied by SSH policy rule \"no-root-on-control-plane\"") {
+		t.Errorf("Expected the control plane node to be denied, got: %+v", result)
+	}
+	if len(runner.Calls()) != 1 {
+		t.Errorf("Expected only the node lookup, got %v", runner.Calls())
+	}
+
+	result, err = handleSSH(context.Background(), createTestRequest(map[string]interface{}{
+		"destination": "ubuntu@cluster=wallaby",
+		"command":     "uptime",
+	}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleSSH() failed: %v %+v", err, result)
+	}
+	for _, node := range result.StructuredContent.(*FanOutResult).Nodes {
+		if !node.Success || node.PolicyRule != "inspect" {
+			t.Errorf("Unexpected node result: %+v", node)
+		}
+	}
+}
+
+func TestHandleSSHPolicyExplain(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh ls --format json cluster=wallaby$`, tshtest.Response{Stdout: tshtest.Fixture(t, "testdata/tsh_ls.json")})
+	sc := newPolicyServerContext(t, runner)
+
+	result, err := handleSSHPolicyExplain(context.Background(), createTestRequest(map[string]interface{}{
+		"destination": "root@cluster=wallaby",
+		"command":     "df -h",
+	}), sc)
+	if err != nil || result.IsError {
+		t.Fatalf("handleSSHPolicyExplain() failed: %v %+v", err, result)
+	}
+
+	explanation := result.StructuredContent.(PolicyExplanation)
+	if explanation.Allowed || len(explanation.Nodes) != 2 {
+		t.Fatalf("Unexpected explanation: %+v", explanation)
+	}
+	if decision := explanation.Nodes[1].Decision; !decision.Allowed || decision.Rule != "inspect" {
+		t.Errorf("Expected the worker to be allowed, got %+v", decision)
+	}
+
+	text := extractTextFromContent(result.Content[0])
+	for _, expected := range []string{
+		`"df -h" on root@cluster=wallaby would be refused`,
+		"wallaby-worker-7x2kq: allowed by SSH policy rule \"inspect\"",
+		"  - no-root-on-control-plane (deny): no match, labels {cluster=wallaby,role=worker} do not match {role=control-plane}",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in result, got: %s", expected, text)
+		}
+	}
+	for _, call := range runner.Calls() {
+		if call[1] == "ssh" {
+			t.Errorf("Explaining must not run the command: %v", call)
+		}
+	}
+}
+
+func TestSSHPolicyAppliesToFileTools(t *testing.T) {
+	runner := tshtest.NewRunner().
+		On(`^tsh resolve --format json wallaby-9wldd$`, tshtest.Response{Stdout: tshtest.Fixture(t, "testdata/tsh_resolve.json")})
+	sc := newPolicyServerContext(t, runner)
+
+	local := t.TempDir() + "/kubelet.conf"
+	if err := os.WriteFile(local, []byte("maxPods: 110\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		handler func(context.Context, mcp.CallToolRequest, *server.ServerContext) (*mcp.CallToolResult, error)
+		params  map[string]interface{}
+	}{
+		{"file read", handleFileRead, map[string]interface{}{"destination": "ubuntu@wallaby-9wldd", "path": "/etc/hostname"}},
+		{"file write", handleFileWrite, map[string]interface{}{"destination": "ubuntu@wallaby-9wldd", "path": "/tmp/x", "content": "x"}},
+		{"dir list", handleDirList, map[string]interface{}{"destination": "ubuntu@wallaby-9wldd", "path": "/etc"}},
+		{"scp upload", handleSCP, map[string]interface{}{"source": local, "destination": "root@wallaby-9wldd:/tmp/kubelet.conf"}},
+		{"scp download", handleSCP, map[string]interface{}{"source": "root@wallaby-9wldd:/etc/hostname", "destination": t.TempDir()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.handler(context.Background(), createTestRequest(tt.params), sc)
+			if err != nil {
+				t.Fatalf("Expected no error from handler, got: %v", err)
+			}
+			if !result.IsError || !strings.Contains(extractTextFromContent(result.Content[0]), "denied by") {
+				t.Errorf("Expected %v to be denied, got: %+v", tt.params, result)
+			}
+		})
+	}
+
+	for _, call := range runner.Calls() {
+		if call[1] == "ssh" || call[1] == "scp" {
+			t.Errorf("Expected nothing to run on the node, got %v", call)
+		}
+	}
+}
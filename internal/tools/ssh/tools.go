@@ -13,6 +13,7 @@ func RegisterSSHTools(s *mcpserver.MCPServer, sc *server.ServerContext) error {
 	// teleport_list_ssh_nodes tool
 	listSSHNodesTool := mcp.NewTool("teleport_list_ssh_nodes",
 		mcp.WithDescription("List SSH nodes available through Teleport"),
+		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithString("loginParam",
 			mcp.Description("Remote host login"),
 		),
@@ -53,6 +54,7 @@ func RegisterSSHTools(s *mcpserver.MCPServer, sc *server.ServerContext) error {
 		mcp.WithString("cluster",
 			mcp.Description("Specify the Teleport cluster to connect"),
 		),
+		server.TimeoutOption(),
 	)
 
 	s.AddTool(listSSHNodesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -61,7 +63,9 @@ func RegisterSSHTools(s *mcpserver.MCPServer, sc *server.ServerContext) error {
 
 	// teleport_ssh tool
 	sshTool := mcp.NewTool("teleport_ssh",
-		mcp.WithDescription("Execute a one-time command on a remote SSH node via Teleport. Interactive shell sessions are not supported - you must provide a specific command to execute. Supports both direct hostname targeting (user@hostname) and label selector targeting (user@key=value,key2=value2) for multi-node execution."),
+		mcp.WithDescription("Execute a one-time command on a remote SSH node via Teleport. Interactive shell sessions are not supported - you must provide a specific command to execute. Supports both direct hostname targeting (user@hostname) and label selector targeting (user@key=value,key2=value2) for multi-node execution, which returns hostname, node UUID, exit code, stdout, stderr and duration per node (structured). Long-running commands can run as background jobs with background=true. In non-destructive mode only read-only commands are allowed. If the server has an SSH policy, the command must pass it on every target node; see teleport_ssh_policy_explain."),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
 		mcp.WithString("loginParam",
 			mcp.Description("Remote host login"),
 		),
@@ -94,6 +98,10 @@ func RegisterSSHTools(s *mcpserver.MCPServer, sc *server.ServerContext) error {
 		mcp.WithNumber("port",
 			mcp.Description("SSH port on the remote host"),
 		),
+		mcp.WithNumber("concurrency",
+			mcp.Description("Maximum number of nodes to run the command on at once with a label selector; capped by the server's --ssh-concurrency"),
+			mcp.Min(1),
+		),
 		mcp.WithBoolean("verbose",
 			mcp.Description("Verbose output"),
 		),
@@ -128,15 +136,58 @@ func RegisterSSHTools(s *mcpserver.MCPServer, sc *server.ServerContext) error {
 		mcp.WithBoolean("tty",
 			mcp.Description("Allocate TTY"),
 		),
+		mcp.WithBoolean("background",
+			mcp.Description("Run the command as a background job and return its job ID immediately. Poll it with teleport_job_status and stop it with teleport_job_cancel. timeoutSeconds then limits the job, which otherwise may run for the server's --job-timeout. Not supported with label selectors"),
+		),
+		server.TimeoutOption(),
 	)
 
 	s.AddTool(sshTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		return handleSSH(ctx, request, sc)
 	})
 
+	// teleport_ssh_policy_explain tool
+	sshPolicyExplainTool := mcp.NewTool("teleport_ssh_policy_explain",
+		mcp.WithDescription("Explain how the server's SSH policy would decide a teleport_ssh call without running the command: the deciding rule per node and why every earlier rule did or did not match (structured). Label selectors are evaluated for each matching node."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("loginParam",
+			mcp.Description("Remote host login"),
+		),
+		mcp.WithString("proxyParam",
+			mcp.Description("Teleport proxy address"),
+		),
+		mcp.WithString("userParam",
+			mcp.Description("Teleport user, defaults to current local user"),
+		),
+		mcp.WithString("identityParam",
+			mcp.Description("Identity file"),
+		),
+		mcp.WithBoolean("insecureParam",
+			mcp.Description("Do not verify server's certificate and host name. Use only in test environments"),
+		),
+		mcp.WithString("destination",
+			mcp.Required(),
+			mcp.Description("Destination as given to teleport_ssh: 'user@hostname' or a label selector 'user@key=value,key2=value2'"),
+		),
+		mcp.WithString("command",
+			mcp.Required(),
+			mcp.Description("Command line to evaluate"),
+		),
+		mcp.WithString("cluster",
+			mcp.Description("Specify the Teleport cluster to connect"),
+		),
+		server.TimeoutOption(),
+	)
+
+	s.AddTool(sshPolicyExplainTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleSSHPolicyExplain(ctx, request, sc)
+	})
+
 	// teleport_scp tool
 	scpTool := mcp.NewTool("teleport_scp",
-		mcp.WithDescription("Transfer files to a remote SSH node"),
+		mcp.WithDescription("Transfer files to or from a remote SSH node. Local and remote paths must pass the server's SCP path policy, and each remote side its SSH policy. Single-file transfers are verified by comparing the size and SHA-256 of both copies (structured). Large transfers can run as background jobs with background=true, verified when they finish. Remote paths must not contain glob characters. Disabled in non-destructive mode."),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
 		mcp.WithString("loginParam",
 			mcp.Description("Remote host login"),
 		),
@@ -181,6 +232,10 @@ func RegisterSSHTools(s *mcpserver.MCPServer, sc *server.ServerContext) error {
 		mcp.WithBoolean("quiet",
 			mcp.Description("Quiet mode"),
 		),
+		mcp.WithBoolean("background",
+			mcp.Description("Run the transfer as a background job and return its job ID immediately. Poll it with teleport_job_status and stop it with teleport_job_cancel. timeoutSeconds then limits the job, which otherwise may run for the server's --job-timeout"),
+		),
+		server.TimeoutOption(),
 	)
 
 	s.AddTool(scpTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -190,6 +245,7 @@ func RegisterSSHTools(s *mcpserver.MCPServer, sc *server.ServerContext) error {
 	// teleport_resolve tool
 	resolveTool := mcp.NewTool("teleport_resolve",
 		mcp.WithDescription("Resolve an SSH host"),
+		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithString("loginParam",
 			mcp.Description("Remote host login"),
 		),
@@ -218,11 +274,335 @@ func RegisterSSHTools(s *mcpserver.MCPServer, sc *server.ServerContext) error {
 		mcp.WithBoolean("quiet",
 			mcp.Description("Quiet mode"),
 		),
+		server.TimeoutOption(),
 	)
 
 	s.AddTool(resolveTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		return handleResolve(ctx, request, sc)
 	})
 
+	// teleport_recordings_list tool
+	recordingsListTool := mcp.NewTool("teleport_recordings_list",
+		mcp.WithDescription("List recorded SSH, Kubernetes, database and desktop sessions in a time range, with user, login, target, command and start and end times (structured). Defaults to the last 24 hours."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("proxyParam",
+			mcp.Description("Teleport proxy address"),
+		),
+		mcp.WithString("userParam",
+			mcp.Description("Teleport user, defaults to current local user"),
+		),
+		mcp.WithString("identityParam",
+			mcp.Description("Identity file"),
+		),
+		mcp.WithBoolean("insecureParam",
+			mcp.Description("Do not verify server's certificate and host name. Use only in test environments"),
+		),
+		mcp.WithBoolean("debugParam",
+			mcp.Description("Verbose logging to stdout"),
+		),
+		mcp.WithString("from",
+			mcp.Description("Start of the time range, as an RFC 3339 timestamp or a YYYY-MM-DD date in UTC. Defaults to 24 hours before to"),
+		),
+		mcp.WithString("to",
+			mcp.Description("End of the time range, as an RFC 3339 timestamp or a YYYY-MM-DD date in UTC. Defaults to now"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of recordings tsh returns"),
+			mcp.Min(1),
+		),
+		mcp.WithString("cluster",
+			mcp.Description("Specify the Teleport cluster to connect"),
+		),
+		server.TimeoutOption(),
+	)
+
+	s.AddTool(recordingsListTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleRecordingsList(ctx, request, sc)
+	})
+
+	// teleport_recording_play tool
+	recordingPlayTool := mcp.NewTool("teleport_recording_play",
+		mcp.WithDescription("Fetch a recorded session as a plain text transcript of its terminal output, with escape sequences removed. Long transcripts are truncated to their head and tail; read the rest with teleport_output_read."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("proxyParam",
+			mcp.Description("Teleport proxy address"),
+		),
+		mcp.WithString("userParam",
+			mcp.Description("Teleport user, defaults to current local user"),
+		),
+		mcp.WithString("identityParam",
+			mcp.Description("Identity file"),
+		),
+		mcp.WithBoolean("insecureParam",
+			mcp.Description("Do not verify server's certificate and host name. Use only in test environments"),
+		),
+		mcp.WithBoolean("debugParam",
+			mcp.Description("Verbose logging to stdout"),
+		),
+		mcp.WithString("sessionId",
+			mcp.Required(),
+			mcp.Description("ID of the recorded session, as returned by teleport_recordings_list"),
+		),
+		mcp.WithNumber("maxBytes",
+			mcp.Description("Maximum bytes of transcript to return; capped by the server's --output-limit"),
+			mcp.Min(1),
+		),
+		mcp.WithString("cluster",
+			mcp.Description("Specify the Teleport cluster to connect"),
+		),
+		server.TimeoutOption(),
+	)
+
+	s.AddTool(recordingPlayTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleRecordingPlay(ctx, request, sc)
+	})
+
+	// teleport_sessions_list tool
+	sessionsListTool := mcp.NewTool("teleport_sessions_list",
+		mcp.WithDescription("List active SSH and Kubernetes sessions with their participants, targets, commands and start times (structured)"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("proxyParam",
+			mcp.Description("Teleport proxy address"),
+		),
+		mcp.WithString("userParam",
+			mcp.Description("Teleport user, defaults to current local user"),
+		),
+		mcp.WithString("identityParam",
+			mcp.Description("Identity file"),
+		),
+		mcp.WithBoolean("insecureParam",
+			mcp.Description("Do not verify server's certificate and host name. Use only in test environments"),
+		),
+		mcp.WithBoolean("debugParam",
+			mcp.Description("Verbose logging to stdout"),
+		),
+		mcp.WithString("kind",
+			mcp.Description("Kind of sessions to list"),
+			mcp.Enum("ssh", "kube", "all"),
+		),
+		mcp.WithString("cluster",
+			mcp.Description("Specify the Teleport cluster to connect"),
+		),
+		server.TimeoutOption(),
+	)
+
+	s.AddTool(sessionsListTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleSessionsList(ctx, request, sc)
+	})
+
+	// teleport_session_observe tool
+	sessionObserveTool := mcp.NewTool("teleport_session_observe",
+		mcp.WithDescription("Join an active SSH or Kubernetes session in observer mode, capture its terminal output for a bounded window and detach. Observers cannot type into the session, but other participants are told that someone joined."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("proxyParam",
+			mcp.Description("Teleport proxy address"),
+		),
+		mcp.WithString("userParam",
+			mcp.Description("Teleport user, defaults to current local user"),
+		),
+		mcp.WithString("identityParam",
+			mcp.Description("Identity file"),
+		),
+		mcp.WithBoolean("insecureParam",
+			mcp.Description("Do not verify server's certificate and host name. Use only in test environments"),
+		),
+		mcp.WithBoolean("debugParam",
+			mcp.Description("Verbose logging to stdout"),
+		),
+		mcp.WithString("sessionId",
+			mcp.Required(),
+			mcp.Description("ID of the active session, as returned by teleport_sessions_list"),
+		),
+		mcp.WithString("kind",
+			mcp.Description("Kind of the session, defaults to ssh"),
+			mcp.Enum("ssh", "kube"),
+		),
+		mcp.WithNumber("durationSeconds",
+			mcp.Description("How long to observe the session before detaching, at most 300 seconds. Defaults to 10 seconds"),
+			mcp.Min(1),
+			mcp.Max(300),
+		),
+		mcp.WithNumber("maxBytes",
+			mcp.Description("Maximum bytes of output to return; capped by the server's --output-limit"),
+			mcp.Min(1),
+		),
+		mcp.WithString("cluster",
+			mcp.Description("Specify the Teleport cluster to connect"),
+		),
+		server.TimeoutOption(),
+	)
+
+	s.AddTool(sessionObserveTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleSessionObserve(ctx, request, sc)
+	})
+
+	// teleport_file_read tool
+	fileReadTool := mcp.NewTool("teleport_file_read",
+		mcp.WithDescription("Read a file on a remote SSH node, either a byte range (offset, length) or a line range (startLine, endLine). Byte ranges are returned a page at a time; follow nextOffset to read on. Text ranges leave out characters cut off at either end, so offset may move forward to the next character (structured). Subject to the server's SSH policy."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("loginParam",
+			mcp.Description("Remote host login"),
+		),
+		mcp.WithString("proxyParam",
+			mcp.Description("Teleport proxy address"),
+		),
+		mcp.WithString("userParam",
+			mcp.Description("Teleport user, defaults to current local user"),
+		),
+		mcp.WithString("identityParam",
+			mcp.Description("Identity file"),
+		),
+		mcp.WithBoolean("insecureParam",
+			mcp.Description("Do not verify server's certificate and host name. Use only in test environments"),
+		),
+		mcp.WithBoolean("debugParam",
+			mcp.Description("Verbose logging to stdout"),
+		),
+		mcp.WithString("destination",
+			mcp.Required(),
+			mcp.Description("Remote host as [user@]hostname. Label selectors are not supported"),
+		),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Path of the file on the remote host"),
+		),
+		mcp.WithNumber("offset",
+			mcp.Description("Byte offset to start reading at"),
+			mcp.Min(0),
+		),
+		mcp.WithNumber("length",
+			mcp.Description("Maximum number of bytes to read; capped by the server's --output-limit"),
+			mcp.Min(1),
+		),
+		mcp.WithNumber("startLine",
+			mcp.Description("First line to read, starting at 1"),
+			mcp.Min(1),
+		),
+		mcp.WithNumber("endLine",
+			mcp.Description("Last line to read, inclusive"),
+			mcp.Min(1),
+		),
+		mcp.WithString("encoding",
+			mcp.Description("Encoding of the returned content; use base64 for binary files"),
+			mcp.Enum("text", "base64"),
+		),
+		mcp.WithNumber("port",
+			mcp.Description("SSH port on the remote host"),
+		),
+		mcp.WithString("cluster",
+			mcp.Description("Specify the Teleport cluster to connect"),
+		),
+		server.TimeoutOption(),
+	)
+
+	s.AddTool(fileReadTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleFileRead(ctx, request, sc)
+	})
+
+	// teleport_file_write tool
+	fileWriteTool := mcp.NewTool("teleport_file_write",
+		mcp.WithDescription("Write a file on a remote SSH node from content given in the call, optionally keeping a backup of the previous file. The SHA-256 and size of the remote file are verified against the content by default (structured). Subject to the server's SSH policy. Disabled in non-destructive mode."),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithString("loginParam",
+			mcp.Description("Remote host login"),
+		),
+		mcp.WithString("proxyParam",
+			mcp.Description("Teleport proxy address"),
+		),
+		mcp.WithString("userParam",
+			mcp.Description("Teleport user, defaults to current local user"),
+		),
+		mcp.WithString("identityParam",
+			mcp.Description("Identity file"),
+		),
+		mcp.WithBoolean("insecureParam",
+			mcp.Description("Do not verify server's certificate and host name. Use only in test environments"),
+		),
+		mcp.WithBoolean("debugParam",
+			mcp.Description("Verbose logging to stdout"),
+		),
+		mcp.WithString("destination",
+			mcp.Required(),
+			mcp.Description("Remote host as [user@]hostname. Label selectors are not supported"),
+		),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Path of the file on the remote host; it is created or overwritten"),
+		),
+		mcp.WithString("content",
+			mcp.Required(),
+			mcp.Description("Content to write"),
+		),
+		mcp.WithString("encoding",
+			mcp.Description("Encoding of content; use base64 for binary files"),
+			mcp.Enum("text", "base64"),
+		),
+		mcp.WithBoolean("backup",
+			mcp.Description("Copy an existing file to <path>.bak.<timestamp> before overwriting it"),
+		),
+		mcp.WithBoolean("verify",
+			mcp.Description("Verify the SHA-256 and size of the written file (default: true)"),
+		),
+		mcp.WithNumber("port",
+			mcp.Description("SSH port on the remote host"),
+		),
+		mcp.WithString("cluster",
+			mcp.Description("Specify the Teleport cluster to connect"),
+		),
+		server.TimeoutOption(),
+	)
+
+	s.AddTool(fileWriteTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleFileWrite(ctx, request, sc)
+	})
+
+	// teleport_dir_list tool
+	dirListTool := mcp.NewTool("teleport_dir_list",
+		mcp.WithDescription("List a directory on a remote SSH node with type, size, mode, owner, group and modification time per entry (structured). Requires GNU find on the remote host. Subject to the server's SSH policy."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("loginParam",
+			mcp.Description("Remote host login"),
+		),
+		mcp.WithString("proxyParam",
+			mcp.Description("Teleport proxy address"),
+		),
+		mcp.WithString("userParam",
+			mcp.Description("Teleport user, defaults to current local user"),
+		),
+		mcp.WithString("identityParam",
+			mcp.Description("Identity file"),
+		),
+		mcp.WithBoolean("insecureParam",
+			mcp.Description("Do not verify server's certificate and host name. Use only in test environments"),
+		),
+		mcp.WithBoolean("debugParam",
+			mcp.Description("Verbose logging to stdout"),
+		),
+		mcp.WithString("destination",
+			mcp.Required(),
+			mcp.Description("Remote host as [user@]hostname. Label selectors are not supported"),
+		),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Path of the directory on the remote host"),
+		),
+		mcp.WithNumber("maxEntries",
+			mcp.Description("Maximum number of entries to return (default: 1000)"),
+			mcp.Min(1),
+		),
+		mcp.WithNumber("port",
+			mcp.Description("SSH port on the remote host"),
+		),
+		mcp.WithString("cluster",
+			mcp.Description("Specify the Teleport cluster to connect"),
+		),
+		server.TimeoutOption(),
+	)
+
+	s.AddTool(dirListTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleDirList(ctx, request, sc)
+	})
+
 	return nil
 }